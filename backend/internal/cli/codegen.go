@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/spf13/cobra"
+)
+
+var (
+	codegenAPIIDs    []uint
+	codegenGroups    []string
+	codegenLang      string
+	codegenParamType string
+	codegenPackage   string
+	codegenOut       string
+)
+
+var codegenCmd = &cobra.Command{
+	Use:   "codegen",
+	Short: "Generate typed models from documented parameters",
+	Long: `Generate request and response types of documented APIs. Nested objects become
+named types (e.g. CreateOrderRequestCustomer), optional fields are marked as such and
+descriptions become doc comments. Languages: ` + strings.Join(services.CodegenLanguages, ", ") + `.
+
+Examples:
+  knot codegen --api 12 --lang typescript
+  knot codegen --group payments --lang go --package payments --out payments/models.go
+  knot codegen --api 12 --api 13 --lang pydantic --param-type response`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(codegenAPIIDs) == 0 && len(codegenGroups) == 0 {
+			fmt.Fprintln(os.Stderr, "❌ Specify --api or --group")
+			os.Exit(2)
+		}
+		if codegenParamType != "" && codegenParamType != "request" && codegenParamType != "response" {
+			fmt.Fprintln(os.Stderr, "❌ --param-type must be request or response")
+			os.Exit(2)
+		}
+
+		db := openExportDatabase()
+
+		apiIDs, err := groupAPIIDs(db, codegenGroups)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		apiIDs = append(apiIDs, codegenAPIIDs...)
+
+		apis, err := services.LoadExportAPIs(db, apiIDs, "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		if len(apis) < len(codegenAPIIDs) {
+			fmt.Fprintln(os.Stderr, "❌ API not found")
+			os.Exit(1)
+		}
+
+		code, err := services.GenerateModels(apis, codegenLang, services.CodegenOptions{
+			ParamType: codegenParamType,
+			Package:   codegenPackage,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v: %s (use %s)\n", err, codegenLang, strings.Join(services.CodegenLanguages, ", "))
+			os.Exit(2)
+		}
+
+		if codegenOut == "" {
+			fmt.Print(code)
+			return
+		}
+		if err := os.WriteFile(codegenOut, []byte(code), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to write %s: %v\n", codegenOut, err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Generated %s types for %d APIs in %s\n", codegenLang, len(apis), codegenOut)
+	},
+}
+
+func init() {
+	codegenCmd.Flags().UintSliceVar(&codegenAPIIDs, "api", nil, "ID of a documented API (repeatable)")
+	codegenCmd.Flags().StringArrayVar(&codegenGroups, "group", nil, "generate types for every API of this group (repeatable)")
+	codegenCmd.Flags().StringVar(&codegenLang, "lang", "typescript", "language: "+strings.Join(services.CodegenLanguages, ", "))
+	codegenCmd.Flags().StringVar(&codegenParamType, "param-type", "", "only generate request or response types")
+	codegenCmd.Flags().StringVar(&codegenPackage, "package", "", "Go, Java or Kotlin package")
+	codegenCmd.Flags().StringVarP(&codegenOut, "out", "o", "", "output file (default stdout)")
+}
@@ -0,0 +1,427 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/config"
+	"github.com/ProjAnvil/knot/backend/internal/database"
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+var (
+	exportGroups      []string
+	exportEnvironment string
+	exportLocale      string
+	exportOut         string
+	exportSingle      bool
+	exportZip         bool
+	exportTitle       string
+	exportTheme       string
+	exportClean       bool
+	exportTemplate    string
+	exportSpecVersion string
+	exportFormat      string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export API documentation",
+	Long: `Export the documented APIs to files.
+
+With --template, render the APIs with an export template: the built-in default.html,
+a template in ~/.knot/templates (wiki.md for ~/.knot/templates/wiki.md.tmpl) or a
+template file. Templates use Go text/template syntax; the output goes to --out or stdout.
+
+Examples:
+  knot export --template default.html --out api-docs.html
+  knot export --template ./confluence.xml.tmpl --group payments`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if exportTemplate == "" {
+			cmd.Help()
+			return
+		}
+
+		name, source, err := readExportTemplate(exportTemplate)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to load template: %v\n", err)
+			os.Exit(1)
+		}
+
+		tmpl, err := services.ParseExportTemplate(name, source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Invalid template: %v\n", err)
+			os.Exit(1)
+		}
+
+		db := openExportDatabase()
+
+		apis, err := loadExportAPIs(db)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		output, err := services.RenderExportTemplate(tmpl, apis, exportLocale)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to render template: %v\n", err)
+			os.Exit(1)
+		}
+
+		if exportOut == "" {
+			fmt.Print(output)
+			return
+		}
+		if err := os.WriteFile(exportOut, []byte(output), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to write export: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Exported %d APIs to %s\n", len(apis), exportOut)
+	},
+}
+
+var exportMarkdownCmd = &cobra.Command{
+	Use:   "markdown",
+	Short: "Export API documentation as Markdown",
+	Long: `Export the documented APIs as Markdown, one file per group plus a README.md
+index, or a single file with --single. Parameter tables name nested parameters by
+their dotted path and every API has JSON examples. The output contains no timestamps,
+so it can be committed to a Git-based wiki and reviewed as a diff.
+
+Examples:
+  knot export markdown --out ./wiki/api
+  knot export markdown --single --out api-docs.md
+  knot export markdown --group payments --group orders --zip --out api-docs.zip`,
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openExportDatabase()
+
+		apis, err := loadExportAPIs(db)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		var files []services.ExportFile
+		if exportSingle {
+			files = []services.ExportFile{{Name: "api-docs.md", Content: services.GenerateMarkdown(apis, exportLocale)}}
+		} else {
+			files = services.GenerateMarkdownFiles(apis, exportLocale)
+		}
+
+		switch {
+		case exportZip:
+			out := defaultString(exportOut, "api-docs.zip")
+			err = writeFileWith(out, func(w io.Writer) error {
+				return services.WriteExportZip(w, files)
+			})
+		case exportSingle:
+			out := defaultString(exportOut, "api-docs.md")
+			err = os.WriteFile(out, []byte(files[0].Content), 0644)
+		default:
+			out := defaultString(exportOut, "api-docs")
+			err = writeFilesTo(out, files)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to write export: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("✓ Exported %d APIs\n", len(apis))
+	},
+}
+
+var exportSiteCmd = &cobra.Command{
+	Use:   "site",
+	Short: "Generate a static documentation site",
+	Long: `Generate a static documentation site with an overview page, one page per group
+and one page per API, a client-side search index and a theme. URLs are derived from
+group and API names (e.g. payments/create-charge.html) and all links are relative, so
+the site can be deployed to any static host or opened from disk. Generating the site
+twice from the same data produces identical files.
+
+Examples:
+  knot export site --out ./dist
+  knot export site --out ./dist --clean --title "Payments API" --theme brand.css`,
+	Run: func(cmd *cobra.Command, args []string) {
+		out := defaultString(exportOut, "dist")
+
+		var theme []byte
+		if exportTheme != "" {
+			var err error
+			if theme, err = os.ReadFile(exportTheme); err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Failed to read theme: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		db := openExportDatabase()
+
+		apis, err := loadExportAPIs(db)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		files := services.GenerateSite(apis, services.SiteOptions{
+			Title:  exportTitle,
+			Locale: exportLocale,
+			Theme:  string(theme),
+		})
+
+		if exportClean {
+			if err := os.RemoveAll(out); err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Failed to clean %s: %v\n", out, err)
+				os.Exit(1)
+			}
+		}
+		if err := writeFilesTo(out, files); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to write site: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("✓ Generated site for %d APIs in %s\n", len(apis), out)
+	},
+}
+
+var exportSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Export JSON Schemas of request and response bodies",
+	Long: `Export draft 2020-12 JSON Schemas of the request and response body of every API,
+one file per body named <group>/<api>.request.schema.json and .response.schema.json.
+Each schema has a stable $id (knot://apis/<id>/request.schema.json) for references
+from gateways and contract tests.
+
+Examples:
+  knot export schema --out ./schemas
+  knot export schema --group payments --zip --out payments-schemas.zip`,
+	Run: func(cmd *cobra.Command, args []string) {
+		db := openExportDatabase()
+
+		apis, err := loadExportAPIs(db)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		files, err := services.GenerateJSONSchemaFiles(apis)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to generate schemas: %v\n", err)
+			os.Exit(1)
+		}
+
+		if exportZip {
+			err = writeFileWith(defaultString(exportOut, "schemas.zip"), func(w io.Writer) error {
+				return services.WriteExportZip(w, files)
+			})
+		} else {
+			err = writeFilesTo(defaultString(exportOut, "schemas"), files)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to write export: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("✓ Exported schemas of %d APIs\n", len(apis))
+	},
+}
+
+var exportAsyncAPICmd = &cobra.Command{
+	Use:   "asyncapi",
+	Short: "Export EVENT APIs as an AsyncAPI document",
+	Long: `Export the EVENT APIs as an AsyncAPI 3.0.0 or 2.6.0 document. Every API becomes an
+operation on its channel with a message whose payload is the schema of its parameters;
+the broker protocol becomes a channel binding. The document is written to --out or stdout.
+
+Examples:
+  knot export asyncapi --group orders --out orders.asyncapi.yaml
+  knot export asyncapi --group orders --spec-version 2.6.0 --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		version, err := services.ResolveAsyncAPIVersion(exportSpecVersion)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(2)
+		}
+		if exportFormat != "yaml" && exportFormat != "json" {
+			fmt.Fprintln(os.Stderr, "❌ --format must be yaml or json")
+			os.Exit(2)
+		}
+
+		db := openExportDatabase()
+
+		apis, err := loadExportAPIs(db)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		title := "API Documentation"
+		if len(exportGroups) == 1 {
+			title = exportGroups[0]
+		}
+		document, err := services.GenerateAsyncAPI(apis, title, version)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to generate AsyncAPI document: %v\n", err)
+			os.Exit(1)
+		}
+
+		var content []byte
+		if exportFormat == "json" {
+			content, err = json.MarshalIndent(document, "", "  ")
+			content = append(content, '\n')
+		} else {
+			content, err = services.EncodeOrderedYAML(document)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to encode AsyncAPI document: %v\n", err)
+			os.Exit(1)
+		}
+
+		if exportOut == "" {
+			os.Stdout.Write(content)
+			return
+		}
+		if err := os.WriteFile(exportOut, content, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to write export: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Exported AsyncAPI %s document to %s\n", version, exportOut)
+	},
+}
+
+func init() {
+	exportCmd.PersistentFlags().StringArrayVar(&exportGroups, "group", nil, "only export APIs of this group (repeatable)")
+	exportCmd.PersistentFlags().StringVar(&exportEnvironment, "env", "", "resolve endpoints to full URLs with this environment")
+	exportCmd.PersistentFlags().StringVar(&exportLocale, "locale", "en", "language of headings (en or zh)")
+	exportCmd.PersistentFlags().StringVar(&exportOut, "out", "", "output file or directory")
+	exportCmd.Flags().StringVar(&exportTemplate, "template", "", "name of an export template, or path to a template file")
+
+	exportMarkdownCmd.Flags().BoolVar(&exportSingle, "single", false, "write a single file instead of one file per group")
+	exportMarkdownCmd.Flags().BoolVar(&exportZip, "zip", false, "write the files into a ZIP archive")
+
+	exportSiteCmd.Flags().StringVar(&exportTitle, "title", "", "site title")
+	exportSiteCmd.Flags().StringVar(&exportTheme, "theme", "", "CSS file replacing the default theme")
+	exportSiteCmd.Flags().BoolVar(&exportClean, "clean", false, "remove the output directory before generating")
+
+	exportSchemaCmd.Flags().BoolVar(&exportZip, "zip", false, "write the files into a ZIP archive")
+
+	exportCmd.AddCommand(exportMarkdownCmd)
+	exportCmd.AddCommand(exportSiteCmd)
+	exportAsyncAPICmd.Flags().StringVar(&exportSpecVersion, "spec-version", "3.0.0", "AsyncAPI version (3.0.0 or 2.6.0)")
+	exportAsyncAPICmd.Flags().StringVar(&exportFormat, "format", "yaml", "output format (yaml or json)")
+
+	exportCmd.AddCommand(exportSchemaCmd)
+	exportCmd.AddCommand(exportAsyncAPICmd)
+}
+
+// openExportDatabase opens the configured database without migration output, exiting on failure
+func openExportDatabase() *gorm.DB {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	database.Output = io.Discard
+	db, err := database.InitDatabase(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to initialize database: %v\n", err)
+		os.Exit(1)
+	}
+	return db
+}
+
+// loadExportAPIs loads the APIs selected by --group, or every API
+func loadExportAPIs(db *gorm.DB) ([]services.APIWithParams, error) {
+	apiIDs, err := groupAPIIDs(db, exportGroups)
+	if err != nil {
+		return nil, err
+	}
+	return services.LoadExportAPIs(db, apiIDs, exportEnvironment)
+}
+
+// groupAPIIDs returns the IDs of the APIs in groups given by name, or nil without names
+func groupAPIIDs(db *gorm.DB, names []string) ([]uint, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var groups []models.Group
+	if err := db.Where("name IN ?", names).Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch groups: %w", err)
+	}
+
+	groupIDs := make([]uint, 0, len(groups))
+	found := make(map[string]bool)
+	for _, group := range groups {
+		groupIDs = append(groupIDs, group.ID)
+		found[group.Name] = true
+	}
+	for _, name := range names {
+		if !found[name] {
+			return nil, fmt.Errorf("group %q not found", name)
+		}
+	}
+
+	apiIDs := []uint{}
+	if err := db.Model(&models.API{}).Where("group_id IN ?", groupIDs).Pluck("id", &apiIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch APIs: %w", err)
+	}
+	return apiIDs, nil
+}
+
+// readExportTemplate returns the name and source of a template given by name or file path.
+// Files are named after their base name without .tmpl, e.g. wiki.md for wiki.md.tmpl.
+func readExportTemplate(ref string) (string, string, error) {
+	if info, err := os.Stat(ref); err == nil && !info.IsDir() {
+		source, err := os.ReadFile(ref)
+		if err != nil {
+			return "", "", err
+		}
+		return strings.TrimSuffix(filepath.Base(ref), ".tmpl"), string(source), nil
+	}
+
+	source, err := services.LoadExportTemplate(ref)
+	return ref, source, err
+}
+
+// writeFilesTo writes generated files into a directory, creating it if needed
+func writeFilesTo(dir string, files []services.ExportFile) error {
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(file.Content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFileWith creates a file and fills it with write
+func writeFileWith(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// defaultString returns value, or fallback if value is empty
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
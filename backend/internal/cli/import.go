@@ -0,0 +1,445 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/config"
+	"github.com/ProjAnvil/knot/backend/internal/database"
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// importAuthor is the author of drafts opened by imports when edits need approval
+const importAuthor = "knot import"
+
+var (
+	importAPIID    uint
+	importRequest  string
+	importResponse string
+	importGroup    string
+	importHost     string
+	importOnly     []string
+	importYes      bool
+	importDepth    int
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import APIs and parameters from other formats",
+	Long:  `Import APIs and parameter trees from documents produced by other tools.`,
+}
+
+var importSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Import a JSON Schema into the parameters of an API",
+	Long: `Replace the request or response parameters of an API with the tree converted
+from a JSON Schema document. Local $ref (e.g. #/$defs/Address) are resolved, allOf
+members are merged, and oneOf/anyOf alternatives become union types whose description
+lists the alternatives. Properties keep their order, required and descriptions.
+The old tree is replaced in a single transaction; when edits need approval, the tree
+is collected into the API's open draft instead. Use "-" to read the schema from stdin.
+
+Examples:
+  knot import schema --api 12 --response order.schema.json
+  cat event.json | knot import schema --api 12 --request -`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if (importRequest == "") == (importResponse == "") {
+			fmt.Fprintln(os.Stderr, "❌ Specify exactly one of --request or --response")
+			os.Exit(2)
+		}
+
+		paramType, file := "response", importResponse
+		if importRequest != "" {
+			paramType, file = "request", importRequest
+		}
+
+		data, err := readInputFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to read schema: %v\n", err)
+			os.Exit(1)
+		}
+
+		nodes, err := services.ParameterNodesFromJSONSchema(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Invalid schema: %v\n", err)
+			os.Exit(1)
+		}
+
+		importParameterNodes(paramType, nodes)
+	},
+}
+
+var importHARCmd = &cobra.Command{
+	Use:   "har <file.har>",
+	Short: "Create APIs from the calls in a HAR recording",
+	Long: `Create APIs in a group from the JSON calls recorded in a HAR file, e.g. a browser
+session saved from the developer tools. Calls are deduplicated by method and path, with
+identifier segments such as /users/42 becoming /users/:id. Request parameters are
+inferred from the JSON request bodies, or from the query strings of calls without a
+body, and response parameters from the bodies of successful responses. Calls matching
+an API documented in the group are skipped.
+
+The APIs found are listed first; confirm to create them, or pass --yes.
+
+Examples:
+  knot import har --group orders session.har
+  knot import har --group orders --host api.example.com --yes session.har
+  knot import har --group orders --only "GET /orders/:id" session.har`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := readInputFile(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to read HAR file: %v\n", err)
+			os.Exit(1)
+		}
+
+		db := openExportDatabase()
+
+		var group models.Group
+		if err := db.Where("name = ?", importGroup).First(&group).Error; err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Group %q not found\n", importGroup)
+			os.Exit(1)
+		}
+
+		candidates, err := services.PreviewHARImport(db, group, data, services.HARImportOptions{Host: importHost})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		pending := 0
+		for _, candidate := range candidates {
+			status := importStatus(candidate.Key, candidate.ExistingAPIID)
+			if status == "new" {
+				pending++
+			}
+			fmt.Printf("  %-40s %3d calls  %2d request / %2d response fields  %s\n", candidate.Key, candidate.Calls,
+				len(candidate.RequestParameters), len(candidate.ResponseParameters), status)
+		}
+
+		if !confirmImport(pending, group) {
+			return
+		}
+
+		created, err := services.ImportHARCandidates(db, group, candidates, importOnly)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to import APIs: %v\n", err)
+			os.Exit(1)
+		}
+		recordCreatedAPIs(db, created)
+		fmt.Printf("✓ Created %d APIs in group %s\n", len(created), group.Name)
+	},
+}
+
+var importAsyncAPICmd = &cobra.Command{
+	Use:   "asyncapi <file>",
+	Short: "Create EVENT APIs from an AsyncAPI document",
+	Long: `Create EVENT APIs in a group from the operations of an AsyncAPI 2.x or 3.x document
+in YAML or JSON. Each operation becomes an API on its channel: send operations of 3.x and
+subscribe operations of 2.x, which are named from the client's side, publish messages.
+The message payload schema becomes the parameters, and the protocol is taken from the
+channel bindings or servers. Operations matching an EVENT API of the group are skipped.
+Use "-" to read the document from stdin.
+
+The APIs found are listed first; confirm to create them, or pass --yes.
+
+Examples:
+  knot import asyncapi --group orders orders.asyncapi.yaml
+  knot import asyncapi --group orders --only "PUBLISH orders.created" --yes orders.asyncapi.yaml`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := readInputFile(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to read AsyncAPI document: %v\n", err)
+			os.Exit(1)
+		}
+
+		db := openExportDatabase()
+
+		var group models.Group
+		if err := db.Where("name = ?", importGroup).First(&group).Error; err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Group %q not found\n", importGroup)
+			os.Exit(1)
+		}
+
+		candidates, err := services.PreviewAsyncAPIImport(db, group, data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		pending := 0
+		for _, candidate := range candidates {
+			status := importStatus(candidate.Key, candidate.ExistingAPIID)
+			if status == "new" {
+				pending++
+			}
+			fmt.Printf("  %-40s %-8s %2d payload fields  %s\n", candidate.Key, candidate.Protocol, len(candidate.PayloadParameters), status)
+		}
+
+		if !confirmImport(pending, group) {
+			return
+		}
+
+		created, err := services.ImportAsyncAPICandidates(db, group, candidates, importOnly)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to import APIs: %v\n", err)
+			os.Exit(1)
+		}
+		recordCreatedAPIs(db, created)
+		fmt.Printf("✓ Created %d APIs in group %s\n", len(created), group.Name)
+	},
+}
+
+var importGraphQLCmd = &cobra.Command{
+	Use:   "graphql <schema.graphql>",
+	Short: "Create GRAPHQL APIs from a GraphQL schema",
+	Long: `Create GRAPHQL APIs in a group from the query, mutation and subscription fields of a
+GraphQL schema in SDL. The arguments of each field become its request parameters, and its
+return type becomes the response parameters, with object types expanded --depth levels deep.
+The SDL of each field and the types it uses is kept as the signature of its API. Fields
+matching a GRAPHQL API of the group are skipped. Use "-" to read the schema from stdin.
+
+The APIs found are listed first; confirm to create them, or pass --yes.
+
+Examples:
+  knot import graphql --group bff schema.graphql
+  knot import graphql --group bff --depth 2 --only "QUERY user" --yes schema.graphql`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := readInputFile(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to read GraphQL schema: %v\n", err)
+			os.Exit(1)
+		}
+
+		db := openExportDatabase()
+
+		var group models.Group
+		if err := db.Where("name = ?", importGroup).First(&group).Error; err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Group %q not found\n", importGroup)
+			os.Exit(1)
+		}
+
+		candidates, err := services.PreviewGraphQLImport(db, group, string(data), importDepth)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		pending := 0
+		for _, candidate := range candidates {
+			status := importStatus(candidate.Key, candidate.ExistingAPIID)
+			if status == "new" {
+				pending++
+			}
+			fmt.Printf("  %-40s %2d arguments  %s\n", candidate.Key, len(candidate.RequestParameters), status)
+		}
+
+		if !confirmImport(pending, group) {
+			return
+		}
+
+		created, err := services.ImportGraphQLCandidates(db, group, candidates, importOnly)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to import APIs: %v\n", err)
+			os.Exit(1)
+		}
+		recordCreatedAPIs(db, created)
+		fmt.Printf("✓ Created %d APIs in group %s\n", len(created), group.Name)
+	},
+}
+
+func init() {
+	importSchemaCmd.Flags().UintVar(&importAPIID, "api", 0, "ID of the API to update")
+	importSchemaCmd.Flags().StringVar(&importRequest, "request", "", "schema file of the request body, or - for stdin")
+	importSchemaCmd.Flags().StringVar(&importResponse, "response", "", "schema file of the response body, or - for stdin")
+	importSchemaCmd.MarkFlagRequired("api")
+
+	importHARCmd.Flags().StringVar(&importGroup, "group", "", "name of the group receiving the APIs")
+	importHARCmd.Flags().StringVar(&importHost, "host", "", "only import calls to this host")
+	importHARCmd.Flags().StringArrayVar(&importOnly, "only", nil, `only import this API, e.g. "GET /orders/:id" (repeatable)`)
+	importHARCmd.Flags().BoolVarP(&importYes, "yes", "y", false, "create the APIs without asking")
+	importHARCmd.MarkFlagRequired("group")
+
+	importAsyncAPICmd.Flags().StringVar(&importGroup, "group", "", "name of the group receiving the APIs")
+	importAsyncAPICmd.Flags().StringArrayVar(&importOnly, "only", nil, `only import this API, e.g. "PUBLISH orders.created" (repeatable)`)
+	importAsyncAPICmd.Flags().BoolVarP(&importYes, "yes", "y", false, "create the APIs without asking")
+	importAsyncAPICmd.MarkFlagRequired("group")
+
+	importGraphQLCmd.Flags().StringVar(&importGroup, "group", "", "name of the group receiving the APIs")
+	importGraphQLCmd.Flags().IntVar(&importDepth, "depth", services.DefaultGraphQLDepth, fmt.Sprintf("levels of object types expanded in responses (at most %d)", services.MaxGraphQLDepth))
+	importGraphQLCmd.Flags().StringArrayVar(&importOnly, "only", nil, `only import this API, e.g. "QUERY user" (repeatable)`)
+	importGraphQLCmd.Flags().BoolVarP(&importYes, "yes", "y", false, "create the APIs without asking")
+	importGraphQLCmd.MarkFlagRequired("group")
+
+	importCmd.AddCommand(importSchemaCmd)
+	importCmd.AddCommand(importHARCmd)
+	importCmd.AddCommand(importAsyncAPICmd)
+	importCmd.AddCommand(importGraphQLCmd)
+}
+
+// importParameterNodes validates an imported tree and stores it as the parameters of the
+// API given by --api, or in its open draft when edits need approval. It exits on failure.
+func importParameterNodes(paramType string, nodes []services.ParameterNode) {
+	if issues := services.ValidateParameterNodes(nodes); len(issues) > 0 {
+		fmt.Fprintln(os.Stderr, "❌ The imported parameter tree is invalid")
+		for _, issue := range issues {
+			fmt.Fprintf(os.Stderr, "   %s  %s: %s\n", issue.Path, issue.Code, issue.Message)
+		}
+		os.Exit(1)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	database.Output = io.Discard
+	db, err := database.InitDatabase(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to initialize database: %v\n", err)
+		os.Exit(1)
+	}
+
+	var api models.API
+	if err := db.First(&api, importAPIID).Error; err != nil {
+		fmt.Fprintf(os.Stderr, "❌ API %d not found\n", importAPIID)
+		os.Exit(1)
+	}
+
+	if cfg.RequireApproval {
+		draft, err := services.OpenDraft(db, api, importAuthor)
+		if err == nil {
+			err = services.SetDraftParameterNodes(&draft, paramType, nodes)
+		}
+		if err == nil {
+			err = db.Save(&draft).Error
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to update draft: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Collected %s parameters of %s %s into draft %d for approval\n", paramType, api.Method, api.Endpoint, draft.ID)
+		return
+	}
+
+	before, err := loadImportTree(db, api.ID, paramType)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to fetch existing parameters: %v\n", err)
+		os.Exit(1)
+	}
+
+	var count int
+	err = db.Transaction(func(tx *gorm.DB) error {
+		count, err = services.ReplaceParameterTree(tx, api.ID, paramType, nodes)
+		return err
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to replace parameters: %v\n", err)
+		os.Exit(1)
+	}
+	recordImportedParameters(db, api, paramType, before)
+	fmt.Printf("✓ Imported %d %s parameters into %s %s (%s)\n", count, paramType, api.Method, api.Endpoint, api.Name)
+}
+
+// recordCreatedAPIs adds imported APIs to the change history, so running servers and
+// their mock routes and MCP resources pick them up
+func recordCreatedAPIs(db *gorm.DB, created []models.API) {
+	for _, api := range created {
+		err := services.RecordChange(db, services.ChangeEvent{
+			Type:    services.EventAPICreated,
+			GroupID: api.GroupID,
+			APIID:   api.ID,
+			Diff:    services.DiffValues(nil, services.APISnapshot(api)),
+			Source:  services.ChangeSourceCLI,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Failed to record the import of %s %s: %v\n", api.Method, api.Endpoint, err)
+		}
+	}
+}
+
+// loadImportTree loads the request or response parameter tree of an API
+func loadImportTree(db *gorm.DB, apiID uint, paramType string) ([]models.Parameter, error) {
+	var params []models.Parameter
+	if err := db.Where("api_id = ? AND param_type = ?", apiID, paramType).Order("`order` ASC").Find(&params).Error; err != nil {
+		return nil, err
+	}
+	return services.BuildParameterTree(params), nil
+}
+
+// recordImportedParameters adds a replaced parameter tree to the change history
+func recordImportedParameters(db *gorm.DB, api models.API, paramType string, before []models.Parameter) {
+	after, err := loadImportTree(db, api.ID, paramType)
+	if err == nil {
+		err = services.RecordChange(db, services.ChangeEvent{
+			Type:    services.EventParametersUpdated,
+			GroupID: api.GroupID,
+			APIID:   api.ID,
+			Diff: services.DiffValues(
+				map[string]interface{}{paramType: services.ParameterSnapshot(before)},
+				map[string]interface{}{paramType: services.ParameterSnapshot(after)},
+			),
+			Source: services.ChangeSourceCLI,
+		})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Failed to record the parameter change: %v\n", err)
+	}
+}
+
+// importStatus describes what an import does with a candidate, given --only
+func importStatus(key string, existingAPIID *uint) string {
+	switch {
+	case existingAPIID != nil:
+		return fmt.Sprintf("documented (API %d), skipped", *existingAPIID)
+	case len(importOnly) > 0 && !containsKey(importOnly, key):
+		return "not selected"
+	}
+	return "new"
+}
+
+// containsKey reports whether keys contains key
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// confirmImport asks whether to create the pending APIs of an import, unless --yes is set
+func confirmImport(pending int, group models.Group) bool {
+	if pending == 0 {
+		fmt.Println("Nothing to import")
+		return false
+	}
+	if importYes {
+		return true
+	}
+
+	fmt.Printf("Create %d APIs in group %s? [y/N] ", pending, group.Name)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+		fmt.Println("Aborted")
+		return false
+	}
+	return true
+}
+
+// readInputFile reads a file, or stdin for "-"
+func readInputFile(file string) ([]byte, error) {
+	if file == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(file)
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/ProjAnvil/knot/backend/internal/config"
+	"github.com/ProjAnvil/knot/backend/internal/database"
+	"github.com/ProjAnvil/knot/backend/internal/mcpserver"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/spf13/cobra"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Run the MCP server on stdio",
+	Long: `Run the MCP (Model Context Protocol) server on stdin and stdout, reading the
+documentation straight from the configured database. No running Knot server or
+separate knot-mcp binary is needed.
+
+Configure your MCP client to launch it, for example in Claude Desktop:
+
+  "knot": { "command": "knot", "args": ["mcp"] }
+
+A running server also serves MCP at /mcp (Streamable HTTP) and at /mcp/sse (SSE).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to load config: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Stdout carries the protocol: keep status messages and SQL logs off it
+		cfg.EnableLogging = false
+		database.Output = io.Discard
+		db, err := database.InitDatabase(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to initialize database: %v\n", err)
+			os.Exit(1)
+		}
+
+		// The resource watcher stops with ctx once ServeStdio returns on SIGINT, SIGTERM or closed stdin
+		ctx, stop := context.WithCancel(context.Background())
+		errorLogger := log.New(os.Stderr, "knot mcp: ", log.LstdFlags)
+		err = server.ServeStdio(mcpserver.New(ctx, db, cfg), server.WithErrorLogger(errorLogger))
+		stop()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ MCP server stopped: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
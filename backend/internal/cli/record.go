@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+
+	"github.com/ProjAnvil/knot/backend/internal/config"
+	"github.com/ProjAnvil/knot/backend/internal/database"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/spf13/cobra"
+)
+
+// recordMaxBodyBytes limits how much of a body is buffered for inference.
+// Larger bodies are forwarded unchanged but not recorded.
+const recordMaxBodyBytes = 1 << 20
+
+var (
+	recordUpstream string
+	recordGroup    string
+	recordPort     int
+	recordHost     string
+)
+
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record live traffic and propose API documentation",
+	Long: `Run a reverse proxy that forwards every request to an upstream server and
+records the observed calls. Calls are grouped by method and path template
+(numeric, UUID and hex segments become parameters), and request and response
+parameter trees are inferred from the JSON bodies. A field is marked required
+only when it was present in every observed sample.
+
+Nothing is published directly: calls matching a documented API of the group
+update a draft of that API kept apart from the drafts people edit, other calls
+open drafts proposing new APIs. Review them with the drafts API.
+
+Examples:
+  knot record --upstream http://localhost:8080 --group orders
+  knot record --upstream https://staging.example.com --port 4020`,
+	Run: func(cmd *cobra.Command, args []string) {
+		upstream, err := url.Parse(recordUpstream)
+		if err != nil || upstream.Scheme == "" || upstream.Host == "" {
+			fmt.Println("❌ --upstream must be an absolute URL, e.g. http://localhost:8080")
+			os.Exit(1)
+		}
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Printf("❌ Failed to load config: %v\n", err)
+			os.Exit(1)
+		}
+
+		db, err := database.InitDatabase(cfg)
+		if err != nil {
+			fmt.Printf("❌ Failed to initialize database: %v\n", err)
+			os.Exit(1)
+		}
+
+		recorder, err := services.NewRecorder(db, recordGroup)
+		if err != nil {
+			fmt.Printf("❌ Failed to prepare group %q: %v\n", recordGroup, err)
+			os.Exit(1)
+		}
+
+		addr := fmt.Sprintf("%s:%d", recordHost, recordPort)
+		fmt.Printf("⏺  Recording %s into group %q on http://%s\n", upstream, recorder.Group().Name, addr)
+
+		if err := http.ListenAndServe(addr, newRecordingProxy(recorder, upstream)); err != nil {
+			fmt.Printf("❌ Failed to start recording proxy: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// newRecordingProxy returns a reverse proxy to upstream that records the calls it forwards
+func newRecordingProxy(recorder *services.Recorder, upstream *url.URL) http.Handler {
+	proxy := httputil.NewSingleHostReverseProxy(upstream)
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		req.Host = upstream.Host
+		// Let the transport negotiate compression so recorded bodies are readable
+		req.Header.Del("Accept-Encoding")
+	}
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		recordResponse(recorder, resp)
+		return nil
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, complete, err := bufferBody(&r.Body)
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadGateway)
+			return
+		}
+		if complete {
+			r = r.WithContext(context.WithValue(r.Context(), recordedRequestKey{}, body))
+		}
+		proxy.ServeHTTP(w, r)
+	})
+}
+
+// recordedRequestKey stores the buffered request body in the request context
+type recordedRequestKey struct{}
+
+// recordResponse records a successful call whose bodies were buffered completely
+func recordResponse(recorder *services.Recorder, resp *http.Response) {
+	requestBody, ok := resp.Request.Context().Value(recordedRequestKey{}).([]byte)
+	if !ok || resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return
+	}
+
+	responseBody, complete, err := bufferBody(&resp.Body)
+	if err != nil || !complete {
+		return
+	}
+
+	call, err := recorder.Observe(resp.Request.Method, resp.Request.URL.RequestURI(), requestBody, responseBody)
+	if err != nil {
+		fmt.Printf("⚠️  Failed to record %s %s: %v\n", resp.Request.Method, resp.Request.URL.Path, err)
+		return
+	}
+
+	target := "new API"
+	if call.APIID != nil {
+		target = fmt.Sprintf("API %d", *call.APIID)
+	}
+	fmt.Printf("● %s %s → draft %d (%s, %d calls)\n", call.Method, call.Endpoint, call.DraftID, target, call.Calls)
+}
+
+// bufferBody reads up to recordMaxBodyBytes of a body and replaces it with a reader
+// that yields the same bytes. It reports whether the whole body was buffered.
+func bufferBody(body *io.ReadCloser) ([]byte, bool, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, true, nil
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(*body, recordMaxBodyBytes+1))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(buf) > recordMaxBodyBytes {
+		*body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(buf), *body), *body}
+		return nil, false, nil
+	}
+
+	(*body).Close()
+	*body = io.NopCloser(bytes.NewReader(buf))
+	return buf, true, nil
+}
+
+func init() {
+	recordCmd.Flags().StringVar(&recordUpstream, "upstream", "", "URL of the server to forward requests to")
+	recordCmd.Flags().StringVar(&recordGroup, "group", "Recorded", "group receiving the proposed APIs, created if missing")
+	recordCmd.Flags().IntVar(&recordPort, "port", 4020, "port to listen on")
+	recordCmd.Flags().StringVar(&recordHost, "host", "localhost", "host to listen on")
+	recordCmd.MarkFlagRequired("upstream")
+}
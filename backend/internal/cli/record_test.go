@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+)
+
+func TestRecordingProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /orders/7":
+			io.WriteString(w, `{"id": 7, "total": 9.5}`)
+		case "POST /customers":
+			body, _ := io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, `{"id": 1, "request": `+string(body)+`}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(upstream.Close)
+
+	db := newTestDB(t)
+	group := models.Group{Name: "orders"}
+	if err := db.Create(&group).Error; err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	api := models.API{GroupID: group.ID, Name: "Get order", Endpoint: "/orders/:id", Method: "GET", Type: "HTTP"}
+	if err := db.Create(&api).Error; err != nil {
+		t.Fatalf("create API: %v", err)
+	}
+	description := "Order ID"
+	documented := models.Parameter{APIID: api.ID, ParamType: "response", Name: "id", Type: "number", Description: &description}
+	if err := db.Create(&documented).Error; err != nil {
+		t.Fatalf("create parameter: %v", err)
+	}
+
+	// A person is editing the API while traffic is recorded
+	edited, err := services.OpenDraft(db, api, "alice")
+	if err != nil {
+		t.Fatalf("OpenDraft: %v", err)
+	}
+	edited.Name = "Fetch order"
+	if err := db.Save(&edited).Error; err != nil {
+		t.Fatalf("save draft: %v", err)
+	}
+
+	recorder, err := services.NewRecorder(db, group.Name)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	upstreamURL, _ := url.Parse(upstream.URL)
+	proxy := httptest.NewServer(newRecordingProxy(recorder, upstreamURL))
+	t.Cleanup(proxy.Close)
+
+	for i := 0; i < 2; i++ {
+		resp, err := http.Get(proxy.URL + "/orders/7")
+		if err != nil {
+			t.Fatalf("GET through proxy: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(body) != `{"id": 7, "total": 9.5}` {
+			t.Fatalf("proxied response = %d %s", resp.StatusCode, body)
+		}
+	}
+	resp, err := http.Post(proxy.URL+"/customers", "application/json", strings.NewReader(`{"name": "Ada"}`))
+	if err != nil {
+		t.Fatalf("POST through proxy: %v", err)
+	}
+	resp.Body.Close()
+	if resp, err := http.Get(proxy.URL + "/missing"); err == nil {
+		resp.Body.Close()
+	}
+
+	// The person's draft is untouched
+	var draft models.APIDraft
+	if err := db.First(&draft, edited.ID).Error; err != nil {
+		t.Fatalf("load draft: %v", err)
+	}
+	if draft.Name != "Fetch order" || draft.ResponseParameters != edited.ResponseParameters {
+		t.Errorf("edited draft changed: name %q, response %s", draft.Name, draft.ResponseParameters)
+	}
+
+	// The recorder proposes the observed fields in its own draft, keeping the documentation
+	var recorded models.APIDraft
+	if err := db.Where("api_id = ? AND author = ?", api.ID, services.RecorderAuthor).First(&recorded).Error; err != nil {
+		t.Fatalf("load recorder draft: %v", err)
+	}
+	nodes, err := services.DraftParameterNodes(recorded, "response")
+	if err != nil {
+		t.Fatalf("DraftParameterNodes: %v", err)
+	}
+	if len(nodes) != 2 || nodes[0].Name != "id" || nodes[1].Name != "total" {
+		t.Fatalf("recorded response = %+v, want id and total", nodes)
+	}
+	if nodes[0].ID != documented.ID || nodes[0].Description == nil || *nodes[0].Description != description {
+		t.Errorf("id = %+v, want the documented parameter's ID and description", nodes[0])
+	}
+	if recorded.Summary == nil || *recorded.Summary != "Recorded from 2 observed calls" {
+		t.Errorf("summary = %v, want the number of recorded calls", recorded.Summary)
+	}
+
+	// Undocumented calls propose new APIs; failed calls are not recorded
+	var proposed []models.APIDraft
+	db.Where("api_id IS NULL AND group_id = ?", group.ID).Find(&proposed)
+	if len(proposed) != 1 || proposed[0].Method != "POST" || proposed[0].Endpoint != "/customers" {
+		t.Fatalf("proposed drafts = %+v, want POST /customers", proposed)
+	}
+	request, _ := services.DraftParameterNodes(proposed[0], "request")
+	if len(request) != 1 || request[0].Name != "name" || request[0].Type != "string" {
+		t.Errorf("proposed request = %+v, want name", request)
+	}
+}
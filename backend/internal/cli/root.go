@@ -35,5 +35,12 @@ func init() {
 	rootCmd.AddCommand(stopCmd)
 	rootCmd.AddCommand(restartCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(mockCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(recordCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(codegenCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(mcpCmd)
 	rootCmd.AddCommand(serveCmd) // Hidden command for internal use
 }
@@ -1,15 +1,20 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/ProjAnvil/knot/backend/internal/config"
 	"github.com/ProjAnvil/knot/backend/internal/database"
 	"github.com/ProjAnvil/knot/backend/internal/embedded"
 	"github.com/ProjAnvil/knot/backend/internal/handlers"
+	"github.com/ProjAnvil/knot/backend/internal/mcpserver"
+	"github.com/ProjAnvil/knot/backend/internal/services"
 	"github.com/ProjAnvil/knot/backend/pkg/logger"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -54,6 +59,15 @@ func runServer() {
 		AppName: "Knot",
 	})
 
+	// Shut down on SIGINT or SIGTERM. Background work such as the MCP resource watcher
+	// stops with ctx; open MCP streams get a few seconds to finish.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		app.ShutdownWithTimeout(5 * time.Second)
+	}()
+
 	// Middleware
 	app.Use(recover.New())
 	app.Use(cors.New())
@@ -82,21 +96,116 @@ func runServer() {
 	apis := api.Group("/apis")
 	apis.Get("/:id", handlers.GetAPI(db))
 	apis.Get("/group/:groupId", handlers.GetAPIsByGroup(db))
-	apis.Post("/", handlers.CreateAPI(db))
-	apis.Patch("/:id", handlers.UpdateAPI(db))
-	apis.Patch("/:id/note", handlers.UpdateAPINote(db))
+	apis.Post("/", handlers.CreateAPI(db, cfg))
+	apis.Patch("/:id", handlers.UpdateAPI(db, cfg))
+	apis.Patch("/:id/note", handlers.UpdateAPINote(db, cfg))
 	apis.Post("/orders", handlers.UpdateAPIOrders(db))
 	apis.Delete("/:id", handlers.DeleteAPI(db))
-	apis.Put("/:id/parameters", handlers.UpdateParameters(db))
-	apis.Post("/:id/parameters/from-json", handlers.UpdateParametersFromJSON(db))
+	apis.Put("/:id/parameters", handlers.UpdateParameters(db, cfg))
+	apis.Post("/:id/parameters/from-json", handlers.UpdateParametersFromJSON(db, cfg))
+	apis.Post("/:id/parameters/from-schema", handlers.UpdateParametersFromSchema(db, cfg))
+
+	// Parameter editing routes
+	apis.Post("/:id/parameters", handlers.AddParameter(db, cfg))
+	apis.Patch("/:id/parameters", handlers.PatchParameters(db, cfg))
+	parameters := api.Group("/parameters")
+	parameters.Patch("/:id", handlers.UpdateParameter(db, cfg))
+	parameters.Post("/:id/move", handlers.MoveParameter(db, cfg))
+	parameters.Delete("/:id", handlers.DeleteParameter(db, cfg))
+
+	// Comments routes
+	apis.Get("/:id/comments", handlers.GetComments(db))
+	apis.Post("/:id/comments", handlers.CreateComment(db))
+	comments := api.Group("/comments")
+	comments.Patch("/:id/resolve", handlers.ResolveComment(db))
+
+	// Drafts routes
+	apis.Get("/:id/drafts", handlers.GetAPIDrafts(db))
+	apis.Post("/:id/drafts", handlers.CreateDraft(db))
+	drafts := api.Group("/drafts")
+	drafts.Get("/", handlers.GetDrafts(db))
+	drafts.Get("/:id", handlers.GetDraft(db))
+	drafts.Patch("/:id", handlers.UpdateDraft(db))
+	drafts.Put("/:id/parameters", handlers.UpdateDraftParameters(db))
+	drafts.Patch("/:id/parameters", handlers.PatchDraftParameters(db))
+	drafts.Post("/:id/parameters/from-json", handlers.UpdateDraftParametersFromJSON(db))
+	drafts.Post("/:id/approve", handlers.ApproveDraft(db))
+	drafts.Post("/:id/reject", handlers.RejectDraft(db))
+	drafts.Post("/:id/rebase", handlers.RebaseDraft(db))
+
+	// Request executor routes
+	apis.Post("/:id/execute", handlers.ExecuteAPI(db))
+	apis.Get("/:id/history", handlers.GetRequestHistory(db))
+	apis.Delete("/:id/history", handlers.ClearRequestHistory(db))
+
+	// Environments routes
+	environments := api.Group("/environments")
+	environments.Get("/", handlers.GetEnvironments(db))
+	environments.Post("/", handlers.CreateEnvironment(db))
+	environments.Get("/:id", handlers.GetEnvironment(db))
+	environments.Patch("/:id", handlers.UpdateEnvironment(db))
+	environments.Delete("/:id", handlers.DeleteEnvironment(db))
+
+	// Payload validation routes
+	apis.Post("/:id/validate", handlers.ValidateAPIPayload(db))
+
+	// Code generation routes
+	apis.Get("/:id/codegen", handlers.GenerateAPIModels(db))
+	apis.Get("/:id/snippets", handlers.GetAPISnippets(db))
+
+	// JSON Schema routes
+	apis.Get("/:id/schemas", handlers.GetAPISchemas(db))
+	apis.Get("/:id/schemas/:paramType", handlers.GetAPISchema(db))
+	groups.Get("/:id/schemas", handlers.ExportGroupSchemas(db))
+
+	// HAR import routes
+	groups.Post("/:id/import/har/preview", handlers.PreviewHARImport(db))
+	groups.Post("/:id/import/har", handlers.ImportHAR(db))
+
+	// AsyncAPI routes
+	groups.Get("/:id/asyncapi", handlers.ExportGroupAsyncAPI(db))
+	groups.Post("/:id/import/asyncapi/preview", handlers.PreviewAsyncAPIImport(db))
+	groups.Post("/:id/import/asyncapi", handlers.ImportAsyncAPI(db))
+
+	// GraphQL import routes
+	groups.Post("/:id/import/graphql/preview", handlers.PreviewGraphQLImport(db))
+	groups.Post("/:id/import/graphql", handlers.ImportGraphQL(db))
+
+	// Webhooks routes
+	webhooks := api.Group("/webhooks")
+	webhooks.Get("/", handlers.GetWebhooks(db))
+	webhooks.Post("/", handlers.CreateWebhook(db))
+	webhooks.Patch("/:id", handlers.UpdateWebhook(db))
+	webhooks.Delete("/:id", handlers.DeleteWebhook(db))
+	webhooks.Get("/:id/deliveries", handlers.GetWebhookDeliveries(db))
+	webhooks.Post("/:id/test", handlers.TestWebhook(db))
+
+	// Export template routes
+	templates := api.Group("/templates")
+	templates.Get("/", handlers.GetTemplates())
+	templates.Get("/:name", handlers.GetTemplate())
+	templates.Put("/:name", handlers.SaveTemplate())
+	templates.Delete("/:name", handlers.DeleteTemplate())
 
 	// Export routes
 	export := api.Group("/export")
 	export.Post("/", handlers.ExportAPIs(db))
 
+	// Change history routes
+	api.Get("/changes", handlers.GetChanges(db))
+
 	// MCP Tools routes
 	mcpTools := api.Group("/mcp-tools")
-	mcpTools.Post("/", handlers.HandleMCPTools(db))
+	mcpTools.Post("/", handlers.HandleMCPTools(db, cfg))
+
+	// MCP server over Streamable HTTP and SSE
+	mcpTransports := mcpserver.NewHTTPTransports(mcpserver.New(ctx, db, cfg))
+	app.All(mcpserver.StreamableHTTPPath, mcpTransports.StreamableHTTP)
+	app.Get(mcpserver.SSEPath, mcpTransports.SSE)
+	app.Post(mcpserver.SSEMessagePath, mcpTransports.SSEMessage)
+
+	// Mock server for documented HTTP APIs
+	app.All("/mock/*", handlers.MockServer(db, services.MockOptions{}))
 
 	// Static file serving for frontend (embedded in CLI)
 	if embedded.HasFrontend() {
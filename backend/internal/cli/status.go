@@ -24,7 +24,7 @@ var statusCmd = &cobra.Command{
 			return
 		}
 
-		fmt.Println("\n📊 Knot Server Status\n")
+		fmt.Print("\n📊 Knot Server Status\n\n")
 
 		if pid == 0 {
 			fmt.Println("Status:  ⭕ Not running")
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ProjAnvil/knot/backend/internal/config"
+	"github.com/ProjAnvil/knot/backend/internal/database"
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/spf13/cobra"
+)
+
+var (
+	validateAPIID    uint
+	validateRequest  string
+	validateResponse string
+	validateJSON     bool
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate a JSON payload against a documented API",
+	Long: `Validate a captured request or response payload against the documented
+parameters of an API. Reports missing required fields, type mismatches and
+undocumented fields with their JSON path. Exits with status 1 if the payload
+does not conform. Use "-" to read the payload from stdin.
+
+Examples:
+  knot validate --api 12 --response response.json
+  curl -s https://api.example.com/users/1 | knot validate --api 12 --response -
+  knot validate --api 12 --request body.json --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if (validateRequest == "") == (validateResponse == "") {
+			fmt.Fprintln(os.Stderr, "❌ Specify exactly one of --request or --response")
+			os.Exit(2)
+		}
+
+		paramType, file := "response", validateResponse
+		if validateRequest != "" {
+			paramType, file = "request", validateRequest
+		}
+
+		var data []byte
+		var err error
+		if file == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(file)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to read payload: %v\n", err)
+			os.Exit(2)
+		}
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to load config: %v\n", err)
+			os.Exit(2)
+		}
+
+		database.Output = io.Discard
+		db, err := database.InitDatabase(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to initialize database: %v\n", err)
+			os.Exit(2)
+		}
+
+		var api models.API
+		if err := db.First(&api, validateAPIID).Error; err != nil {
+			fmt.Fprintf(os.Stderr, "❌ API %d not found\n", validateAPIID)
+			os.Exit(2)
+		}
+
+		version, err := services.LoadPublishedVersion(db, api)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to load parameters: %v\n", err)
+			os.Exit(2)
+		}
+
+		tree := version.ResponseParameters
+		if paramType == "request" {
+			tree = version.RequestParameters
+		}
+		issues := services.ValidateJSON(tree, data)
+
+		if validateJSON {
+			out, _ := json.MarshalIndent(map[string]interface{}{
+				"apiId":     api.ID,
+				"paramType": paramType,
+				"valid":     len(issues) == 0,
+				"issues":    issues,
+			}, "", "  ")
+			fmt.Println(string(out))
+		} else if len(issues) == 0 {
+			fmt.Printf("✓ %s payload conforms to %s %s (%s)\n", paramType, api.Method, api.Endpoint, api.Name)
+		} else {
+			fmt.Printf("❌ %s payload does not conform to %s %s (%s)\n", paramType, api.Method, api.Endpoint, api.Name)
+			for _, issue := range issues {
+				fmt.Printf("   %s  %s: %s\n", issue.Path, issue.Code, issue.Message)
+			}
+		}
+
+		if len(issues) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	validateCmd.Flags().UintVar(&validateAPIID, "api", 0, "ID of the documented API")
+	validateCmd.Flags().StringVar(&validateRequest, "request", "", "request payload file, or - for stdin")
+	validateCmd.Flags().StringVar(&validateResponse, "response", "", "response payload file, or - for stdin")
+	validateCmd.Flags().BoolVar(&validateJSON, "json", false, "print the result as JSON")
+	validateCmd.MarkFlagRequired("api")
+}
@@ -11,13 +11,15 @@ import (
 
 // Config holds the application configuration
 type Config struct {
-	DatabaseType  string `mapstructure:"databaseType"`
-	SQLitePath    string `mapstructure:"sqlitePath"`
-	PostgresURL   string `mapstructure:"postgresUrl"`
-	MySQLURL      string `mapstructure:"mysqlUrl"`
-	Port          int    `mapstructure:"port"`
-	Host          string `mapstructure:"host"`
-	EnableLogging bool   `mapstructure:"enableLogging"`
+	DatabaseType    string `mapstructure:"databaseType"`
+	SQLitePath      string `mapstructure:"sqlitePath"`
+	PostgresURL     string `mapstructure:"postgresUrl"`
+	MySQLURL        string `mapstructure:"mysqlUrl"`
+	Port            int    `mapstructure:"port"`
+	Host            string `mapstructure:"host"`
+	EnableLogging   bool   `mapstructure:"enableLogging"`
+	RequireApproval bool   `mapstructure:"requireApproval"` // Collect edits into drafts that need approval
+	MCPWrite        bool   `mapstructure:"mcpWrite"`        // Expose the MCP tools that create and update documentation
 }
 
 // GetUserDataDir returns the user data directory for Knot
@@ -57,6 +59,11 @@ func GetPIDPath() string {
 	return filepath.Join(GetUserDataDir(), "knot.pid")
 }
 
+// GetSecretKeyPath returns the path to the key used to encrypt environment secrets
+func GetSecretKeyPath() string {
+	return filepath.Join(GetUserDataDir(), "secret.key")
+}
+
 // GetLogDir returns the log directory path
 func GetLogDir() string {
 	return filepath.Join(GetUserDataDir(), "log")
@@ -93,6 +100,8 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("port", 3000)
 	viper.SetDefault("host", "localhost")
 	viper.SetDefault("enableLogging", false)
+	viper.SetDefault("requireApproval", false)
+	viper.SetDefault("mcpWrite", false)
 
 	// Try to read config file
 	if err := viper.ReadInConfig(); err != nil {
@@ -147,6 +156,8 @@ func InitConfig() error {
 	viper.Set("port", defaultConfig.Port)
 	viper.Set("host", defaultConfig.Host)
 	viper.Set("enableLogging", defaultConfig.EnableLogging)
+	viper.Set("requireApproval", defaultConfig.RequireApproval)
+	viper.Set("mcpWrite", defaultConfig.MCPWrite)
 
 	if err := viper.WriteConfigAs(configPath); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
@@ -169,6 +180,8 @@ func SaveConfig(config *Config) error {
 	viper.Set("port", config.Port)
 	viper.Set("host", config.Host)
 	viper.Set("enableLogging", config.EnableLogging)
+	viper.Set("requireApproval", config.RequireApproval)
+	viper.Set("mcpWrite", config.MCPWrite)
 
 	if err := viper.WriteConfig(); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
@@ -212,6 +225,8 @@ func ShowConfig() error {
 	fmt.Printf("Server Host:     %s\n", config.Host)
 	fmt.Printf("Server Port:     %d\n", config.Port)
 	fmt.Printf("Logging:         %v\n", config.EnableLogging)
+	fmt.Printf("Approval:        %v\n", config.RequireApproval)
+	fmt.Printf("MCP write tools: %v\n", config.MCPWrite)
 	fmt.Printf("\n")
 
 	return nil
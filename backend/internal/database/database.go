@@ -2,6 +2,8 @@ package database
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
 	"github.com/ProjAnvil/knot/backend/internal/config"
@@ -13,6 +15,25 @@ import (
 	"gorm.io/gorm/logger"
 )
 
+// Output receives status messages while the database is initialized.
+// Commands whose stdout is machine-readable set it to io.Discard.
+var Output io.Writer = os.Stdout
+
+// managedModels lists every model whose table is created by the migration
+var managedModels = []interface{}{
+	&models.Group{},
+	&models.API{},
+	&models.Parameter{},
+	&models.Webhook{},
+	&models.WebhookDelivery{},
+	&models.Comment{},
+	&models.APIDraft{},
+	&models.RequestHistory{},
+	&models.Environment{},
+	&models.EnvironmentVariable{},
+	&models.ChangeRecord{},
+}
+
 // InitDatabase initializes the database connection based on configuration
 func InitDatabase(cfg *config.Config) (*gorm.DB, error) {
 	var dialector gorm.Dialector
@@ -23,21 +44,21 @@ func InitDatabase(cfg *config.Config) (*gorm.DB, error) {
 			return nil, fmt.Errorf("PostgreSQL URL not configured. Please set postgresUrl in config")
 		}
 		dialector = postgres.Open(cfg.PostgresURL)
-		fmt.Println("Using PostgreSQL database")
+		fmt.Fprintln(Output, "Using PostgreSQL database")
 
 	case "mysql":
 		if cfg.MySQLURL == "" {
 			return nil, fmt.Errorf("MySQL URL not configured. Please set mysqlUrl in config")
 		}
 		dialector = mysql.Open(cfg.MySQLURL)
-		fmt.Println("Using MySQL database")
+		fmt.Fprintln(Output, "Using MySQL database")
 
 	case "sqlite", "":
 		if cfg.SQLitePath == "" {
 			return nil, fmt.Errorf("SQLite path not configured")
 		}
 		dialector = sqlite.Open(cfg.SQLitePath)
-		fmt.Printf("Using SQLite database: %s\n", cfg.SQLitePath)
+		fmt.Fprintf(Output, "Using SQLite database: %s\n", cfg.SQLitePath)
 
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", cfg.DatabaseType)
@@ -64,7 +85,7 @@ func InitDatabase(cfg *config.Config) (*gorm.DB, error) {
 
 	if !tableExists {
 		// Tables don't exist, run full migration
-		if err := db.AutoMigrate(&models.Group{}, &models.API{}, &models.Parameter{}); err != nil {
+		if err := db.AutoMigrate(managedModels...); err != nil {
 			return nil, fmt.Errorf("failed to run auto-migration: %w", err)
 		}
 	} else {
@@ -72,25 +93,51 @@ func InitDatabase(cfg *config.Config) (*gorm.DB, error) {
 		// Use Migrator to add missing columns only
 		migrator := db.Migrator()
 
-		// Ensure all tables exist
-		if !migrator.HasTable(&models.Group{}) {
-			if err := migrator.CreateTable(&models.Group{}); err != nil {
-				return nil, fmt.Errorf("failed to create groups table: %w", err)
+		// Ensure all tables exist and have every column
+		for _, model := range managedModels {
+			if !migrator.HasTable(model) {
+				if err := migrator.CreateTable(model); err != nil {
+					return nil, fmt.Errorf("failed to create table for %T: %w", model, err)
+				}
+				continue
 			}
-		}
-		if !migrator.HasTable(&models.API{}) {
-			if err := migrator.CreateTable(&models.API{}); err != nil {
-				return nil, fmt.Errorf("failed to create apis table: %w", err)
+
+			stmt := &gorm.Statement{DB: db}
+			if err := stmt.Parse(model); err != nil {
+				return nil, fmt.Errorf("failed to parse model %T: %w", model, err)
 			}
-		}
-		if !migrator.HasTable(&models.Parameter{}) {
-			if err := migrator.CreateTable(&models.Parameter{}); err != nil {
-				return nil, fmt.Errorf("failed to create parameters table: %w", err)
+			columnTypes, err := migrator.ColumnTypes(model)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read columns for %T: %w", model, err)
+			}
+			nullable := make(map[string]bool, len(columnTypes))
+			for _, column := range columnTypes {
+				isNullable, ok := column.Nullable()
+				nullable[column.Name()] = !ok || isNullable
+			}
+
+			for _, field := range stmt.Schema.Fields {
+				if field.DBName == "" {
+					continue
+				}
+				columnNullable, exists := nullable[field.DBName]
+				if !exists {
+					if err := migrator.AddColumn(model, field.Name); err != nil {
+						return nil, fmt.Errorf("failed to add column %s for %T: %w", field.DBName, model, err)
+					}
+					continue
+				}
+				// Relax NOT NULL constraints dropped from the model, e.g. optional foreign keys
+				if !columnNullable && !field.NotNull && !field.PrimaryKey {
+					if err := migrator.AlterColumn(model, field.Name); err != nil {
+						return nil, fmt.Errorf("failed to alter column %s for %T: %w", field.DBName, model, err)
+					}
+				}
 			}
 		}
 	}
 
-	fmt.Println("✓ Database initialized successfully")
+	fmt.Fprintln(Output, "✓ Database initialized successfully")
 
 	return db, nil
 }
@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"strconv"
 
+	"github.com/ProjAnvil/knot/backend/internal/config"
 	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
 	"github.com/ProjAnvil/knot/backend/pkg/response"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -32,6 +35,12 @@ func GetAPI(db *gorm.DB) fiber.Handler {
 			return response.InternalError(c, "Failed to fetch API")
 		}
 
+		unresolved, err := services.CountUnresolvedComments(db, []uint{api.ID})
+		if err != nil {
+			return response.InternalError(c, "Failed to count unresolved comments")
+		}
+		api.UnresolvedComments = unresolved[api.ID]
+
 		return response.Success(c, api)
 	}
 }
@@ -60,45 +69,34 @@ func GetAPIsByGroup(db *gorm.DB) fiber.Handler {
 	}
 }
 
-// CreateAPI creates a new API
-func CreateAPI(db *gorm.DB) fiber.Handler {
+// CreateAPI creates a new API.
+// When approval is required, a draft proposing the API is opened instead.
+func CreateAPI(db *gorm.DB, cfg *config.Config) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var body struct {
-			GroupID  uint    `json:"groupId"`
-			Name     string  `json:"name"`
-			Endpoint string  `json:"endpoint"`
-			Method   string  `json:"method"`
-			Type     string  `json:"type"`
-			Note     *string `json:"note"`
-		}
+		var body newAPIFields
 
 		if err := c.BodyParser(&body); err != nil {
 			return response.BadRequest(c, "Invalid request body")
 		}
 
-		if body.GroupID == 0 || body.Name == "" || body.Endpoint == "" || body.Type == "" {
-			return response.BadRequest(c, "Missing required fields")
-		}
-
-		if body.Type == "HTTP" && body.Method == "" {
-			return response.BadRequest(c, "Method is required for HTTP APIs")
+		api, err := body.build()
+		if err != nil {
+			return response.BadRequest(c, err.Error())
 		}
 
-		// Get max order for this group
-		var maxOrder int
-		db.Model(&models.API{}).Where("group_id = ?", body.GroupID).Select("COALESCE(MAX(`order`), 0)").Scan(&maxOrder)
-
-		api := models.API{
-			GroupID:  body.GroupID,
-			Name:     body.Name,
-			Endpoint: body.Endpoint,
-			Method:   body.Method,
-			Type:     body.Type,
-			Note:     body.Note,
-			Order:    maxOrder + 1,
+		if cfg.RequireApproval {
+			draft, err := services.ProposeNewAPI(db, api, c.Get(EditorHeader))
+			if err != nil {
+				return response.InternalError(c, "Failed to create draft")
+			}
+			c.Status(fiber.StatusAccepted)
+			return response.Success(c, fiber.Map{
+				"pendingApproval": true,
+				"draft":           draft,
+			})
 		}
 
-		if err := db.Create(&api).Error; err != nil {
+		if _, err := insertAPI(db, &api, services.ChangeSourceAPI); err != nil {
 			return response.InternalError(c, "Failed to create API")
 		}
 
@@ -106,21 +104,77 @@ func CreateAPI(db *gorm.DB) fiber.Handler {
 	}
 }
 
-// UpdateAPI updates API basic info
-func UpdateAPI(db *gorm.DB) fiber.Handler {
+// newAPIFields are the fields of an API to create
+type newAPIFields struct {
+	GroupID  uint    `json:"groupId"`
+	Name     string  `json:"name"`
+	Endpoint string  `json:"endpoint"`
+	Method   string  `json:"method"`
+	Type     string  `json:"type"`
+	Protocol *string `json:"protocol"`
+	Note     *string `json:"note"`
+}
+
+// build validates the fields and returns the API they describe
+func (f newAPIFields) build() (models.API, error) {
+	if f.GroupID == 0 || f.Name == "" || f.Endpoint == "" || f.Type == "" {
+		return models.API{}, errors.New("Missing required fields")
+	}
+
+	if f.Type == "HTTP" && f.Method == "" {
+		return models.API{}, errors.New("Method is required for HTTP APIs")
+	}
+
+	method, err := services.NormalizeAPIMethod(f.Type, f.Method)
+	if err != nil {
+		return models.API{}, err
+	}
+
+	return models.API{
+		GroupID:  f.GroupID,
+		Name:     f.Name,
+		Endpoint: f.Endpoint,
+		Method:   method,
+		Type:     f.Type,
+		Protocol: f.Protocol,
+		Note:     f.Note,
+	}, nil
+}
+
+// insertAPI adds an API at the end of its group and publishes the change made from source.
+// It returns the published diff.
+func insertAPI(db *gorm.DB, api *models.API, source string) ([]services.FieldChange, error) {
+	// Get max order for this group
+	var maxOrder int
+	db.Model(&models.API{}).Where("group_id = ?", api.GroupID).Select("COALESCE(MAX(`order`), 0)").Scan(&maxOrder)
+	api.Order = maxOrder + 1
+
+	if err := db.Create(api).Error; err != nil {
+		return nil, err
+	}
+
+	diff := services.DiffValues(nil, services.APISnapshot(*api))
+	services.PublishChange(db, services.ChangeEvent{
+		Type:    services.EventAPICreated,
+		GroupID: api.GroupID,
+		APIID:   api.ID,
+		Entity:  *api,
+		Diff:    diff,
+		Source:  source,
+	})
+	return diff, nil
+}
+
+// UpdateAPI updates API basic info.
+// When approval is required, the change is collected into the API's open draft.
+func UpdateAPI(db *gorm.DB, cfg *config.Config) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
 		if err != nil {
 			return response.BadRequest(c, "Invalid API ID")
 		}
 
-		var body struct {
-			Name     *string `json:"name"`
-			Endpoint *string `json:"endpoint"`
-			Method   *string `json:"method"`
-			Type     *string `json:"type"`
-			Note     *string `json:"note"`
-		}
+		var body apiChanges
 
 		if err := c.BodyParser(&body); err != nil {
 			return response.BadRequest(c, "Invalid request body")
@@ -134,32 +188,96 @@ func UpdateAPI(db *gorm.DB) fiber.Handler {
 			return response.InternalError(c, "Failed to fetch API")
 		}
 
-		if body.Name != nil {
-			api.Name = *body.Name
+		if cfg.RequireApproval {
+			return collectIntoDraft(c, db, api, body.applyToDraft)
 		}
-		if body.Endpoint != nil {
-			api.Endpoint = *body.Endpoint
-		}
-		if body.Method != nil {
-			api.Method = *body.Method
-		}
-		if body.Type != nil {
-			api.Type = *body.Type
-		}
-		if body.Note != nil {
-			api.Note = body.Note
+
+		before := services.APISnapshot(api)
+
+		if err := body.apply(&api); err != nil {
+			return response.BadRequest(c, err.Error())
 		}
 
 		if err := db.Save(&api).Error; err != nil {
 			return response.InternalError(c, "Failed to update API")
 		}
 
+		publishAPIChange(db, api, before, services.ChangeSourceAPI)
+
 		return response.Success(c, api)
 	}
 }
 
+// apiChanges are the basic info fields of an API update. Nil fields are left unchanged.
+type apiChanges struct {
+	Name     *string `json:"name"`
+	Endpoint *string `json:"endpoint"`
+	Method   *string `json:"method"`
+	Type     *string `json:"type"`
+	Protocol *string `json:"protocol"`
+	Note     *string `json:"note"`
+}
+
+// apply updates a published API and normalizes its method
+func (ch apiChanges) apply(api *models.API) error {
+	if ch.Name != nil {
+		api.Name = *ch.Name
+	}
+	if ch.Endpoint != nil {
+		api.Endpoint = *ch.Endpoint
+	}
+	if ch.Method != nil {
+		api.Method = *ch.Method
+	}
+	if ch.Type != nil {
+		api.Type = *ch.Type
+	}
+	if ch.Protocol != nil {
+		api.Protocol = ch.Protocol
+	}
+	if ch.Note != nil {
+		api.Note = ch.Note
+	}
+
+	method, err := services.NormalizeAPIMethod(api.Type, api.Method)
+	if err != nil {
+		return err
+	}
+	api.Method = method
+	return nil
+}
+
+// applyToDraft updates the proposed basic info of a draft and normalizes its method
+func (ch apiChanges) applyToDraft(draft *models.APIDraft) error {
+	if ch.Name != nil {
+		draft.Name = *ch.Name
+	}
+	if ch.Endpoint != nil {
+		draft.Endpoint = *ch.Endpoint
+	}
+	if ch.Method != nil {
+		draft.Method = *ch.Method
+	}
+	if ch.Type != nil {
+		draft.Type = *ch.Type
+	}
+	if ch.Protocol != nil {
+		draft.Protocol = ch.Protocol
+	}
+	if ch.Note != nil {
+		draft.Note = ch.Note
+	}
+
+	method, err := services.NormalizeAPIMethod(draft.Type, draft.Method)
+	if err != nil {
+		return err
+	}
+	draft.Method = method
+	return nil
+}
+
 // UpdateAPINote updates API note
-func UpdateAPINote(db *gorm.DB) fiber.Handler {
+func UpdateAPINote(db *gorm.DB, cfg *config.Config) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
 		if err != nil {
@@ -182,11 +300,22 @@ func UpdateAPINote(db *gorm.DB) fiber.Handler {
 			return response.InternalError(c, "Failed to fetch API")
 		}
 
+		if cfg.RequireApproval {
+			return collectIntoDraft(c, db, api, func(draft *models.APIDraft) error {
+				draft.Note = body.Note
+				return nil
+			})
+		}
+
+		before := services.APISnapshot(api)
+
 		api.Note = body.Note
 		if err := db.Save(&api).Error; err != nil {
 			return response.InternalError(c, "Failed to update API note")
 		}
 
+		publishAPIChange(db, api, before, services.ChangeSourceAPI)
+
 		return response.Success(c, api)
 	}
 }
@@ -231,6 +360,14 @@ func DeleteAPI(db *gorm.DB) fiber.Handler {
 			return response.BadRequest(c, "Invalid API ID")
 		}
 
+		var api models.API
+		if err := db.First(&api, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return response.NotFound(c, "API not found")
+			}
+			return response.InternalError(c, "Failed to fetch API")
+		}
+
 		// Delete the API (parameters will be cascade deleted via foreign key constraint)
 		result := db.Delete(&models.API{}, id)
 		if result.Error != nil {
@@ -241,12 +378,23 @@ func DeleteAPI(db *gorm.DB) fiber.Handler {
 			return response.NotFound(c, "API not found")
 		}
 
+		services.PublishChange(db, services.ChangeEvent{
+			Type:    services.EventAPIDeleted,
+			GroupID: api.GroupID,
+			APIID:   api.ID,
+			Entity:  api,
+			Diff:    services.DiffValues(services.APISnapshot(api), nil),
+		})
+
 		return response.Success(c, nil)
 	}
 }
 
-// UpdateParameters updates parameters from structure
-func UpdateParameters(db *gorm.DB) fiber.Handler {
+// UpdateParameters replaces the request or response parameter tree of an API.
+// The whole tree is validated first; invalid trees are rejected with a details list of
+// per-node issues. Nodes without an ID take the ID of the stored parameter at the same path,
+// so parameters keep their IDs across saves. The tree is stored in a single transaction.
+func UpdateParameters(db *gorm.DB, cfg *config.Config) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
 		if err != nil {
@@ -266,82 +414,130 @@ func UpdateParameters(db *gorm.DB) fiber.Handler {
 			return response.BadRequest(c, "Invalid paramType")
 		}
 
-		// Parse parameters
-		var params []map[string]interface{}
-		if err := json.Unmarshal(body.Parameters, &params); err != nil {
+		var nodes []services.ParameterNode
+		if err := json.Unmarshal(body.Parameters, &nodes); err != nil {
 			return response.BadRequest(c, "Invalid parameters format")
 		}
 
-		// Delete existing parameters of this type
-		if err := db.Where("api_id = ? AND param_type = ?", id, body.ParamType).Delete(&models.Parameter{}).Error; err != nil {
-			return response.InternalError(c, "Failed to delete existing parameters")
+		if issues := services.ValidateParameterNodes(nodes); len(issues) > 0 {
+			return response.ValidationFailed(c, "Invalid parameter tree", issues)
 		}
 
-		if len(params) == 0 {
-			return response.Success(c, fiber.Map{"count": 0})
+		var api models.API
+		if err := db.First(&api, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return response.NotFound(c, "API not found")
+			}
+			return response.InternalError(c, "Failed to fetch API")
 		}
 
-		// Insert parameters recursively
-		order := 0
-		insertedCount := 0
+		if cfg.RequireApproval {
+			return collectIntoDraft(c, db, api, func(draft *models.APIDraft) error {
+				return services.SetDraftParameterNodes(draft, body.ParamType, nodes)
+			})
+		}
 
-		var insertParams func(params []map[string]interface{}, parentID *uint) error
-		insertParams = func(params []map[string]interface{}, parentID *uint) error {
-			for _, param := range params {
-				name, _ := param["name"].(string)
-				paramType, _ := param["type"].(string)
-				description, _ := param["description"].(string)
-				required, _ := param["required"].(bool)
+		before, err := loadParameterTree(db, api.ID, body.ParamType)
+		if err != nil {
+			return response.InternalError(c, "Failed to fetch existing parameters")
+		}
 
-				p := models.Parameter{
-					APIID:     uint(id),
-					ParentID:  parentID,
-					Name:      name,
-					Type:      paramType,
-					Required:  required,
-					ParamType: body.ParamType,
-					Order:     order,
-				}
+		services.MatchParameterIDs(nodes, before)
 
-				if description != "" {
-					p.Description = &description
-				}
+		var insertedCount int
+		err = db.Transaction(func(tx *gorm.DB) error {
+			insertedCount, err = services.SyncParameterTree(tx, api.ID, body.ParamType, nodes)
+			return err
+		})
+		if err != nil {
+			return response.InternalError(c, "Failed to replace parameters")
+		}
 
-				if err := db.Create(&p).Error; err != nil {
-					return err
-				}
+		publishParametersChange(db, api, body.ParamType, before, services.ChangeSourceAPI)
 
-				order++
-				insertedCount++
-
-				// Handle children
-				if children, ok := param["children"].([]interface{}); ok && len(children) > 0 {
-					childParams := make([]map[string]interface{}, 0, len(children))
-					for _, child := range children {
-						if childMap, ok := child.(map[string]interface{}); ok {
-							childParams = append(childParams, childMap)
-						}
-					}
-					if len(childParams) > 0 {
-						if err := insertParams(childParams, &p.ID); err != nil {
-							return err
-						}
-					}
-				}
+		return response.Success(c, fiber.Map{"count": insertedCount})
+	}
+}
+
+// UpdateParametersFromJSON updates parameters from JSON. The json example is inferred as a
+// single sample: fields keep the order of the example, whole numbers are typed integer and
+// null values make a field nullable; required flags and descriptions of existing parameters
+// are preserved. With a samples array instead of json, the tree is inferred from all samples:
+// array items are merged and fields missing from some samples are optional.
+func UpdateParametersFromJSON(db *gorm.DB, cfg *config.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return response.BadRequest(c, "Invalid API ID")
+		}
+
+		var body struct {
+			ParamType string            `json:"paramType"`
+			JSON      json.RawMessage   `json:"json"`
+			Samples   []json.RawMessage `json:"samples"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		if body.ParamType != "request" && body.ParamType != "response" {
+			return response.BadRequest(c, "Invalid paramType. Must be 'request' or 'response'")
+		}
+
+		example, err := decodeParameterExample(body.JSON, body.Samples)
+		if err != nil {
+			return response.BadRequest(c, err.Error())
+		}
+
+		var api models.API
+		if err := db.First(&api, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return response.NotFound(c, "API not found")
 			}
-			return nil
+			return response.InternalError(c, "Failed to fetch API")
 		}
 
-		if err := insertParams(params, nil); err != nil {
-			return response.InternalError(c, "Failed to insert parameters")
+		if cfg.RequireApproval {
+			return collectIntoDraft(c, db, api, func(draft *models.APIDraft) error {
+				return example.applyToDraft(draft, body.ParamType)
+			})
 		}
 
-		return response.Success(c, fiber.Map{"count": insertedCount})
+		before, err := loadParameterTree(db, api.ID, body.ParamType)
+		if err != nil {
+			return response.InternalError(c, "Failed to fetch existing parameters")
+		}
+
+		nodes := example.nodes(before)
+		services.MatchParameterIDs(nodes, before)
+
+		if issues := services.ValidateParameterNodes(nodes); len(issues) > 0 {
+			return response.ValidationFailed(c, "Invalid parameter tree", issues)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			_, err := services.SyncParameterTree(tx, api.ID, body.ParamType, nodes)
+			return err
+		})
+		if err != nil {
+			return response.InternalError(c, "Failed to convert JSON to parameters")
+		}
+
+		publishParametersChange(db, api, body.ParamType, before, services.ChangeSourceAPI)
+
+		result := fiber.Map{"parameterCount": len(nodes)}
+		if !example.single {
+			result["sampleCount"] = len(body.Samples)
+		}
+		return response.Success(c, result)
 	}
 }
 
-// UpdateParametersFromJSON updates parameters from JSON
-func UpdateParametersFromJSON(db *gorm.DB) fiber.Handler {
+// UpdateParametersFromSchema replaces the request or response parameter tree of an API with
+// the tree converted from a JSON Schema document. Parameters at the same path keep their IDs.
+// The tree is stored in a single transaction.
+func UpdateParametersFromSchema(db *gorm.DB, cfg *config.Config) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
 		if err != nil {
@@ -349,8 +545,8 @@ func UpdateParametersFromJSON(db *gorm.DB) fiber.Handler {
 		}
 
 		var body struct {
-			ParamType string                 `json:"paramType"`
-			JSON      map[string]interface{} `json:"json"`
+			ParamType string          `json:"paramType"`
+			Schema    json.RawMessage `json:"schema"`
 		}
 
 		if err := c.BodyParser(&body); err != nil {
@@ -361,88 +557,98 @@ func UpdateParametersFromJSON(db *gorm.DB) fiber.Handler {
 			return response.BadRequest(c, "Invalid paramType. Must be 'request' or 'response'")
 		}
 
-		if body.JSON == nil {
-			return response.BadRequest(c, "Invalid json object")
-		}
-
-		// Get existing parameters to preserve required status and descriptions
-		var existingParams []models.Parameter
-		db.Where("api_id = ? AND param_type = ?", id, body.ParamType).Find(&existingParams)
-
-		// Build a map of existing parameters by name
-		existingMap := make(map[string]*models.Parameter)
-		for i := range existingParams {
-			existingMap[existingParams[i].Name] = &existingParams[i]
-		}
-
-		// Delete existing parameters
-		db.Where("api_id = ? AND param_type = ?", id, body.ParamType).Delete(&models.Parameter{})
-
-		// Convert JSON to parameters
-		orderCounter := 0
-		var convertJSON func(obj map[string]interface{}, parentID *uint) error
-		convertJSON = func(obj map[string]interface{}, parentID *uint) error {
-			for key, value := range obj {
-				existing := existingMap[key]
-
-				var paramType string
-				var children map[string]interface{}
-
-				switch v := value.(type) {
-				case []interface{}:
-					paramType = "array"
-					if len(v) > 0 {
-						if obj, ok := v[0].(map[string]interface{}); ok {
-							children = obj
-						}
-					}
-				case map[string]interface{}:
-					paramType = "object"
-					children = v
-				case string:
-					paramType = "string"
-				case float64:
-					paramType = "number"
-				case bool:
-					paramType = "boolean"
-				default:
-					paramType = "string"
-				}
-
-				param := models.Parameter{
-					APIID:     uint(id),
-					ParentID:  parentID,
-					Name:      key,
-					Type:      paramType,
-					ParamType: body.ParamType,
-					Order:     orderCounter,
-					Required:  false,
-				}
+		nodes, err := services.ParameterNodesFromJSONSchema(body.Schema)
+		if err != nil {
+			return response.BadRequest(c, "Invalid schema: "+err.Error())
+		}
 
-				if existing != nil {
-					param.Required = existing.Required
-					param.Description = existing.Description
-				}
+		if issues := services.ValidateParameterNodes(nodes); len(issues) > 0 {
+			return response.ValidationFailed(c, "Invalid parameter tree", issues)
+		}
 
-				if err := db.Create(&param).Error; err != nil {
-					return err
-				}
+		var api models.API
+		if err := db.First(&api, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return response.NotFound(c, "API not found")
+			}
+			return response.InternalError(c, "Failed to fetch API")
+		}
 
-				orderCounter++
+		if cfg.RequireApproval {
+			return collectIntoDraft(c, db, api, func(draft *models.APIDraft) error {
+				return services.SetDraftParameterNodes(draft, body.ParamType, nodes)
+			})
+		}
 
-				if children != nil {
-					if err := convertJSON(children, &param.ID); err != nil {
-						return err
-					}
-				}
-			}
-			return nil
+		before, err := loadParameterTree(db, api.ID, body.ParamType)
+		if err != nil {
+			return response.InternalError(c, "Failed to fetch existing parameters")
 		}
 
-		if err := convertJSON(body.JSON, nil); err != nil {
-			return response.InternalError(c, "Failed to convert JSON to parameters")
+		services.MatchParameterIDs(nodes, before)
+
+		var insertedCount int
+		err = db.Transaction(func(tx *gorm.DB) error {
+			insertedCount, err = services.SyncParameterTree(tx, api.ID, body.ParamType, nodes)
+			return err
+		})
+		if err != nil {
+			return response.InternalError(c, "Failed to replace parameters")
 		}
 
-		return response.Success(c, fiber.Map{"parameterCount": len(body.JSON)})
+		publishParametersChange(db, api, body.ParamType, before, services.ChangeSourceAPI)
+
+		return response.Success(c, fiber.Map{"parameterCount": insertedCount})
+	}
+}
+
+// loadParameterTree loads the request or response parameter tree of an API
+func loadParameterTree(db *gorm.DB, apiID uint, paramType string) ([]models.Parameter, error) {
+	var params []models.Parameter
+	if err := db.Where("api_id = ? AND param_type = ?", apiID, paramType).Order("`order` ASC").Find(&params).Error; err != nil {
+		return nil, err
+	}
+	return services.BuildParameterTree(params), nil
+}
+
+// publishAPIChange notifies subscribers that the basic info of an API changed from source.
+// It returns the published diff.
+func publishAPIChange(db *gorm.DB, api models.API, before map[string]interface{}, source string) []services.FieldChange {
+	diff := services.DiffValues(before, services.APISnapshot(api))
+	services.PublishChange(db, services.ChangeEvent{
+		Type:    services.EventAPIUpdated,
+		GroupID: api.GroupID,
+		APIID:   api.ID,
+		Entity:  api,
+		Diff:    diff,
+		Source:  source,
+	})
+	return diff
+}
+
+// publishParametersChange notifies subscribers that a parameter tree of an API was replaced
+// from source. It returns the published diff.
+func publishParametersChange(db *gorm.DB, api models.API, paramType string, before []models.Parameter, source string) []services.FieldChange {
+	after, err := loadParameterTree(db, api.ID, paramType)
+	if err != nil {
+		return []services.FieldChange{}
 	}
+
+	diff := services.DiffValues(
+		map[string]interface{}{paramType: services.ParameterSnapshot(before)},
+		map[string]interface{}{paramType: services.ParameterSnapshot(after)},
+	)
+	services.PublishChange(db, services.ChangeEvent{
+		Type:    services.EventParametersUpdated,
+		GroupID: api.GroupID,
+		APIID:   api.ID,
+		Entity: fiber.Map{
+			"api":        api,
+			"paramType":  paramType,
+			"parameters": after,
+		},
+		Diff:   diff,
+		Source: source,
+	})
+	return diff
 }
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// asyncAPIImportBody is the body of the AsyncAPI import endpoints
+type asyncAPIImportBody struct {
+	Document json.RawMessage `json:"document"` // The document as an object, or its YAML or JSON text as a string
+	Keys     []string        `json:"keys"`     // Candidates to import, e.g. "PUBLISH orders.created"; all if empty
+}
+
+// ExportGroupAsyncAPI downloads an AsyncAPI document of the EVENT APIs of a group.
+// Query: version (2.6.0 or 3.0.0, the default) and format (yaml, the default, or json).
+func ExportGroupAsyncAPI(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		version, err := services.ResolveAsyncAPIVersion(c.Query("version"))
+		if err != nil {
+			return response.BadRequest(c, "Invalid version. Must be one of: 2.6.0, 3.0.0")
+		}
+		format := c.Query("format", "yaml")
+		if format != "yaml" && format != "json" {
+			return response.BadRequest(c, "Invalid format. Must be 'yaml' or 'json'")
+		}
+
+		var group models.Group
+		if err := db.First(&group, c.Params("id")).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return response.NotFound(c, "Group not found")
+			}
+			return response.InternalError(c, "Failed to fetch group")
+		}
+
+		apiIDs := []uint{}
+		if err := db.Model(&models.API{}).Where("group_id = ? AND type = ?", group.ID, models.APITypeEvent).Pluck("id", &apiIDs).Error; err != nil {
+			return response.InternalError(c, "Failed to fetch APIs")
+		}
+
+		apis, err := services.LoadExportAPIs(db, apiIDs, "")
+		if err != nil {
+			return response.InternalError(c, "Failed to load APIs for export")
+		}
+
+		document, err := services.GenerateAsyncAPI(apis, group.Name, version)
+		if err != nil {
+			return response.InternalError(c, "Failed to generate AsyncAPI document")
+		}
+
+		var content []byte
+		if format == "json" {
+			content, err = json.MarshalIndent(document, "", "  ")
+			c.Set("Content-Type", "application/json")
+		} else {
+			content, err = services.EncodeOrderedYAML(document)
+			c.Set("Content-Type", "application/yaml")
+		}
+		if err != nil {
+			return response.InternalError(c, "Failed to generate AsyncAPI document")
+		}
+
+		c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.asyncapi.%s\"", services.Slugify(group.Name), format))
+		return c.Send(content)
+	}
+}
+
+// PreviewAsyncAPIImport lists the EVENT APIs an AsyncAPI document would create in a group
+func PreviewAsyncAPIImport(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body asyncAPIImportBody
+		if err := c.BodyParser(&body); err != nil || len(body.Document) == 0 {
+			return response.BadRequest(c, "Invalid request body. A document is required")
+		}
+
+		group, candidates, err := loadAsyncAPICandidates(db, c.Params("id"), body)
+		if err != nil {
+			return asyncAPIImportError(c, err)
+		}
+
+		return response.Success(c, fiber.Map{
+			"groupId":    group.ID,
+			"candidates": candidates,
+		})
+	}
+}
+
+// ImportAsyncAPI creates the EVENT APIs of an AsyncAPI document in a group. Only the candidates
+// listed in keys are created, or all of them; operations matching existing APIs are skipped.
+func ImportAsyncAPI(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body asyncAPIImportBody
+		if err := c.BodyParser(&body); err != nil || len(body.Document) == 0 {
+			return response.BadRequest(c, "Invalid request body. A document is required")
+		}
+
+		group, candidates, err := loadAsyncAPICandidates(db, c.Params("id"), body)
+		if err != nil {
+			return asyncAPIImportError(c, err)
+		}
+
+		created, err := services.ImportAsyncAPICandidates(db, group, candidates, body.Keys)
+		if err != nil {
+			return response.InternalError(c, "Failed to import APIs")
+		}
+
+		for _, api := range created {
+			services.PublishChange(db, services.ChangeEvent{
+				Type:    services.EventAPICreated,
+				GroupID: api.GroupID,
+				APIID:   api.ID,
+				Entity:  api,
+				Diff:    services.DiffValues(nil, services.APISnapshot(api)),
+			})
+		}
+
+		return response.Success(c, fiber.Map{
+			"count": len(created),
+			"apis":  created,
+		})
+	}
+}
+
+// loadAsyncAPICandidates previews the import of an AsyncAPI document into a group given by ID
+func loadAsyncAPICandidates(db *gorm.DB, groupID string, body asyncAPIImportBody) (models.Group, []services.AsyncAPIImportCandidate, error) {
+	var group models.Group
+	if err := db.First(&group, groupID).Error; err != nil {
+		return group, nil, err
+	}
+
+	document := []byte(body.Document)
+	var text string
+	if err := json.Unmarshal(body.Document, &text); err == nil {
+		document = []byte(text)
+	}
+
+	candidates, err := services.PreviewAsyncAPIImport(db, group, document)
+	return group, candidates, err
+}
+
+// asyncAPIImportError maps an error of loadAsyncAPICandidates to a response
+func asyncAPIImportError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, "Group not found")
+	case errors.Is(err, services.ErrInvalidAsyncAPI):
+		return response.BadRequest(c, err.Error())
+	}
+	return response.InternalError(c, "Failed to read AsyncAPI document")
+}
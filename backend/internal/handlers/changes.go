@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetChanges returns the most recent entries of the change history.
+// Use ?groupId, ?apiId and ?source (api, mcp or cli) to narrow the list.
+func GetChanges(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		filter := services.ChangeHistoryFilter{
+			Source: c.Query("source"),
+			Limit:  c.QueryInt("limit", 50),
+		}
+		if filter.Limit <= 0 || filter.Limit > 500 {
+			filter.Limit = 50
+		}
+
+		if raw := c.Query("groupId"); raw != "" {
+			groupID, err := strconv.ParseUint(raw, 10, 32)
+			if err != nil {
+				return response.BadRequest(c, "Invalid group ID")
+			}
+			filter.GroupID = uint(groupID)
+		}
+		if raw := c.Query("apiId"); raw != "" {
+			apiID, err := strconv.ParseUint(raw, 10, 32)
+			if err != nil {
+				return response.BadRequest(c, "Invalid API ID")
+			}
+			filter.APIID = uint(apiID)
+		}
+
+		records, err := services.LoadChangeHistory(db, filter)
+		if err != nil {
+			return response.InternalError(c, "Failed to fetch change history")
+		}
+
+		return response.Success(c, records)
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GenerateAPIModels generates request and response types of an API.
+// Query: lang (typescript, go, java, kotlin, python or pydantic), paramType and package.
+func GenerateAPIModels(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		api, err := findAPI(db, c.Params("id"))
+		if err != nil {
+			return apiLookupError(c, err)
+		}
+
+		lang := c.Query("lang", "typescript")
+		paramType := c.Query("paramType")
+		if paramType != "" && paramType != "request" && paramType != "response" {
+			return response.BadRequest(c, "Invalid paramType. Must be 'request' or 'response'")
+		}
+
+		apis, err := services.LoadExportAPIs(db, []uint{api.ID}, "")
+		if err != nil {
+			return response.InternalError(c, "Failed to fetch parameters")
+		}
+
+		code, err := services.GenerateModels(apis, lang, services.CodegenOptions{
+			ParamType: paramType,
+			Package:   c.Query("package"),
+		})
+		if err == services.ErrUnsupportedLanguage {
+			return response.BadRequest(c, "Invalid lang. Must be one of: "+strings.Join(services.CodegenLanguages, ", "))
+		}
+
+		return response.Success(c, fiber.Map{
+			"apiId":    api.ID,
+			"language": lang,
+			"code":     code,
+		})
+	}
+}
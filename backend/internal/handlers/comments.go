@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetComments returns the comment threads of an API.
+// Query parameters: status (open, resolved or all, default open) and parameterId.
+func GetComments(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return response.BadRequest(c, "Invalid API ID")
+		}
+
+		status := c.Query("status", "open")
+		if status != "open" && status != "resolved" && status != "all" {
+			return response.BadRequest(c, "Invalid status. Must be 'open', 'resolved' or 'all'")
+		}
+
+		var parameterID *uint
+		if raw := c.Query("parameterId"); raw != "" {
+			parsed, err := strconv.ParseUint(raw, 10, 32)
+			if err != nil {
+				return response.BadRequest(c, "Invalid parameter ID")
+			}
+			value := uint(parsed)
+			parameterID = &value
+		}
+
+		threads, err := services.LoadCommentThreads(db, uint(id), parameterID, status != "open")
+		if err != nil {
+			return response.InternalError(c, "Failed to fetch comments")
+		}
+
+		if status == "resolved" {
+			resolved := make([]models.Comment, 0, len(threads))
+			for _, thread := range threads {
+				if thread.Resolved {
+					resolved = append(resolved, thread)
+				}
+			}
+			threads = resolved
+		}
+
+		return response.Success(c, threads)
+	}
+}
+
+// CreateComment adds a comment to an API, one of its parameters, or replies to a thread
+func CreateComment(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return response.BadRequest(c, "Invalid API ID")
+		}
+
+		var body struct {
+			Author      string   `json:"author"`
+			Body        string   `json:"body"`
+			ParameterID *uint    `json:"parameterId"`
+			ParentID    *uint    `json:"parentId"`
+			Mentions    []string `json:"mentions"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		body.Author = strings.TrimSpace(body.Author)
+		if body.Author == "" || strings.TrimSpace(body.Body) == "" {
+			return response.BadRequest(c, "Author and body are required")
+		}
+
+		var api models.API
+		if err := db.First(&api, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return response.NotFound(c, "API not found")
+			}
+			return response.InternalError(c, "Failed to fetch API")
+		}
+
+		comment := models.Comment{
+			APIID:       api.ID,
+			ParameterID: body.ParameterID,
+			Author:      body.Author,
+			Body:        body.Body,
+		}
+
+		if body.ParentID != nil {
+			var parent models.Comment
+			if err := db.Where("id = ? AND api_id = ?", *body.ParentID, api.ID).First(&parent).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return response.NotFound(c, "Parent comment not found")
+				}
+				return response.InternalError(c, "Failed to fetch parent comment")
+			}
+
+			// Replies always attach to the thread root and share its target
+			if parent.ParentID != nil {
+				comment.ParentID = parent.ParentID
+			} else {
+				comment.ParentID = &parent.ID
+			}
+			comment.ParameterID = parent.ParameterID
+		} else if comment.ParameterID != nil {
+			var count int64
+			if err := db.Model(&models.Parameter{}).Where("id = ? AND api_id = ?", *comment.ParameterID, api.ID).Count(&count).Error; err != nil {
+				return response.InternalError(c, "Failed to fetch parameter")
+			}
+			if count == 0 {
+				return response.NotFound(c, "Parameter not found")
+			}
+		}
+
+		mentions := services.ParseMentions(body.Body)
+		for _, mention := range body.Mentions {
+			mention = strings.TrimPrefix(strings.TrimSpace(mention), "@")
+			if mention != "" && !containsString(mentions, mention) {
+				mentions = append(mentions, mention)
+			}
+		}
+		comment.Mentions = strings.Join(mentions, ",")
+
+		if err := db.Create(&comment).Error; err != nil {
+			return response.InternalError(c, "Failed to create comment")
+		}
+
+		return response.Success(c, comment)
+	}
+}
+
+// ResolveComment resolves or reopens a comment thread
+func ResolveComment(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return response.BadRequest(c, "Invalid comment ID")
+		}
+
+		var body struct {
+			Resolved *bool  `json:"resolved"`
+			Author   string `json:"author"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		resolved := body.Resolved == nil || *body.Resolved
+
+		var comment models.Comment
+		if err := db.First(&comment, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return response.NotFound(c, "Comment not found")
+			}
+			return response.InternalError(c, "Failed to fetch comment")
+		}
+
+		// Resolution state lives on the thread root
+		if comment.ParentID != nil {
+			var root models.Comment
+			if err := db.First(&root, *comment.ParentID).Error; err != nil {
+				return response.InternalError(c, "Failed to fetch comment thread")
+			}
+			comment = root
+		}
+
+		comment.Resolved = resolved
+		if resolved {
+			now := time.Now().Unix()
+			comment.ResolvedAt = &now
+			if author := strings.TrimSpace(body.Author); author != "" {
+				comment.ResolvedBy = &author
+			} else {
+				comment.ResolvedBy = nil
+			}
+		} else {
+			comment.ResolvedAt = nil
+			comment.ResolvedBy = nil
+		}
+
+		if err := db.Save(&comment).Error; err != nil {
+			return response.InternalError(c, "Failed to update comment")
+		}
+
+		if err := db.Preload("Replies", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).First(&comment, comment.ID).Error; err != nil {
+			return response.InternalError(c, "Failed to fetch comment thread")
+		}
+
+		return response.Success(c, comment)
+	}
+}
+
+// containsString reports whether a slice contains the given value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,467 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// EditorHeader identifies the user behind an edit that is collected into a draft
+const EditorHeader = "X-Knot-User"
+
+// errInvalidID is returned when a route ID parameter is not a number
+var errInvalidID = errors.New("invalid ID")
+
+// GetDrafts returns drafts across all APIs, e.g. the review queue with ?status=open.
+// Use ?groupId to list the drafts of one group, including proposed new APIs.
+func GetDrafts(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		query := db.Order("id DESC")
+		if status := c.Query("status"); status != "" {
+			query = query.Where("status = ?", status)
+		}
+		if raw := c.Query("groupId"); raw != "" {
+			groupID, err := strconv.ParseUint(raw, 10, 32)
+			if err != nil {
+				return response.BadRequest(c, "Invalid group ID")
+			}
+			query = query.Where("group_id = ?", groupID)
+		}
+
+		var drafts []models.APIDraft
+		if err := query.Find(&drafts).Error; err != nil {
+			return response.InternalError(c, "Failed to fetch drafts")
+		}
+
+		if drafts == nil {
+			drafts = []models.APIDraft{}
+		}
+
+		return response.Success(c, drafts)
+	}
+}
+
+// GetAPIDrafts returns the drafts of a single API
+func GetAPIDrafts(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return response.BadRequest(c, "Invalid API ID")
+		}
+
+		query := db.Where("api_id = ?", id).Order("id DESC")
+		if status := c.Query("status"); status != "" {
+			query = query.Where("status = ?", status)
+		}
+
+		var drafts []models.APIDraft
+		if err := query.Find(&drafts).Error; err != nil {
+			return response.InternalError(c, "Failed to fetch drafts")
+		}
+
+		if drafts == nil {
+			drafts = []models.APIDraft{}
+		}
+
+		return response.Success(c, drafts)
+	}
+}
+
+// CreateDraft opens a draft for an API, seeded from the published version.
+// If the API already has an open draft, that draft is returned.
+func CreateDraft(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return response.BadRequest(c, "Invalid API ID")
+		}
+
+		var body struct {
+			Author  string  `json:"author"`
+			Summary *string `json:"summary"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		var api models.API
+		if err := db.First(&api, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return response.NotFound(c, "API not found")
+			}
+			return response.InternalError(c, "Failed to fetch API")
+		}
+
+		draft, err := services.OpenDraft(db, api, body.Author)
+		if err != nil {
+			return response.InternalError(c, "Failed to create draft")
+		}
+
+		if body.Summary != nil {
+			draft.Summary = body.Summary
+			if err := db.Save(&draft).Error; err != nil {
+				return response.InternalError(c, "Failed to update draft")
+			}
+		}
+
+		return response.Success(c, draft)
+	}
+}
+
+// GetDraft returns a draft together with the published version and a diff
+func GetDraft(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		draft, err := findDraft(db, c.Params("id"))
+		if err != nil {
+			return draftLookupError(c, err)
+		}
+
+		preview, err := services.PreviewDraft(db, draft)
+		if err != nil {
+			return response.InternalError(c, "Failed to build draft preview")
+		}
+
+		return response.Success(c, preview)
+	}
+}
+
+// UpdateDraft updates the proposed basic info of an open draft
+func UpdateDraft(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body struct {
+			Summary  *string `json:"summary"`
+			Name     *string `json:"name"`
+			Endpoint *string `json:"endpoint"`
+			Method   *string `json:"method"`
+			Type     *string `json:"type"`
+			Protocol *string `json:"protocol"`
+			Note     *string `json:"note"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		draft, err := findDraft(db, c.Params("id"))
+		if err != nil {
+			return draftLookupError(c, err)
+		}
+
+		if draft.Status != models.DraftStatusOpen {
+			return response.Error(c, fiber.StatusConflict, "Draft is not open")
+		}
+
+		if body.Summary != nil {
+			draft.Summary = body.Summary
+		}
+		if body.Name != nil {
+			draft.Name = *body.Name
+		}
+		if body.Endpoint != nil {
+			draft.Endpoint = *body.Endpoint
+		}
+		if body.Method != nil {
+			draft.Method = *body.Method
+		}
+		if body.Type != nil {
+			draft.Type = *body.Type
+		}
+		if body.Protocol != nil {
+			draft.Protocol = body.Protocol
+		}
+		if body.Note != nil {
+			draft.Note = body.Note
+		}
+
+		method, err := services.NormalizeAPIMethod(draft.Type, draft.Method)
+		if err != nil {
+			return response.BadRequest(c, err.Error())
+		}
+		draft.Method = method
+
+		if err := db.Save(&draft).Error; err != nil {
+			return response.InternalError(c, "Failed to update draft")
+		}
+
+		return response.Success(c, draft)
+	}
+}
+
+// UpdateDraftParameters replaces the proposed request or response tree of an open draft
+func UpdateDraftParameters(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body struct {
+			ParamType  string          `json:"paramType"`
+			Parameters json.RawMessage `json:"parameters"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		if body.ParamType != "request" && body.ParamType != "response" {
+			return response.BadRequest(c, "Invalid paramType")
+		}
+
+		var nodes []services.ParameterNode
+		if err := json.Unmarshal(body.Parameters, &nodes); err != nil {
+			return response.BadRequest(c, "Invalid parameters format")
+		}
+
+		if issues := services.ValidateParameterNodes(nodes); len(issues) > 0 {
+			return response.ValidationFailed(c, "Invalid parameter tree", issues)
+		}
+
+		draft, err := findDraft(db, c.Params("id"))
+		if err != nil {
+			return draftLookupError(c, err)
+		}
+
+		if draft.Status != models.DraftStatusOpen {
+			return response.Error(c, fiber.StatusConflict, "Draft is not open")
+		}
+
+		if err := services.SetDraftParameterNodes(&draft, body.ParamType, nodes); err != nil {
+			return response.InternalError(c, "Failed to encode parameters")
+		}
+
+		if err := db.Save(&draft).Error; err != nil {
+			return response.InternalError(c, "Failed to update draft")
+		}
+
+		return response.Success(c, draft)
+	}
+}
+
+// UpdateDraftParametersFromJSON replaces the proposed tree of an open draft from an example JSON
+// or, with a samples array, from the tree inferred from all samples
+func UpdateDraftParametersFromJSON(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body struct {
+			ParamType string            `json:"paramType"`
+			JSON      json.RawMessage   `json:"json"`
+			Samples   []json.RawMessage `json:"samples"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		if body.ParamType != "request" && body.ParamType != "response" {
+			return response.BadRequest(c, "Invalid paramType. Must be 'request' or 'response'")
+		}
+
+		example, err := decodeParameterExample(body.JSON, body.Samples)
+		if err != nil {
+			return response.BadRequest(c, err.Error())
+		}
+
+		draft, err := findDraft(db, c.Params("id"))
+		if err != nil {
+			return draftLookupError(c, err)
+		}
+
+		if draft.Status != models.DraftStatusOpen {
+			return response.Error(c, fiber.StatusConflict, "Draft is not open")
+		}
+
+		if err := example.applyToDraft(&draft, body.ParamType); err != nil {
+			return response.InternalError(c, "Failed to convert JSON to parameters")
+		}
+
+		if err := db.Save(&draft).Error; err != nil {
+			return response.InternalError(c, "Failed to update draft")
+		}
+
+		return response.Success(c, draft)
+	}
+}
+
+// ApproveDraft approves an open draft and merges it into the published API
+func ApproveDraft(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return reviewDraft(c, db, services.ApproveDraft)
+	}
+}
+
+// RejectDraft rejects an open draft
+func RejectDraft(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return reviewDraft(c, db, services.RejectDraft)
+	}
+}
+
+// RebaseDraft bases an open draft on the current published version of its API
+func RebaseDraft(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		draft, err := findDraft(db, c.Params("id"))
+		if err != nil {
+			return draftLookupError(c, err)
+		}
+
+		draft, err = services.RebaseDraft(db, draft)
+		if err != nil {
+			if err == services.ErrDraftNotOpen {
+				return response.Error(c, fiber.StatusConflict, "Draft is not open")
+			}
+			return response.InternalError(c, "Failed to rebase draft")
+		}
+
+		return response.Success(c, draft)
+	}
+}
+
+// reviewDraft applies a review decision to a draft
+func reviewDraft(c *fiber.Ctx, db *gorm.DB, decide func(*gorm.DB, models.APIDraft, string, *string) (models.APIDraft, error)) error {
+	var body struct {
+		Reviewer string  `json:"reviewer"`
+		Comment  *string `json:"comment"`
+	}
+
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	draft, err := findDraft(db, c.Params("id"))
+	if err != nil {
+		return draftLookupError(c, err)
+	}
+
+	draft, err = decide(db, draft, body.Reviewer, body.Comment)
+	if err != nil {
+		if err == services.ErrDraftNotOpen {
+			return response.Error(c, fiber.StatusConflict, "Draft is not open")
+		}
+		if err == services.ErrDraftWithoutTarget {
+			return response.BadRequest(c, "Draft has no API or group")
+		}
+		if err == services.ErrDraftConflict {
+			return response.Error(c, fiber.StatusConflict, "API was changed after the draft was opened; review the draft and rebase it")
+		}
+		return response.InternalError(c, "Failed to review draft")
+	}
+
+	return response.Success(c, draft)
+}
+
+// findDraft loads a draft by its ID route parameter
+func findDraft(db *gorm.DB, rawID string) (models.APIDraft, error) {
+	var draft models.APIDraft
+
+	id, err := strconv.ParseUint(rawID, 10, 32)
+	if err != nil {
+		return draft, errInvalidID
+	}
+
+	err = db.First(&draft, id).Error
+	return draft, err
+}
+
+// draftLookupError maps a findDraft error to a response
+func draftLookupError(c *fiber.Ctx, err error) error {
+	switch err {
+	case errInvalidID:
+		return response.BadRequest(c, "Invalid draft ID")
+	case gorm.ErrRecordNotFound:
+		return response.NotFound(c, "Draft not found")
+	default:
+		return response.InternalError(c, "Failed to fetch draft")
+	}
+}
+
+// inferSampleNodes infers a parameter tree from the raw samples of a from-json request
+func inferSampleNodes(samples []json.RawMessage) ([]services.ParameterNode, error) {
+	raw := make([][]byte, len(samples))
+	for i, sample := range samples {
+		raw[i] = sample
+	}
+	return services.InferParameterNodesFromJSON(raw...)
+}
+
+// parameterExample is the tree a from-json request infers from its samples, or from its
+// example object as a single sample, so both give the same types
+type parameterExample struct {
+	inferred []services.ParameterNode
+	single   bool // inferred from the json example; required flags of existing parameters are kept
+}
+
+// decodeParameterExample infers a tree from the samples of a from-json request or, without
+// samples, from its example object. Key order is kept.
+func decodeParameterExample(raw json.RawMessage, samples []json.RawMessage) (parameterExample, error) {
+	if len(samples) > 0 {
+		inferred, err := inferSampleNodes(samples)
+		if err != nil {
+			return parameterExample{}, err
+		}
+		return parameterExample{inferred: inferred}, nil
+	}
+
+	if len(raw) == 0 {
+		return parameterExample{}, errors.New("Invalid json object")
+	}
+	inferred, err := services.InferParameterNodesFromJSON(raw)
+	if err != nil {
+		return parameterExample{}, errors.New("Invalid json object")
+	}
+	return parameterExample{inferred: inferred, single: true}, nil
+}
+
+// carry keeps the documentation of the parameters of a tree that is being replaced
+func (e parameterExample) carry(documented []services.ParameterNode) []services.ParameterNode {
+	services.CarryDocumentation(e.inferred, documented)
+	if e.single {
+		services.CarryRequiredFlags(e.inferred, documented)
+	}
+	return e.inferred
+}
+
+// nodes derives the tree replacing a published tree, keeping the documentation of its parameters
+func (e parameterExample) nodes(before []models.Parameter) []services.ParameterNode {
+	return e.carry(services.NodesFromParameters(before))
+}
+
+// applyToDraft replaces a proposed tree of a draft with the derived tree,
+// keeping the documentation of the proposed parameters
+func (e parameterExample) applyToDraft(draft *models.APIDraft, paramType string) error {
+	current, err := services.DraftParameterNodes(*draft, paramType)
+	if err != nil {
+		return err
+	}
+	return services.SetDraftParameterNodes(draft, paramType, e.carry(current))
+}
+
+// collectIntoDraft applies an edit of a published API to its open draft instead.
+// It responds with 202 Accepted and the draft awaiting approval.
+func collectIntoDraft(c *fiber.Ctx, db *gorm.DB, api models.API, apply func(draft *models.APIDraft) error) error {
+	draft, err := services.OpenDraft(db, api, c.Get(EditorHeader))
+	if err != nil {
+		return response.InternalError(c, "Failed to open draft")
+	}
+
+	if err := apply(&draft); err != nil {
+		if editErr, ok := err.(*services.ParameterEditError); ok {
+			return response.ValidationFailed(c, "Invalid parameter operations", editErr.Issues)
+		}
+		if errors.Is(err, services.ErrInvalidEventDirection) || errors.Is(err, services.ErrInvalidGraphQLOperation) {
+			return response.BadRequest(c, err.Error())
+		}
+		return response.InternalError(c, "Failed to update draft")
+	}
+
+	if err := db.Save(&draft).Error; err != nil {
+		return response.InternalError(c, "Failed to update draft")
+	}
+
+	c.Status(fiber.StatusAccepted)
+	return response.Success(c, fiber.Map{
+		"pendingApproval": true,
+		"draft":           draft,
+	})
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+)
+
+func TestDecodeParameterExampleMatchesSamples(t *testing.T) {
+	payload := json.RawMessage(`{"id": 7, "price": 9.5, "coupon": null, "tags": ["a"], "owner": {"id": 1}}`)
+
+	single, err := decodeParameterExample(payload, nil)
+	if err != nil {
+		t.Fatalf("decodeParameterExample(json): %v", err)
+	}
+	sampled, err := decodeParameterExample(nil, []json.RawMessage{payload})
+	if err != nil {
+		t.Fatalf("decodeParameterExample(samples): %v", err)
+	}
+
+	got, want := single.nodes(nil), sampled.nodes(nil)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("json and samples disagree\n json: %+v\n samples: %+v", got, want)
+	}
+
+	types := make(map[string]string)
+	for _, node := range got {
+		types[node.Name] = node.Type
+	}
+	if types["id"] != "integer" || types["price"] != "number" || types["coupon"] != "string" {
+		t.Errorf("types = %v, want id integer, price number, coupon string", types)
+	}
+	if !got[2].Nullable {
+		t.Error("coupon should be nullable")
+	}
+}
+
+func TestParameterExampleKeepsDocumentation(t *testing.T) {
+	description := "Order ID"
+	before := []models.Parameter{
+		{ID: 10, Name: "id", Type: "number", Required: false, Description: &description},
+		{ID: 11, Name: "owner", Type: "object", Required: true, Children: []models.Parameter{
+			{ID: 12, Name: "id", Type: "number", Required: false},
+		}},
+	}
+
+	example, err := decodeParameterExample(json.RawMessage(`{"id": 7, "owner": {"id": 1}, "note": "x"}`), nil)
+	if err != nil {
+		t.Fatalf("decodeParameterExample: %v", err)
+	}
+
+	nodes := example.nodes(before)
+	want := []services.ParameterNode{
+		{ID: 10, Name: "id", Type: "integer", Description: &description},
+		{ID: 11, Name: "owner", Type: "object", Required: true, Children: []services.ParameterNode{
+			{ID: 12, Name: "id", Type: "integer"},
+		}},
+		{Name: "note", Type: "string", Required: true},
+	}
+	if !reflect.DeepEqual(nodes, want) {
+		t.Errorf("nodes = %+v\nwant %+v", nodes, want)
+	}
+
+	if _, err := decodeParameterExample(json.RawMessage(`[1, 2]`), nil); err == nil {
+		t.Error("an array example should be rejected")
+	}
+}
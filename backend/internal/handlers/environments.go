@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetEnvironments returns all environments.
+// Query parameters: groupId to list the global environments and those of one group.
+func GetEnvironments(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		query := db.Preload("Variables", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).Order("name ASC, id ASC")
+
+		if raw := c.Query("groupId"); raw != "" {
+			groupID, err := strconv.ParseUint(raw, 10, 32)
+			if err != nil {
+				return response.BadRequest(c, "Invalid group ID")
+			}
+			query = query.Where("group_id IS NULL OR group_id = ?", groupID)
+		}
+
+		var environments []models.Environment
+		if err := query.Find(&environments).Error; err != nil {
+			return response.InternalError(c, "Failed to fetch environments")
+		}
+
+		if environments == nil {
+			environments = []models.Environment{}
+		}
+
+		return response.Success(c, environments)
+	}
+}
+
+// GetEnvironment returns a single environment
+func GetEnvironment(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		environment, err := findEnvironment(db, c.Params("id"))
+		if err != nil {
+			return environmentLookupError(c, err)
+		}
+
+		return response.Success(c, environment)
+	}
+}
+
+// CreateEnvironment creates a global environment or, with a groupId, a group environment
+func CreateEnvironment(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body struct {
+			Name        string                              `json:"name"`
+			GroupID     *uint                               `json:"groupId"`
+			Description *string                             `json:"description"`
+			Variables   []services.EnvironmentVariableInput `json:"variables"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		body.Name = strings.TrimSpace(body.Name)
+		if body.Name == "" {
+			return response.BadRequest(c, "Name is required")
+		}
+
+		environment := models.Environment{
+			Name:        body.Name,
+			GroupID:     normalizeGroupFilter(body.GroupID),
+			Description: body.Description,
+		}
+
+		if status, message := validateEnvironmentScope(db, environment); status != 0 {
+			return response.Error(c, status, message)
+		}
+
+		variables, err := services.BuildEnvironmentVariables(body.Variables, nil)
+		if err != nil {
+			return response.BadRequest(c, err.Error())
+		}
+		environment.Variables = variables
+
+		if err := db.Create(&environment).Error; err != nil {
+			return response.InternalError(c, "Failed to create environment")
+		}
+
+		return response.Success(c, environment)
+	}
+}
+
+// UpdateEnvironment updates an environment.
+// A groupId of 0 makes the environment global. Variables, when given, replace all
+// variables; secrets sent without a value or with the masked value keep their stored value.
+func UpdateEnvironment(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body struct {
+			Name        *string                             `json:"name"`
+			GroupID     *uint                               `json:"groupId"`
+			Description *string                             `json:"description"`
+			Variables   []services.EnvironmentVariableInput `json:"variables"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		environment, err := findEnvironment(db, c.Params("id"))
+		if err != nil {
+			return environmentLookupError(c, err)
+		}
+
+		if body.Name != nil {
+			name := strings.TrimSpace(*body.Name)
+			if name == "" {
+				return response.BadRequest(c, "Name is required")
+			}
+			environment.Name = name
+		}
+		if body.GroupID != nil {
+			environment.GroupID = normalizeGroupFilter(body.GroupID)
+		}
+		if body.Description != nil {
+			environment.Description = body.Description
+		}
+
+		if status, message := validateEnvironmentScope(db, environment); status != 0 {
+			return response.Error(c, status, message)
+		}
+
+		var variables []models.EnvironmentVariable
+		if body.Variables != nil {
+			variables, err = services.BuildEnvironmentVariables(body.Variables, environment.Variables)
+			if err != nil {
+				return response.BadRequest(c, err.Error())
+			}
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Omit("Variables").Save(&environment).Error; err != nil {
+				return err
+			}
+			if body.Variables == nil {
+				return nil
+			}
+
+			if err := tx.Where("environment_id = ?", environment.ID).Delete(&models.EnvironmentVariable{}).Error; err != nil {
+				return err
+			}
+			for i := range variables {
+				variables[i].EnvironmentID = environment.ID
+			}
+			if len(variables) > 0 {
+				if err := tx.Create(&variables).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return response.InternalError(c, "Failed to update environment")
+		}
+
+		environment, err = findEnvironment(db, strconv.FormatUint(uint64(environment.ID), 10))
+		if err != nil {
+			return environmentLookupError(c, err)
+		}
+
+		return response.Success(c, environment)
+	}
+}
+
+// DeleteEnvironment deletes an environment and its variables
+func DeleteEnvironment(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		environment, err := findEnvironment(db, c.Params("id"))
+		if err != nil {
+			return environmentLookupError(c, err)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("environment_id = ?", environment.ID).Delete(&models.EnvironmentVariable{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&models.Environment{}, environment.ID).Error
+		})
+		if err != nil {
+			return response.InternalError(c, "Failed to delete environment")
+		}
+
+		return response.Success(c, nil)
+	}
+}
+
+// findEnvironment loads an environment and its variables by its ID route parameter
+func findEnvironment(db *gorm.DB, rawID string) (models.Environment, error) {
+	var environment models.Environment
+
+	id, err := strconv.ParseUint(rawID, 10, 32)
+	if err != nil {
+		return environment, errInvalidID
+	}
+
+	err = db.Preload("Variables", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).First(&environment, id).Error
+	return environment, err
+}
+
+// environmentLookupError maps an environment lookup error to a response
+func environmentLookupError(c *fiber.Ctx, err error) error {
+	switch err {
+	case errInvalidID:
+		return response.BadRequest(c, "Invalid environment ID")
+	case gorm.ErrRecordNotFound, services.ErrEnvironmentNotFound:
+		return response.NotFound(c, "Environment not found")
+	default:
+		return response.InternalError(c, "Failed to fetch environment")
+	}
+}
+
+// validateEnvironmentScope checks that the group of an environment exists and that no other
+// environment in the same scope has the same name. It returns an error status and message,
+// or 0 if the environment is valid.
+func validateEnvironmentScope(db *gorm.DB, environment models.Environment) (int, string) {
+	query := db.Model(&models.Environment{}).Where("name = ? AND id <> ?", environment.Name, environment.ID)
+	if environment.GroupID == nil {
+		query = query.Where("group_id IS NULL")
+	} else {
+		var groups int64
+		if err := db.Model(&models.Group{}).Where("id = ?", *environment.GroupID).Count(&groups).Error; err != nil {
+			return fiber.StatusInternalServerError, "Failed to fetch group"
+		}
+		if groups == 0 {
+			return fiber.StatusNotFound, "Group not found"
+		}
+		query = query.Where("group_id = ?", *environment.GroupID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return fiber.StatusInternalServerError, "Failed to fetch environments"
+	}
+	if count > 0 {
+		return fiber.StatusConflict, "An environment with this name already exists in this scope"
+	}
+	return 0, ""
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/logger"
+	"github.com/ProjAnvil/knot/backend/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ExecuteAPI sends the documented request of an API to a target server and
+// records it in the API's request history
+func ExecuteAPI(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return response.BadRequest(c, "Invalid API ID")
+		}
+
+		var input services.ExecuteInput
+		if err := c.BodyParser(&input); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		if input.BaseURL == "" && input.Environment == "" {
+			return response.BadRequest(c, "baseUrl or environment is required")
+		}
+
+		var api models.API
+		if err := db.First(&api, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return response.NotFound(c, "API not found")
+			}
+			return response.InternalError(c, "Failed to fetch API")
+		}
+
+		if input.Environment != "" {
+			env, err := services.LoadEnvironment(db, input.Environment, api.GroupID)
+			if err != nil {
+				return environmentLookupError(c, err)
+			}
+			input.Env = env
+		}
+
+		requestTree, err := loadParameterTree(db, api.ID, "request")
+		if err != nil {
+			return response.InternalError(c, "Failed to fetch parameters")
+		}
+
+		result, err := services.ExecuteRequest(api, requestTree, input)
+		if err != nil {
+			if err == services.ErrRequestBodyTooLarge {
+				return response.Error(c, fiber.StatusRequestEntityTooLarge, "Request body exceeds the size limit")
+			}
+			return response.BadRequest(c, err.Error())
+		}
+
+		entry, err := services.RecordRequestHistory(db, api.ID, result)
+		if err != nil {
+			logger.Log.Error("Failed to record request history", zap.Uint("apiId", api.ID), zap.Error(err))
+		} else {
+			result.HistoryID = entry.ID
+		}
+
+		return response.Success(c, result)
+	}
+}
+
+// GetRequestHistory returns the most recent executed requests of an API
+func GetRequestHistory(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return response.BadRequest(c, "Invalid API ID")
+		}
+
+		limit := c.QueryInt("limit", 50)
+		if limit <= 0 || limit > 500 {
+			limit = 50
+		}
+
+		var history []models.RequestHistory
+		if err := db.Where("api_id = ?", id).Order("id DESC").Limit(limit).Find(&history).Error; err != nil {
+			return response.InternalError(c, "Failed to fetch request history")
+		}
+
+		if history == nil {
+			history = []models.RequestHistory{}
+		}
+
+		return response.Success(c, history)
+	}
+}
+
+// ClearRequestHistory deletes the request history of an API
+func ClearRequestHistory(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return response.BadRequest(c, "Invalid API ID")
+		}
+
+		if err := db.Where("api_id = ?", id).Delete(&models.RequestHistory{}).Error; err != nil {
+			return response.InternalError(c, "Failed to clear request history")
+		}
+
+		return response.Success(c, nil)
+	}
+}
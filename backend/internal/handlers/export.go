@@ -1,18 +1,30 @@
 package handlers
 
 import (
-	"github.com/ProjAnvil/knot/backend/internal/models"
+	"bytes"
+	"fmt"
+	"mime"
+
 	"github.com/ProjAnvil/knot/backend/internal/services"
 	"github.com/ProjAnvil/knot/backend/pkg/response"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
-// ExportAPIs exports selected APIs to HTML
+// ExportAPIs exports selected APIs to HTML, Markdown or a zipped static site.
+// With an environment, endpoints are shown as full URLs with secrets masked.
+// Markdown is a single document, or one document per group with layout "group"; zip
+// delivers the documents as a ZIP archive (always the case for the group layout).
+// With a template, the named export template renders the document instead.
 func ExportAPIs(db *gorm.DB) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		var body struct {
-			APIIDs []uint `json:"apiIds"`
+			APIIDs      []uint `json:"apiIds"`
+			Environment string `json:"environment"`
+			Format      string `json:"format"` // html (default), markdown or site
+			Layout      string `json:"layout"` // single (default) or group, Markdown only
+			Zip         bool   `json:"zip"`
+			Template    string `json:"template"`
 		}
 
 		if err := c.BodyParser(&body); err != nil {
@@ -23,68 +35,80 @@ func ExportAPIs(db *gorm.DB) fiber.Handler {
 			return response.BadRequest(c, "No API IDs provided")
 		}
 
-		// Fetch APIs with their parameters and group information
-		var apis []models.API
-		if err := db.Where("id IN ?", body.APIIDs).Find(&apis).Error; err != nil {
-			return response.InternalError(c, "Failed to fetch APIs")
+		if body.Format == "" {
+			body.Format = "html"
 		}
-
-		// Fetch all groups that these APIs belong to
-		groupIDs := make([]uint, 0)
-		groupIDMap := make(map[uint]bool)
-		for _, api := range apis {
-			if !groupIDMap[api.GroupID] {
-				groupIDs = append(groupIDs, api.GroupID)
-				groupIDMap[api.GroupID] = true
-			}
+		if body.Format != "html" && body.Format != "markdown" && body.Format != "site" {
+			return response.BadRequest(c, "Invalid format. Must be 'html', 'markdown' or 'site'")
 		}
 
-		var groups []models.Group
-		if len(groupIDs) > 0 {
-			if err := db.Where("id IN ?", groupIDs).Find(&groups).Error; err != nil {
-				return response.InternalError(c, "Failed to fetch groups")
-			}
+		if body.Layout == "" {
+			body.Layout = "single"
+		}
+		if body.Layout != "single" && body.Layout != "group" {
+			return response.BadRequest(c, "Invalid layout. Must be 'single' or 'group'")
 		}
 
-		groupMap := make(map[uint]string)
-		for _, g := range groups {
-			groupMap[g.ID] = g.Name
+		apisWithParams, err := services.LoadExportAPIs(db, body.APIIDs, body.Environment)
+		if err != nil {
+			return response.InternalError(c, "Failed to load APIs for export")
 		}
 
-		// Fetch parameters for each API
-		var apisWithParams []services.APIWithParams
-		for _, api := range apis {
-			var allParams []models.Parameter
-			if err := db.Where("api_id = ?", api.ID).Order("`order` ASC").Find(&allParams).Error; err != nil {
-				return response.InternalError(c, "Failed to fetch parameters")
+		// Get locale from cookie or default to "zh"
+		locale := c.Cookies("locale", "zh")
+
+		if body.Template != "" {
+			source, err := services.LoadExportTemplate(body.Template)
+			if err != nil {
+				return templateError(c, err)
 			}
 
-			requestParams := make([]models.Parameter, 0)
-			responseParams := make([]models.Parameter, 0)
+			tmpl, err := services.ParseExportTemplate(body.Template, source)
+			if err != nil {
+				return response.BadRequest(c, "Invalid template: "+err.Error())
+			}
 
-			for _, p := range allParams {
-				if p.ParamType == "request" {
-					requestParams = append(requestParams, p)
-				} else if p.ParamType == "response" {
-					responseParams = append(responseParams, p)
-				}
+			output, err := services.RenderExportTemplate(tmpl, apisWithParams, locale)
+			if err != nil {
+				return response.BadRequest(c, "Failed to render template: "+err.Error())
 			}
 
-			groupName := groupMap[api.GroupID]
-			if groupName == "" {
-				groupName = "Ungrouped"
+			ext := services.TemplateExtension(body.Template)
+			contentType := mime.TypeByExtension(ext)
+			if contentType == "" {
+				contentType = "text/plain; charset=utf-8"
 			}
 
-			apisWithParams = append(apisWithParams, services.APIWithParams{
-				API:                api,
-				GroupName:          groupName,
-				RequestParameters:  requestParams,
-				ResponseParameters: responseParams,
-			})
+			c.Set("Content-Type", contentType)
+			c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"api-docs%s\"", ext))
+			return c.SendString(output)
 		}
 
-		// Get locale from cookie or default to "zh"
-		locale := c.Cookies("locale", "zh")
+		if body.Format != "html" {
+			var files []services.ExportFile
+			if body.Format == "site" {
+				files = services.GenerateSite(apisWithParams, services.SiteOptions{Locale: locale})
+			} else if body.Layout == "group" {
+				files = services.GenerateMarkdownFiles(apisWithParams, locale)
+			} else {
+				files = []services.ExportFile{{Name: "api-docs.md", Content: services.GenerateMarkdown(apisWithParams, locale)}}
+			}
+
+			if body.Format == "markdown" && !body.Zip && len(files) == 1 {
+				c.Set("Content-Type", "text/markdown; charset=utf-8")
+				c.Set("Content-Disposition", "attachment; filename=\"api-docs.md\"")
+				return c.SendString(files[0].Content)
+			}
+
+			var archive bytes.Buffer
+			if err := services.WriteExportZip(&archive, files); err != nil {
+				return response.InternalError(c, "Failed to create archive")
+			}
+
+			c.Set("Content-Type", "application/zip")
+			c.Set("Content-Disposition", "attachment; filename=\"api-docs.zip\"")
+			return c.Send(archive.Bytes())
+		}
 
 		// Generate HTML
 		html := services.GenerateHTML(apisWithParams, locale)
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// graphQLImportBody is the body of the GraphQL import endpoints
+type graphQLImportBody struct {
+	Schema string   `json:"schema"` // GraphQL schema in SDL
+	Depth  int      `json:"depth"`  // Levels of object types expanded in responses; 3 if not given
+	Keys   []string `json:"keys"`   // Candidates to import, e.g. "QUERY user"; all if empty
+}
+
+// PreviewGraphQLImport lists the GRAPHQL APIs a GraphQL schema would create in a group
+func PreviewGraphQLImport(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body graphQLImportBody
+		if err := c.BodyParser(&body); err != nil || body.Schema == "" {
+			return response.BadRequest(c, "Invalid request body. A schema is required")
+		}
+
+		group, candidates, err := loadGraphQLCandidates(db, c.Params("id"), body)
+		if err != nil {
+			return graphQLImportError(c, err)
+		}
+
+		return response.Success(c, fiber.Map{
+			"groupId":    group.ID,
+			"candidates": candidates,
+		})
+	}
+}
+
+// ImportGraphQL creates the GRAPHQL APIs of a GraphQL schema in a group. Only the candidates
+// listed in keys are created, or all of them; fields matching existing APIs are skipped.
+func ImportGraphQL(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body graphQLImportBody
+		if err := c.BodyParser(&body); err != nil || body.Schema == "" {
+			return response.BadRequest(c, "Invalid request body. A schema is required")
+		}
+
+		group, candidates, err := loadGraphQLCandidates(db, c.Params("id"), body)
+		if err != nil {
+			return graphQLImportError(c, err)
+		}
+
+		created, err := services.ImportGraphQLCandidates(db, group, candidates, body.Keys)
+		if err != nil {
+			return response.InternalError(c, "Failed to import APIs")
+		}
+
+		for _, api := range created {
+			services.PublishChange(db, services.ChangeEvent{
+				Type:    services.EventAPICreated,
+				GroupID: api.GroupID,
+				APIID:   api.ID,
+				Entity:  api,
+				Diff:    services.DiffValues(nil, services.APISnapshot(api)),
+			})
+		}
+
+		return response.Success(c, fiber.Map{
+			"count": len(created),
+			"apis":  created,
+		})
+	}
+}
+
+// loadGraphQLCandidates previews the import of a GraphQL schema into a group given by ID
+func loadGraphQLCandidates(db *gorm.DB, groupID string, body graphQLImportBody) (models.Group, []services.GraphQLImportCandidate, error) {
+	var group models.Group
+	if err := db.First(&group, groupID).Error; err != nil {
+		return group, nil, err
+	}
+
+	candidates, err := services.PreviewGraphQLImport(db, group, body.Schema, body.Depth)
+	return group, candidates, err
+}
+
+// graphQLImportError maps an error of loadGraphQLCandidates to a response
+func graphQLImportError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, "Group not found")
+	case errors.Is(err, services.ErrInvalidSDL):
+		return response.BadRequest(c, err.Error())
+	}
+	return response.InternalError(c, "Failed to read GraphQL schema")
+}
@@ -4,6 +4,7 @@ import (
 	"strconv"
 
 	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
 	"github.com/ProjAnvil/knot/backend/pkg/response"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -44,11 +45,19 @@ func GetGroupsWithAPIs(db *gorm.DB) fiber.Handler {
 			groups = []models.Group{}
 		}
 
+		unresolved, err := services.CountUnresolvedComments(db, nil)
+		if err != nil {
+			return response.InternalError(c, "Failed to count unresolved comments")
+		}
+
 		// Ensure each group's APIs slice is never nil
 		for i := range groups {
 			if groups[i].APIs == nil {
 				groups[i].APIs = []models.API{}
 			}
+			for j := range groups[i].APIs {
+				groups[i].APIs[j].UnresolvedComments = unresolved[groups[i].APIs[j].ID]
+			}
 		}
 
 		return response.Success(c, groups)
@@ -70,16 +79,8 @@ func CreateGroup(db *gorm.DB) fiber.Handler {
 			return response.BadRequest(c, "Group name is required")
 		}
 
-		// Get max order
-		var maxOrder int
-		db.Model(&models.Group{}).Select("COALESCE(MAX(`order`), 0)").Scan(&maxOrder)
-
-		group := models.Group{
-			Name:  body.Name,
-			Order: maxOrder + 1,
-		}
-
-		if err := db.Create(&group).Error; err != nil {
+		group := models.Group{Name: body.Name}
+		if _, err := insertGroup(db, &group, services.ChangeSourceAPI); err != nil {
 			return response.InternalError(c, "Failed to create group")
 		}
 
@@ -87,6 +88,29 @@ func CreateGroup(db *gorm.DB) fiber.Handler {
 	}
 }
 
+// insertGroup adds a group after all other groups and publishes the change made from source.
+// It returns the published diff.
+func insertGroup(db *gorm.DB, group *models.Group, source string) ([]services.FieldChange, error) {
+	// Get max order
+	var maxOrder int
+	db.Model(&models.Group{}).Select("COALESCE(MAX(`order`), 0)").Scan(&maxOrder)
+	group.Order = maxOrder + 1
+
+	if err := db.Create(group).Error; err != nil {
+		return nil, err
+	}
+
+	diff := services.DiffValues(nil, services.GroupSnapshot(*group))
+	services.PublishChange(db, services.ChangeEvent{
+		Type:    services.EventGroupCreated,
+		GroupID: group.ID,
+		Entity:  *group,
+		Diff:    diff,
+		Source:  source,
+	})
+	return diff, nil
+}
+
 // UpdateGroup updates a group name
 func UpdateGroup(db *gorm.DB) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -115,11 +139,20 @@ func UpdateGroup(db *gorm.DB) fiber.Handler {
 			return response.InternalError(c, "Failed to fetch group")
 		}
 
+		before := services.GroupSnapshot(group)
+
 		group.Name = body.Name
 		if err := db.Save(&group).Error; err != nil {
 			return response.InternalError(c, "Failed to update group")
 		}
 
+		services.PublishChange(db, services.ChangeEvent{
+			Type:    services.EventGroupUpdated,
+			GroupID: group.ID,
+			Entity:  group,
+			Diff:    services.DiffValues(before, services.GroupSnapshot(group)),
+		})
+
 		return response.Success(c, group)
 	}
 }
@@ -164,6 +197,14 @@ func DeleteGroup(db *gorm.DB) fiber.Handler {
 			return response.BadRequest(c, "Invalid group ID")
 		}
 
+		var group models.Group
+		if err := db.First(&group, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return response.NotFound(c, "Group not found")
+			}
+			return response.InternalError(c, "Failed to fetch group")
+		}
+
 		// Delete the group (APIs will be cascade deleted via foreign key constraint)
 		result := db.Delete(&models.Group{}, id)
 		if result.Error != nil {
@@ -174,6 +215,13 @@ func DeleteGroup(db *gorm.DB) fiber.Handler {
 			return response.NotFound(c, "Group not found")
 		}
 
+		services.PublishChange(db, services.ChangeEvent{
+			Type:    services.EventGroupDeleted,
+			GroupID: group.ID,
+			Entity:  group,
+			Diff:    services.DiffValues(services.GroupSnapshot(group), nil),
+		})
+
 		return response.Success(c, nil)
 	}
 }
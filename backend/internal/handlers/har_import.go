@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// harImportBody is the body of the HAR import endpoints
+type harImportBody struct {
+	HAR  json.RawMessage `json:"har"`
+	Host string          `json:"host"`
+	Keys []string        `json:"keys"` // Candidates to import, e.g. "GET /users/:id"; all if empty
+}
+
+// PreviewHARImport lists the APIs a HAR file would create in a group, with their inferred parameters
+func PreviewHARImport(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body harImportBody
+		if err := c.BodyParser(&body); err != nil || len(body.HAR) == 0 {
+			return response.BadRequest(c, "Invalid request body. A har object is required")
+		}
+
+		group, candidates, err := loadHARCandidates(db, c.Params("id"), body)
+		if err != nil {
+			return harImportError(c, err)
+		}
+
+		return response.Success(c, fiber.Map{
+			"groupId":    group.ID,
+			"candidates": candidates,
+		})
+	}
+}
+
+// ImportHAR creates the APIs found in a HAR file in a group. Only the candidates listed in
+// keys are created, or all of them; calls matching documented APIs are skipped.
+func ImportHAR(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body harImportBody
+		if err := c.BodyParser(&body); err != nil || len(body.HAR) == 0 {
+			return response.BadRequest(c, "Invalid request body. A har object is required")
+		}
+
+		group, candidates, err := loadHARCandidates(db, c.Params("id"), body)
+		if err != nil {
+			return harImportError(c, err)
+		}
+
+		created, err := services.ImportHARCandidates(db, group, candidates, body.Keys)
+		if err != nil {
+			return response.InternalError(c, "Failed to import APIs")
+		}
+
+		for _, api := range created {
+			services.PublishChange(db, services.ChangeEvent{
+				Type:    services.EventAPICreated,
+				GroupID: api.GroupID,
+				APIID:   api.ID,
+				Entity:  api,
+				Diff:    services.DiffValues(nil, services.APISnapshot(api)),
+			})
+		}
+
+		return response.Success(c, fiber.Map{
+			"count": len(created),
+			"apis":  created,
+		})
+	}
+}
+
+// loadHARCandidates previews the import of a HAR file into a group given by ID
+func loadHARCandidates(db *gorm.DB, groupID string, body harImportBody) (models.Group, []services.HARImportCandidate, error) {
+	var group models.Group
+	if err := db.First(&group, groupID).Error; err != nil {
+		return group, nil, err
+	}
+
+	candidates, err := services.PreviewHARImport(db, group, body.HAR, services.HARImportOptions{Host: body.Host})
+	return group, candidates, err
+}
+
+// harImportError maps an error of loadHARCandidates to a response
+func harImportError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.NotFound(c, "Group not found")
+	case errors.Is(err, services.ErrInvalidHAR):
+		return response.BadRequest(c, err.Error())
+	}
+	return response.InternalError(c, "Failed to read HAR file")
+}
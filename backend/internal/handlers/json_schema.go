@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetAPISchemas returns the JSON Schemas of the request and response of an API
+func GetAPISchemas(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		api, err := findAPI(db, c.Params("id"))
+		if err != nil {
+			return apiLookupError(c, err)
+		}
+
+		requestTree, err := loadParameterTree(db, api.ID, "request")
+		if err != nil {
+			return response.InternalError(c, "Failed to fetch parameters")
+		}
+		responseTree, err := loadParameterTree(db, api.ID, "response")
+		if err != nil {
+			return response.InternalError(c, "Failed to fetch parameters")
+		}
+
+		return response.Success(c, fiber.Map{
+			"request":  services.GenerateJSONSchema(api, "request", requestTree),
+			"response": services.GenerateJSONSchema(api, "response", responseTree),
+		})
+	}
+}
+
+// GetAPISchema returns the JSON Schema of the request or response of an API as a plain
+// schema document, so that other tools can fetch and reference it
+func GetAPISchema(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		paramType := c.Params("paramType")
+		if paramType != "request" && paramType != "response" {
+			return response.BadRequest(c, "Invalid paramType. Must be 'request' or 'response'")
+		}
+
+		api, err := findAPI(db, c.Params("id"))
+		if err != nil {
+			return apiLookupError(c, err)
+		}
+
+		tree, err := loadParameterTree(db, api.ID, paramType)
+		if err != nil {
+			return response.InternalError(c, "Failed to fetch parameters")
+		}
+
+		content, err := json.MarshalIndent(services.GenerateJSONSchema(api, paramType, tree), "", "  ")
+		if err != nil {
+			return response.InternalError(c, "Failed to generate schema")
+		}
+
+		c.Set("Content-Type", "application/schema+json")
+		return c.Send(content)
+	}
+}
+
+// ExportGroupSchemas downloads the JSON Schemas of every API in a group as a ZIP archive
+func ExportGroupSchemas(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var group models.Group
+		if err := db.First(&group, c.Params("id")).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return response.NotFound(c, "Group not found")
+			}
+			return response.InternalError(c, "Failed to fetch group")
+		}
+
+		apiIDs := []uint{}
+		if err := db.Model(&models.API{}).Where("group_id = ?", group.ID).Pluck("id", &apiIDs).Error; err != nil {
+			return response.InternalError(c, "Failed to fetch APIs")
+		}
+
+		apis, err := services.LoadExportAPIs(db, apiIDs, "")
+		if err != nil {
+			return response.InternalError(c, "Failed to load APIs for export")
+		}
+
+		files, err := services.GenerateJSONSchemaFiles(apis)
+		if err != nil {
+			return response.InternalError(c, "Failed to generate schemas")
+		}
+
+		var archive bytes.Buffer
+		if err := services.WriteExportZip(&archive, files); err != nil {
+			return response.InternalError(c, "Failed to create archive")
+		}
+
+		c.Set("Content-Type", "application/zip")
+		c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"group-%d-schemas.zip\"", group.ID))
+		return c.Send(archive.Bytes())
+	}
+}
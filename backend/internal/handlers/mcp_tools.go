@@ -3,6 +3,7 @@ package handlers
 import (
 	"strings"
 
+	"github.com/ProjAnvil/knot/backend/internal/config"
 	"github.com/ProjAnvil/knot/backend/internal/models"
 	"github.com/ProjAnvil/knot/backend/internal/services"
 	"github.com/ProjAnvil/knot/backend/pkg/response"
@@ -10,8 +11,23 @@ import (
 	"gorm.io/gorm"
 )
 
+// MCPToolError is a failed MCP tool call, with the HTTP status it is reported with over
+// POST /api/mcp-tools
+type MCPToolError struct {
+	Status  int
+	Message string
+}
+
+func (e *MCPToolError) Error() string {
+	return e.Message
+}
+
+func mcpToolError(status int, message string) error {
+	return &MCPToolError{Status: status, Message: message}
+}
+
 // HandleMCPTools handles all MCP tool calls
-func HandleMCPTools(db *gorm.DB) fiber.Handler {
+func HandleMCPTools(db *gorm.DB, cfg *config.Config) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		var body struct {
 			Tool string                 `json:"tool"`
@@ -26,31 +42,72 @@ func HandleMCPTools(db *gorm.DB) fiber.Handler {
 			return response.BadRequest(c, "Tool name is required")
 		}
 
-		// Route to appropriate tool handler
-		switch body.Tool {
-		case "list_groups":
-			return handleListGroups(c, db)
-		case "get_group":
-			return handleGetGroup(c, db, body.Args)
-		case "list_apis_by_group":
-			return handleListAPIsByGroup(c, db, body.Args)
-		case "get_api":
-			return handleGetAPI(c, db, body.Args)
-		case "search_apis":
-			return handleSearchAPIs(c, db, body.Args)
-		case "get_api_json_example":
-			return handleGetAPIJSONExample(c, db, body.Args)
-		default:
-			return response.BadRequest(c, "Unknown tool: "+body.Tool)
+		data, err := CallMCPTool(db, cfg, body.Tool, body.Args)
+		if err != nil {
+			if toolErr, ok := err.(*MCPToolError); ok {
+				return response.Error(c, toolErr.Status, toolErr.Message)
+			}
+			return response.InternalError(c, err.Error())
 		}
+		return c.JSON(fiber.Map{"data": data})
 	}
 }
 
-// handleListGroups lists all API groups
-func handleListGroups(c *fiber.Ctx, db *gorm.DB) error {
+// CallMCPTool runs an MCP tool with its arguments and returns its data. Failures are
+// *MCPToolError. It serves both POST /api/mcp-tools and the embedded MCP server.
+// Write tools are refused unless cfg.MCPWrite is set.
+func CallMCPTool(db *gorm.DB, cfg *config.Config, tool string, args map[string]interface{}) (interface{}, error) {
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+
+	if write, ok := mcpWriteTools[tool]; ok {
+		if !cfg.MCPWrite {
+			return nil, mcpToolError(fiber.StatusForbidden, "MCP write tools are disabled; set mcpWrite to true in the config to enable them")
+		}
+		return write(db, cfg, args)
+	}
+
+	// Route to appropriate tool handler
+	switch tool {
+	case "list_groups":
+		return toolListGroups(db, args)
+	case "get_group":
+		return toolGetGroup(db, args)
+	case "list_apis_by_group":
+		return toolListAPIsByGroup(db, args)
+	case "get_api":
+		return toolGetAPI(db, args)
+	case "search_apis":
+		return toolSearchAPIs(db, args)
+	case "get_api_json_example":
+		return toolGetAPIJSONExample(db, args)
+	case "get_api_comments":
+		return toolGetAPIComments(db, args)
+	case "get_api_types":
+		return toolGetAPITypes(db, args)
+	case "get_api_snippets":
+		return toolGetAPISnippets(db, args)
+	case "get_api_sdl":
+		return toolGetAPISDL(db, args)
+	default:
+		return nil, mcpToolError(fiber.StatusBadRequest, "Unknown tool: "+tool)
+	}
+}
+
+// environmentToolError maps an error of services.LoadEnvironment to a tool error
+func environmentToolError(err error) error {
+	if err == services.ErrEnvironmentNotFound || err == gorm.ErrRecordNotFound {
+		return mcpToolError(fiber.StatusNotFound, "Environment not found")
+	}
+	return mcpToolError(fiber.StatusInternalServerError, "Failed to fetch environment")
+}
+
+// toolListGroups lists all API groups
+func toolListGroups(db *gorm.DB, args map[string]interface{}) (interface{}, error) {
 	var groups []models.Group
 	if err := db.Order("created_at DESC").Find(&groups).Error; err != nil {
-		return response.InternalError(c, "Failed to fetch groups")
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to fetch groups")
 	}
 
 	data := make([]map[string]interface{}, len(groups))
@@ -62,52 +119,50 @@ func handleListGroups(c *fiber.Ctx, db *gorm.DB) error {
 		}
 	}
 
-	return c.JSON(fiber.Map{"data": data})
+	return data, nil
 }
 
-// handleGetGroup gets group details with API count
-func handleGetGroup(c *fiber.Ctx, db *gorm.DB, args map[string]interface{}) error {
+// toolGetGroup gets group details with API count
+func toolGetGroup(db *gorm.DB, args map[string]interface{}) (interface{}, error) {
 	groupName, ok := args["groupName"].(string)
 	if !ok || groupName == "" {
-		return response.BadRequest(c, "groupName is required")
+		return nil, mcpToolError(fiber.StatusBadRequest, "groupName is required")
 	}
 
 	var groups []models.Group
 	if err := db.Where("name LIKE ?", "%"+groupName+"%").Preload("APIs").Find(&groups).Error; err != nil {
-		return response.InternalError(c, "Failed to fetch groups")
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to fetch groups")
 	}
 
 	if len(groups) == 0 {
-		return response.NotFound(c, "No group found matching: "+groupName)
+		return nil, mcpToolError(fiber.StatusNotFound, "No group found matching: "+groupName)
 	}
 
 	group := groups[0]
-	return c.JSON(fiber.Map{
-		"data": map[string]interface{}{
-			"id":        group.ID,
-			"name":      group.Name,
-			"apiCount":  len(group.APIs),
-			"createdAt": group.CreatedAt,
-		},
-	})
+	return map[string]interface{}{
+		"id":        group.ID,
+		"name":      group.Name,
+		"apiCount":  len(group.APIs),
+		"createdAt": group.CreatedAt,
+	}, nil
 }
 
-// handleListAPIsByGroup lists all APIs in a group
-func handleListAPIsByGroup(c *fiber.Ctx, db *gorm.DB, args map[string]interface{}) error {
+// toolListAPIsByGroup lists all APIs in a group
+func toolListAPIsByGroup(db *gorm.DB, args map[string]interface{}) (interface{}, error) {
 	groupName, ok := args["groupName"].(string)
 	if !ok || groupName == "" {
-		return response.BadRequest(c, "groupName is required")
+		return nil, mcpToolError(fiber.StatusBadRequest, "groupName is required")
 	}
 
 	var groups []models.Group
 	if err := db.Where("name LIKE ?", "%"+groupName+"%").Preload("APIs", func(db *gorm.DB) *gorm.DB {
 		return db.Order("`order` ASC")
 	}).Find(&groups).Error; err != nil {
-		return response.InternalError(c, "Failed to fetch groups")
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to fetch groups")
 	}
 
 	if len(groups) == 0 {
-		return response.NotFound(c, "No group found matching: "+groupName)
+		return nil, mcpToolError(fiber.StatusNotFound, "No group found matching: "+groupName)
 	}
 
 	group := groups[0]
@@ -119,25 +174,24 @@ func handleListAPIsByGroup(c *fiber.Ctx, db *gorm.DB, args map[string]interface{
 			"endpoint": api.Endpoint,
 			"method":   api.Method,
 			"type":     api.Type,
+			"protocol": api.Protocol,
 		}
 	}
 
-	return c.JSON(fiber.Map{
-		"data": map[string]interface{}{
-			"group": map[string]interface{}{
-				"id":   group.ID,
-				"name": group.Name,
-			},
-			"apis": apis,
+	return map[string]interface{}{
+		"group": map[string]interface{}{
+			"id":   group.ID,
+			"name": group.Name,
 		},
-	})
+		"apis": apis,
+	}, nil
 }
 
-// handleGetAPI gets full API details with parameters
-func handleGetAPI(c *fiber.Ctx, db *gorm.DB, args map[string]interface{}) error {
+// toolGetAPI gets full API details with parameters
+func toolGetAPI(db *gorm.DB, args map[string]interface{}) (interface{}, error) {
 	apiID, ok := args["apiId"].(float64)
 	if !ok {
-		return response.BadRequest(c, "apiId (number) is required")
+		return nil, mcpToolError(fiber.StatusBadRequest, "apiId (number) is required")
 	}
 
 	var api models.API
@@ -145,9 +199,9 @@ func handleGetAPI(c *fiber.Ctx, db *gorm.DB, args map[string]interface{}) error
 		return db.Order("`order` ASC")
 	}).First(&api, uint(apiID)).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return response.NotFound(c, "API not found")
+			return nil, mcpToolError(fiber.StatusNotFound, "API not found")
 		}
-		return response.InternalError(c, "Failed to fetch API")
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to fetch API")
 	}
 
 	// Separate request and response parameters
@@ -166,26 +220,42 @@ func handleGetAPI(c *fiber.Ctx, db *gorm.DB, args map[string]interface{}) error
 	requestTree := services.BuildParameterTree(requestParams)
 	responseTree := services.BuildParameterTree(responseParams)
 
-	return c.JSON(fiber.Map{
-		"data": map[string]interface{}{
-			"id":                 api.ID,
-			"name":               api.Name,
-			"endpoint":           api.Endpoint,
-			"method":             api.Method,
-			"type":               api.Type,
-			"note":               api.Note,
-			"group":              map[string]interface{}{"id": api.Group.ID, "name": api.Group.Name},
-			"requestParameters":  requestTree,
-			"responseParameters": responseTree,
-		},
-	})
+	data := map[string]interface{}{
+		"id":                 api.ID,
+		"name":               api.Name,
+		"endpoint":           api.Endpoint,
+		"method":             api.Method,
+		"type":               api.Type,
+		"protocol":           api.Protocol,
+		"note":               api.Note,
+		"group":              map[string]interface{}{"id": api.Group.ID, "name": api.Group.Name},
+		"requestParameters":  requestTree,
+		"responseParameters": responseTree,
+	}
+
+	if api.Signature != nil {
+		data["signature"] = *api.Signature
+	}
+
+	// Resolve the full URL when an environment is requested; channels of EVENT APIs and
+	// fields of GRAPHQL APIs are not URLs
+	if environment, ok := args["environment"].(string); ok && environment != "" && api.Type != models.APITypeEvent && api.Type != models.APITypeGraphQL {
+		env, err := services.LoadEnvironment(db, environment, api.GroupID)
+		if err != nil {
+			return nil, environmentToolError(err)
+		}
+		data["environment"] = env.Name
+		data["url"] = env.Mask(env.URL(api.Endpoint))
+	}
+
+	return data, nil
 }
 
-// handleSearchAPIs searches APIs by name or endpoint
-func handleSearchAPIs(c *fiber.Ctx, db *gorm.DB, args map[string]interface{}) error {
+// toolSearchAPIs searches APIs by name or endpoint
+func toolSearchAPIs(db *gorm.DB, args map[string]interface{}) (interface{}, error) {
 	query, ok := args["query"].(string)
 	if !ok || query == "" {
-		return response.BadRequest(c, "query is required")
+		return nil, mcpToolError(fiber.StatusBadRequest, "query is required")
 	}
 
 	var apis []models.API
@@ -194,7 +264,7 @@ func handleSearchAPIs(c *fiber.Ctx, db *gorm.DB, args map[string]interface{}) er
 		Preload("Group").
 		Limit(50).
 		Find(&apis).Error; err != nil {
-		return response.InternalError(c, "Failed to search APIs")
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to search APIs")
 	}
 
 	results := make([]map[string]interface{}, len(apis))
@@ -205,6 +275,7 @@ func handleSearchAPIs(c *fiber.Ctx, db *gorm.DB, args map[string]interface{}) er
 			"endpoint": api.Endpoint,
 			"method":   api.Method,
 			"type":     api.Type,
+			"protocol": api.Protocol,
 			"group": map[string]interface{}{
 				"id":   api.Group.ID,
 				"name": api.Group.Name,
@@ -212,19 +283,17 @@ func handleSearchAPIs(c *fiber.Ctx, db *gorm.DB, args map[string]interface{}) er
 		}
 	}
 
-	return c.JSON(fiber.Map{
-		"data": map[string]interface{}{
-			"count": len(results),
-			"apis":  results,
-		},
-	})
+	return map[string]interface{}{
+		"count": len(results),
+		"apis":  results,
+	}, nil
 }
 
-// handleGetAPIJSONExample generates example JSON for API
-func handleGetAPIJSONExample(c *fiber.Ctx, db *gorm.DB, args map[string]interface{}) error {
+// toolGetAPIJSONExample generates example JSON for API
+func toolGetAPIJSONExample(db *gorm.DB, args map[string]interface{}) (interface{}, error) {
 	apiID, ok := args["apiId"].(float64)
 	if !ok {
-		return response.BadRequest(c, "apiId (number) is required")
+		return nil, mcpToolError(fiber.StatusBadRequest, "apiId (number) is required")
 	}
 
 	var api models.API
@@ -232,9 +301,9 @@ func handleGetAPIJSONExample(c *fiber.Ctx, db *gorm.DB, args map[string]interfac
 		return db.Order("`order` ASC")
 	}).First(&api, uint(apiID)).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return response.NotFound(c, "API not found")
+			return nil, mcpToolError(fiber.StatusNotFound, "API not found")
 		}
-		return response.InternalError(c, "Failed to fetch API")
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to fetch API")
 	}
 
 	// Separate request and response parameters
@@ -262,13 +331,216 @@ func handleGetAPIJSONExample(c *fiber.Ctx, db *gorm.DB, args map[string]interfac
 		responseExample = services.GenerateExampleJSON(responseTree)
 	}
 
-	return c.JSON(fiber.Map{
-		"data": map[string]interface{}{
-			"apiName":         api.Name,
-			"endpoint":        api.Endpoint,
-			"method":          api.Method,
-			"requestExample":  requestExample,
-			"responseExample": responseExample,
-		},
-	})
+	return map[string]interface{}{
+		"apiName":         api.Name,
+		"endpoint":        api.Endpoint,
+		"method":          api.Method,
+		"requestExample":  requestExample,
+		"responseExample": responseExample,
+	}, nil
+}
+
+// toolGetAPIComments returns the review discussion of an API
+func toolGetAPIComments(db *gorm.DB, args map[string]interface{}) (interface{}, error) {
+	apiID, ok := args["apiId"].(float64)
+	if !ok {
+		return nil, mcpToolError(fiber.StatusBadRequest, "apiId (number) is required")
+	}
+	includeResolved, _ := args["includeResolved"].(bool)
+
+	var api models.API
+	if err := db.Preload("Parameters", func(db *gorm.DB) *gorm.DB {
+		return db.Order("`order` ASC")
+	}).First(&api, uint(apiID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, mcpToolError(fiber.StatusNotFound, "API not found")
+		}
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to fetch API")
+	}
+
+	threads, err := services.LoadCommentThreads(db, api.ID, nil, includeResolved)
+	if err != nil {
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to fetch comments")
+	}
+
+	// Resolve parameter IDs to readable paths such as "response.items[].id"
+	targets := make(map[uint]string)
+	for _, paramType := range []string{"request", "response"} {
+		params := make([]models.Parameter, 0)
+		for _, p := range api.Parameters {
+			if p.ParamType == paramType {
+				params = append(params, p)
+			}
+		}
+		for id, path := range services.ParameterPaths(services.BuildParameterTree(params)) {
+			targets[id] = paramType + "." + path
+		}
+	}
+
+	formatComment := func(comment models.Comment) map[string]interface{} {
+		return map[string]interface{}{
+			"id":        comment.ID,
+			"author":    comment.Author,
+			"body":      comment.Body,
+			"mentions":  comment.MentionList(),
+			"createdAt": comment.CreatedAt,
+		}
+	}
+
+	openCount := 0
+	results := make([]map[string]interface{}, len(threads))
+	for i, thread := range threads {
+		target := "api"
+		if thread.ParameterID != nil {
+			target = targets[*thread.ParameterID]
+			if target == "" {
+				target = "parameter (removed)"
+			}
+		}
+
+		replies := make([]map[string]interface{}, len(thread.Replies))
+		for j, reply := range thread.Replies {
+			replies[j] = formatComment(reply)
+		}
+
+		result := formatComment(thread)
+		result["target"] = target
+		result["parameterId"] = thread.ParameterID
+		result["resolved"] = thread.Resolved
+		result["resolvedBy"] = thread.ResolvedBy
+		result["replies"] = replies
+		results[i] = result
+
+		if !thread.Resolved {
+			openCount++
+		}
+	}
+
+	return map[string]interface{}{
+		"apiId":       api.ID,
+		"apiName":     api.Name,
+		"endpoint":    api.Endpoint,
+		"openThreads": openCount,
+		"threads":     results,
+	}, nil
+}
+
+// toolGetAPITypes generates the request and response types of an API in a language
+func toolGetAPITypes(db *gorm.DB, args map[string]interface{}) (interface{}, error) {
+	apiID, ok := args["apiId"].(float64)
+	if !ok {
+		return nil, mcpToolError(fiber.StatusBadRequest, "apiId (number) is required")
+	}
+
+	language, _ := args["language"].(string)
+	if language == "" {
+		language = "typescript"
+	}
+
+	var api models.API
+	if err := db.First(&api, uint(apiID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, mcpToolError(fiber.StatusNotFound, "API not found")
+		}
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to fetch API")
+	}
+
+	apis, err := services.LoadExportAPIs(db, []uint{api.ID}, "")
+	if err != nil {
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to fetch parameters")
+	}
+
+	code, err := services.GenerateModels(apis, language, services.CodegenOptions{})
+	if err == services.ErrUnsupportedLanguage {
+		return nil, mcpToolError(fiber.StatusBadRequest, "language must be one of: "+strings.Join(services.CodegenLanguages, ", "))
+	}
+
+	return map[string]interface{}{
+		"apiName":  api.Name,
+		"endpoint": api.Endpoint,
+		"method":   api.Method,
+		"language": language,
+		"code":     code,
+	}, nil
+}
+
+// toolGetAPISnippets generates ready-to-run calls of an API
+func toolGetAPISnippets(db *gorm.DB, args map[string]interface{}) (interface{}, error) {
+	apiID, ok := args["apiId"].(float64)
+	if !ok {
+		return nil, mcpToolError(fiber.StatusBadRequest, "apiId (number) is required")
+	}
+
+	var languages []string
+	if language, _ := args["language"].(string); language != "" {
+		languages = []string{language}
+	}
+
+	var api models.API
+	if err := db.First(&api, uint(apiID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, mcpToolError(fiber.StatusNotFound, "API not found")
+		}
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to fetch API")
+	}
+
+	input := services.ExecuteInput{}
+	input.BaseURL, _ = args["baseUrl"].(string)
+	if environment, ok := args["environment"].(string); ok && environment != "" {
+		env, err := services.LoadEnvironment(db, environment, api.GroupID)
+		if err != nil {
+			return nil, environmentToolError(err)
+		}
+		input.Env = env
+	}
+
+	requestTree, err := loadParameterTree(db, api.ID, "request")
+	if err != nil {
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to fetch parameters")
+	}
+
+	snippets, err := services.GenerateSnippets(api, requestTree, input, languages)
+	if err == services.ErrUnsupportedLanguage {
+		return nil, mcpToolError(fiber.StatusBadRequest, "language must be one of: "+strings.Join(services.SnippetLanguages, ", "))
+	}
+	if err != nil {
+		return nil, mcpToolError(fiber.StatusBadRequest, err.Error())
+	}
+
+	return map[string]interface{}{
+		"apiName":  api.Name,
+		"endpoint": api.Endpoint,
+		"method":   api.Method,
+		"snippets": snippets,
+	}, nil
+}
+
+// toolGetAPISDL returns the SDL signature of a GRAPHQL API: its root field and the types it uses
+func toolGetAPISDL(db *gorm.DB, args map[string]interface{}) (interface{}, error) {
+	apiID, ok := args["apiId"].(float64)
+	if !ok {
+		return nil, mcpToolError(fiber.StatusBadRequest, "apiId (number) is required")
+	}
+
+	var api models.API
+	if err := db.First(&api, uint(apiID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, mcpToolError(fiber.StatusNotFound, "API not found")
+		}
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to fetch API")
+	}
+
+	if api.Type != models.APITypeGraphQL {
+		return nil, mcpToolError(fiber.StatusBadRequest, "Only GRAPHQL APIs have an SDL signature")
+	}
+	if api.Signature == nil || *api.Signature == "" {
+		return nil, mcpToolError(fiber.StatusNotFound, "The API has no SDL signature; import it from a GraphQL schema to record one")
+	}
+
+	return map[string]interface{}{
+		"apiName":   api.Name,
+		"operation": api.Method,
+		"field":     api.Endpoint,
+		"signature": *api.Signature,
+	}, nil
 }
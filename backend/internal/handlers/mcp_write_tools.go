@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/config"
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// mcpWriteTool is an MCP tool that creates or updates documentation
+type mcpWriteTool func(db *gorm.DB, cfg *config.Config, args map[string]interface{}) (interface{}, error)
+
+// mcpWriteTools are the tools that change documentation. They are only available with
+// cfg.MCPWrite, publish their changes with source mcp and return the diff of what changed.
+// When approval is required, API edits are collected into a draft authored by mcp instead,
+// new APIs are proposed in a draft and groups, which cannot be reviewed, are not created.
+var mcpWriteTools = map[string]mcpWriteTool{
+	"create_group":                 toolCreateGroup,
+	"create_api":                   toolCreateAPI,
+	"update_api":                   toolUpdateAPI,
+	"update_api_note":              toolUpdateAPINote,
+	"set_api_parameters_from_json": toolSetAPIParametersFromJSON,
+}
+
+// toolCreateGroup creates a group unless one with the same name exists.
+// It is refused when approval is required, since group changes are not reviewed.
+func toolCreateGroup(db *gorm.DB, cfg *config.Config, args map[string]interface{}) (interface{}, error) {
+	name, _ := args["name"].(string)
+	if name = strings.TrimSpace(name); name == "" {
+		return nil, mcpToolError(fiber.StatusBadRequest, "name is required")
+	}
+	if cfg.RequireApproval {
+		return nil, mcpToolError(fiber.StatusForbidden, "Groups cannot be created through MCP while edits require approval; ask a maintainer to create the group")
+	}
+
+	var count int64
+	if err := db.Model(&models.Group{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to fetch groups")
+	}
+	if count > 0 {
+		return nil, mcpToolError(fiber.StatusConflict, "Group already exists: "+name)
+	}
+
+	group := models.Group{Name: name}
+	diff, err := insertGroup(db, &group, services.ChangeSourceMCP)
+	if err != nil {
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to create group")
+	}
+
+	return map[string]interface{}{
+		"group": map[string]interface{}{
+			"id":   group.ID,
+			"name": group.Name,
+		},
+		"diff": diff,
+	}, nil
+}
+
+// toolCreateAPI creates an API at the end of the group with the exact given name.
+// When approval is required, a draft proposing the API is opened instead.
+func toolCreateAPI(db *gorm.DB, cfg *config.Config, args map[string]interface{}) (interface{}, error) {
+	groupName, _ := args["groupName"].(string)
+	if groupName == "" {
+		return nil, mcpToolError(fiber.StatusBadRequest, "groupName is required")
+	}
+
+	var group models.Group
+	if err := db.Where("name = ?", groupName).First(&group).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, mcpToolError(fiber.StatusNotFound, "No group named: "+groupName+"; create it with create_group first")
+		}
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to fetch group")
+	}
+
+	fields := newAPIFields{
+		GroupID:  group.ID,
+		Type:     "HTTP",
+		Protocol: optionalStringArg(args, "protocol"),
+		Note:     optionalStringArg(args, "note"),
+	}
+	fields.Name, _ = args["name"].(string)
+	fields.Endpoint, _ = args["endpoint"].(string)
+	if method, ok := args["method"].(string); ok {
+		fields.Method = strings.ToUpper(method)
+	}
+	if apiType, ok := args["type"].(string); ok && apiType != "" {
+		fields.Type = strings.ToUpper(apiType)
+	}
+
+	api, err := fields.build()
+	if err != nil {
+		return nil, mcpToolError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if cfg.RequireApproval {
+		draft, err := services.ProposeNewAPI(db, api, services.ChangeSourceMCP)
+		if err != nil {
+			return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to create draft")
+		}
+		return mcpDraftResult(db, draft)
+	}
+
+	diff, err := insertAPI(db, &api, services.ChangeSourceMCP)
+	if err != nil {
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to create API")
+	}
+
+	return map[string]interface{}{
+		"api":  api,
+		"diff": diff,
+	}, nil
+}
+
+// toolUpdateAPI updates the basic info of an API. Omitted fields are left unchanged.
+func toolUpdateAPI(db *gorm.DB, cfg *config.Config, args map[string]interface{}) (interface{}, error) {
+	api, err := findToolAPI(db, args)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := apiChanges{
+		Name:     optionalStringArg(args, "name"),
+		Endpoint: optionalStringArg(args, "endpoint"),
+		Method:   optionalStringArg(args, "method"),
+		Type:     optionalStringArg(args, "type"),
+		Protocol: optionalStringArg(args, "protocol"),
+		Note:     optionalStringArg(args, "note"),
+	}
+	if changes == (apiChanges{}) {
+		return nil, mcpToolError(fiber.StatusBadRequest, "At least one of name, endpoint, method, type, protocol or note is required")
+	}
+
+	if cfg.RequireApproval {
+		return mcpDraftEdit(db, api, changes.applyToDraft)
+	}
+
+	before := services.APISnapshot(api)
+	if err := changes.apply(&api); err != nil {
+		return nil, mcpToolError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := db.Save(&api).Error; err != nil {
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to update API")
+	}
+
+	return map[string]interface{}{
+		"api":  api,
+		"diff": publishAPIChange(db, api, before, services.ChangeSourceMCP),
+	}, nil
+}
+
+// toolUpdateAPINote replaces the note of an API. An empty note removes it.
+func toolUpdateAPINote(db *gorm.DB, cfg *config.Config, args map[string]interface{}) (interface{}, error) {
+	text, ok := args["note"].(string)
+	if !ok {
+		return nil, mcpToolError(fiber.StatusBadRequest, "note (string) is required")
+	}
+
+	api, err := findToolAPI(db, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var note *string
+	if text != "" {
+		note = &text
+	}
+
+	if cfg.RequireApproval {
+		return mcpDraftEdit(db, api, func(draft *models.APIDraft) error {
+			draft.Note = note
+			return nil
+		})
+	}
+
+	before := services.APISnapshot(api)
+	api.Note = note
+	if err := db.Save(&api).Error; err != nil {
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to update API note")
+	}
+
+	return map[string]interface{}{
+		"api":  api,
+		"diff": publishAPIChange(db, api, before, services.ChangeSourceMCP),
+	}, nil
+}
+
+// toolSetAPIParametersFromJSON replaces the request or response parameters of an API with
+// the tree derived from an example JSON object, or inferred from several samples. Required
+// flags, descriptions and IDs of existing parameters are kept, like POST /api/apis/:id/parameters/from-json.
+func toolSetAPIParametersFromJSON(db *gorm.DB, cfg *config.Config, args map[string]interface{}) (interface{}, error) {
+	paramType, _ := args["paramType"].(string)
+	if paramType != "request" && paramType != "response" {
+		return nil, mcpToolError(fiber.StatusBadRequest, "paramType must be 'request' or 'response'")
+	}
+
+	var samples []json.RawMessage
+	if rawSamples, ok := args["samples"].([]interface{}); ok {
+		for _, sample := range rawSamples {
+			samples = append(samples, rawJSONArg(sample))
+		}
+	}
+
+	example, err := decodeParameterExample(rawJSONArg(args["json"]), samples)
+	if err != nil {
+		return nil, mcpToolError(fiber.StatusBadRequest, err.Error())
+	}
+
+	api, err := findToolAPI(db, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.RequireApproval {
+		return mcpDraftEdit(db, api, func(draft *models.APIDraft) error {
+			return example.applyToDraft(draft, paramType)
+		})
+	}
+
+	before, err := loadParameterTree(db, api.ID, paramType)
+	if err != nil {
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to fetch existing parameters")
+	}
+
+	nodes := example.nodes(before)
+	services.MatchParameterIDs(nodes, before)
+	if issues := services.ValidateParameterNodes(nodes); len(issues) > 0 {
+		return nil, mcpToolError(fiber.StatusBadRequest, validationIssuesMessage("Invalid parameter tree", issues))
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		_, err := services.SyncParameterTree(tx, api.ID, paramType, nodes)
+		return err
+	})
+	if err != nil {
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to convert JSON to parameters")
+	}
+
+	return map[string]interface{}{
+		"apiId":          api.ID,
+		"paramType":      paramType,
+		"parameterCount": len(nodes),
+		"diff":           publishParametersChange(db, api, paramType, before, services.ChangeSourceMCP),
+	}, nil
+}
+
+// mcpDraftEdit applies an edit of a published API to its open draft, which is opened with
+// author mcp if there is none. The result holds the draft and its diff against the published API.
+func mcpDraftEdit(db *gorm.DB, api models.API, apply func(draft *models.APIDraft) error) (interface{}, error) {
+	draft, err := services.OpenDraft(db, api, services.ChangeSourceMCP)
+	if err != nil {
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to open draft")
+	}
+
+	if err := apply(&draft); err != nil {
+		if errors.Is(err, services.ErrInvalidEventDirection) || errors.Is(err, services.ErrInvalidGraphQLOperation) {
+			return nil, mcpToolError(fiber.StatusBadRequest, err.Error())
+		}
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to update draft")
+	}
+
+	if err := db.Save(&draft).Error; err != nil {
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to update draft")
+	}
+	return mcpDraftResult(db, draft)
+}
+
+// mcpDraftResult returns a draft awaiting approval with its diff against the published API
+func mcpDraftResult(db *gorm.DB, draft models.APIDraft) (interface{}, error) {
+	preview, err := services.PreviewDraft(db, draft)
+	if err != nil {
+		return nil, mcpToolError(fiber.StatusInternalServerError, "Failed to build draft preview")
+	}
+
+	return map[string]interface{}{
+		"pendingApproval": true,
+		"draft":           draft,
+		"diff":            preview.Diff,
+	}, nil
+}
+
+// findToolAPI loads the API of the apiId argument
+func findToolAPI(db *gorm.DB, args map[string]interface{}) (models.API, error) {
+	var api models.API
+
+	apiID, ok := args["apiId"].(float64)
+	if !ok {
+		return api, mcpToolError(fiber.StatusBadRequest, "apiId (number) is required")
+	}
+
+	if err := db.First(&api, uint(apiID)).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return api, mcpToolError(fiber.StatusNotFound, "API not found")
+		}
+		return api, mcpToolError(fiber.StatusInternalServerError, "Failed to fetch API")
+	}
+	return api, nil
+}
+
+// optionalStringArg returns a string argument, or nil if it is missing
+func optionalStringArg(args map[string]interface{}, key string) *string {
+	value, ok := args[key].(string)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+// rawJSONArg returns the JSON text of an argument. Strings are taken as JSON text, which
+// keeps the key order of objects; other values are encoded.
+func rawJSONArg(value interface{}) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	if text, ok := value.(string); ok {
+		return json.RawMessage(text)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// validationIssuesMessage joins validation issues into a single error message
+func validationIssuesMessage(message string, issues []services.ValidationIssue) string {
+	details := make([]string, len(issues))
+	for i, issue := range issues {
+		details[i] = fmt.Sprintf("%s: %s", issue.Path, issue.Message)
+	}
+	return message + ": " + strings.Join(details, "; ")
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/ProjAnvil/knot/backend/internal/config"
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMCPCreateToolsRequireApproval(t *testing.T) {
+	db := newTestDB(t)
+	cfg := &config.Config{MCPWrite: true, RequireApproval: true}
+	group := models.Group{Name: "orders"}
+	if err := db.Create(&group).Error; err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+
+	_, err := CallMCPTool(db, cfg, "create_group", map[string]interface{}{"name": "payments"})
+	if toolErr, ok := err.(*MCPToolError); !ok || toolErr.Status != fiber.StatusForbidden {
+		t.Fatalf("create_group error = %v, want 403", err)
+	}
+
+	result, err := CallMCPTool(db, cfg, "create_api", map[string]interface{}{
+		"groupName": "orders",
+		"name":      "Create order",
+		"endpoint":  "/orders",
+		"method":    "post",
+	})
+	if err != nil {
+		t.Fatalf("create_api: %v", err)
+	}
+	data, _ := result.(map[string]interface{})
+	draft, _ := data["draft"].(models.APIDraft)
+	if data["pendingApproval"] != true || draft.APIID != nil || draft.Method != "POST" || draft.Author != "mcp" {
+		t.Errorf("create_api result = %+v, want a draft proposing the API", result)
+	}
+
+	var groups, apis int64
+	db.Model(&models.Group{}).Count(&groups)
+	db.Model(&models.API{}).Count(&apis)
+	if groups != 1 || apis != 0 {
+		t.Errorf("stored %d groups and %d APIs, want nothing published", groups, apis)
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Mock server request headers
+const (
+	// MockLatencyHeader overrides the latency of a single request, in milliseconds up to services.MockMaxLatency
+	MockLatencyHeader = "X-Mock-Latency"
+	// MockStatusHeader forces the status code of a single response
+	MockStatusHeader = "X-Mock-Status"
+	// MockAPIHeader identifies the documented API that answered a mock request
+	MockAPIHeader = "X-Knot-Mock-API"
+)
+
+// MockServer answers requests with the documented response examples of matching HTTP APIs.
+// The request path is taken from the "*" route parameter. Routes are cached until the
+// change history records a change, so documentation changes are picked up on the next request.
+func MockServer(db *gorm.DB, options services.MockOptions) fiber.Handler {
+	cache := services.NewMockRouteCache()
+
+	return func(c *fiber.Ctx) error {
+		routes, err := cache.Routes(db, options.Group)
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return response.NotFound(c, "Group not found: "+options.Group)
+			}
+			return response.InternalError(c, "Failed to load APIs")
+		}
+
+		path := "/" + c.Params("*")
+		route, pathParams := services.MatchMockRoute(routes, c.Method(), path)
+		if route == nil {
+			return response.NotFound(c, fmt.Sprintf("No documented API matches %s %s", c.Method(), path))
+		}
+
+		latency := options.Latency
+		if raw := c.Get(MockLatencyHeader); raw != "" {
+			ms, err := strconv.Atoi(raw)
+			if err != nil || ms < 0 || int64(ms) > services.MockMaxLatency.Milliseconds() {
+				return response.BadRequest(c, fmt.Sprintf("%s must be a number of milliseconds between 0 and %d",
+					MockLatencyHeader, services.MockMaxLatency.Milliseconds()))
+			}
+			latency = time.Duration(ms) * time.Millisecond
+		}
+		if latency > 0 {
+			time.Sleep(latency)
+		}
+
+		c.Set(MockAPIHeader, strconv.FormatUint(uint64(route.API.ID), 10))
+
+		// Validate the request body against the documented request parameters
+		// first, so forced and injected errors never hide a malformed request
+		if hasRequestBody(c.Method()) && len(route.RequestTree) > 0 {
+			var payload interface{} = map[string]interface{}{}
+			if len(c.Body()) > 0 {
+				if err := json.Unmarshal(c.Body(), &payload); err != nil {
+					return response.BadRequest(c, "Request body is not valid JSON")
+				}
+			}
+
+			if issues := services.ValidatePayload(route.RequestTree, payload); len(issues) > 0 {
+				return response.ValidationFailed(c, "Request body does not match the documented parameters", issues)
+			}
+		}
+
+		status := fiber.StatusOK
+		if raw := c.Get(MockStatusHeader); raw != "" {
+			if forced, err := strconv.Atoi(raw); err == nil && forced >= 100 && forced <= 599 {
+				status = forced
+			}
+		} else if injected, ok := services.InjectMockError(options); ok {
+			status = injected
+		}
+		if status >= 400 {
+			return response.Error(c, status, "Injected error")
+		}
+
+		return c.Status(status).JSON(services.MockResponseBody(route, pathParams))
+	}
+}
+
+// hasRequestBody reports whether requests with the method carry a body
+func hasRequestBody(method string) bool {
+	switch method {
+	case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMockServerLatencyHeader(t *testing.T) {
+	db := newTestDB(t)
+	group := models.Group{Name: "shop"}
+	db.Create(&group)
+	db.Create(&models.API{GroupID: group.ID, Name: "List orders", Endpoint: "/orders", Method: "GET", Type: "HTTP"})
+
+	app := fiber.New()
+	app.All("/mock/*", MockServer(db, services.MockOptions{}))
+
+	tests := []struct {
+		latency string
+		status  int
+	}{
+		{latency: "", status: fiber.StatusOK},
+		{latency: "5", status: fiber.StatusOK},
+		{latency: "-1", status: fiber.StatusBadRequest},
+		{latency: "soon", status: fiber.StatusBadRequest},
+		{latency: "999999999", status: fiber.StatusBadRequest},
+		{latency: "99999999999999999", status: fiber.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/mock/orders", nil)
+		if tt.latency != "" {
+			req.Header.Set(MockLatencyHeader, tt.latency)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s=%q: %v", MockLatencyHeader, tt.latency, err)
+		}
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s=%q: status = %d, want %d", MockLatencyHeader, tt.latency, resp.StatusCode, tt.status)
+		}
+	}
+}
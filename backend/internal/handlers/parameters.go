@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/ProjAnvil/knot/backend/internal/config"
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// AddParameter adds one parameter, with its children, to the request or response tree of an API.
+// Body: paramType, parentId (omitted for the root), position and parameter.
+func AddParameter(db *gorm.DB, cfg *config.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body struct {
+			ParamType string          `json:"paramType"`
+			ParentID  *uint           `json:"parentId"`
+			Position  *int            `json:"position"`
+			Parameter json.RawMessage `json:"parameter"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		if body.ParamType != "request" && body.ParamType != "response" {
+			return response.BadRequest(c, "Invalid paramType. Must be 'request' or 'response'")
+		}
+
+		api, err := findAPI(db, c.Params("id"))
+		if err != nil {
+			return apiLookupError(c, err)
+		}
+
+		parentID := uint(0)
+		if body.ParentID != nil {
+			parentID = *body.ParentID
+		}
+
+		return editParameters(c, db, cfg, api, body.ParamType, []services.ParameterOperation{{
+			Op:       services.ParameterOpAdd,
+			ParentID: &parentID,
+			Position: body.Position,
+			Value:    body.Parameter,
+		}}, true)
+	}
+}
+
+// UpdateParameter changes the fields of one parameter; its ID and children are kept
+func UpdateParameter(db *gorm.DB, cfg *config.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		param, api, err := findParameter(db, c.Params("id"))
+		if err != nil {
+			return parameterLookupError(c, err)
+		}
+
+		var fields services.ParameterFields
+		if err := c.BodyParser(&fields); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		value, err := json.Marshal(fields)
+		if err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		return editParameters(c, db, cfg, api, param.ParamType, []services.ParameterOperation{{
+			Op:    services.ParameterOpReplace,
+			ID:    param.ID,
+			Value: value,
+		}}, true)
+	}
+}
+
+// MoveParameter moves one parameter, with its children, to another parent or position.
+// Body: parentId (0 or omitted for the root) and position (last if omitted).
+func MoveParameter(db *gorm.DB, cfg *config.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		param, api, err := findParameter(db, c.Params("id"))
+		if err != nil {
+			return parameterLookupError(c, err)
+		}
+
+		var body struct {
+			ParentID *uint `json:"parentId"`
+			Position *int  `json:"position"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		parentID := uint(0)
+		if body.ParentID != nil {
+			parentID = *body.ParentID
+		}
+
+		return editParameters(c, db, cfg, api, param.ParamType, []services.ParameterOperation{{
+			Op:       services.ParameterOpMove,
+			ID:       param.ID,
+			ParentID: &parentID,
+			Position: body.Position,
+		}}, true)
+	}
+}
+
+// DeleteParameter deletes one parameter and its children
+func DeleteParameter(db *gorm.DB, cfg *config.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		param, api, err := findParameter(db, c.Params("id"))
+		if err != nil {
+			return parameterLookupError(c, err)
+		}
+
+		return editParameters(c, db, cfg, api, param.ParamType, []services.ParameterOperation{{
+			Op: services.ParameterOpRemove,
+			ID: param.ID,
+		}}, false)
+	}
+}
+
+// PatchParameters applies a batch of JSON Patch style operations to the request or
+// response tree of an API. Either every operation applies or none does.
+func PatchParameters(db *gorm.DB, cfg *config.Config) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body struct {
+			ParamType  string                        `json:"paramType"`
+			Operations []services.ParameterOperation `json:"operations"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		if body.ParamType != "request" && body.ParamType != "response" {
+			return response.BadRequest(c, "Invalid paramType. Must be 'request' or 'response'")
+		}
+
+		if len(body.Operations) == 0 {
+			return response.BadRequest(c, "Operations are required")
+		}
+
+		api, err := findAPI(db, c.Params("id"))
+		if err != nil {
+			return apiLookupError(c, err)
+		}
+
+		return editParameters(c, db, cfg, api, body.ParamType, body.Operations, false)
+	}
+}
+
+// PatchDraftParameters applies a batch of JSON Patch style operations to the proposed tree of an open draft
+func PatchDraftParameters(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body struct {
+			ParamType  string                        `json:"paramType"`
+			Operations []services.ParameterOperation `json:"operations"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		if body.ParamType != "request" && body.ParamType != "response" {
+			return response.BadRequest(c, "Invalid paramType. Must be 'request' or 'response'")
+		}
+
+		if len(body.Operations) == 0 {
+			return response.BadRequest(c, "Operations are required")
+		}
+
+		draft, err := findDraft(db, c.Params("id"))
+		if err != nil {
+			return draftLookupError(c, err)
+		}
+
+		if draft.Status != models.DraftStatusOpen {
+			return response.Error(c, fiber.StatusConflict, "Draft is not open")
+		}
+
+		if err := patchDraftParameters(&draft, body.ParamType, body.Operations); err != nil {
+			if editErr, ok := err.(*services.ParameterEditError); ok {
+				return response.ValidationFailed(c, "Invalid parameter operations", editErr.Issues)
+			}
+			return response.InternalError(c, "Failed to update draft")
+		}
+
+		if err := db.Save(&draft).Error; err != nil {
+			return response.InternalError(c, "Failed to update draft")
+		}
+
+		return response.Success(c, draft)
+	}
+}
+
+// editParameters applies operations to the published tree of an API, or to its open draft
+// when approval is required. Published edits keep the IDs of untouched and edited parameters.
+// The response holds the updated tree and, with single, the parameter the operation affected.
+func editParameters(c *fiber.Ctx, db *gorm.DB, cfg *config.Config, api models.API, paramType string, ops []services.ParameterOperation, single bool) error {
+	if cfg.RequireApproval {
+		return collectIntoDraft(c, db, api, func(draft *models.APIDraft) error {
+			return patchDraftParameters(draft, paramType, ops)
+		})
+	}
+
+	before, err := loadParameterTree(db, api.ID, paramType)
+	if err != nil {
+		return response.InternalError(c, "Failed to fetch existing parameters")
+	}
+
+	nodes, paths, err := services.ApplyParameterOperations(services.NodesFromParameters(before), ops)
+	if err != nil {
+		if editErr, ok := err.(*services.ParameterEditError); ok {
+			return response.ValidationFailed(c, "Invalid parameter operations", editErr.Issues)
+		}
+		return response.InternalError(c, "Failed to apply parameter operations")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		_, err := services.SyncParameterTree(tx, api.ID, paramType, nodes)
+		return err
+	})
+	if err != nil {
+		return response.InternalError(c, "Failed to update parameters")
+	}
+
+	publishParametersChange(db, api, paramType, before, services.ChangeSourceAPI)
+
+	after, err := loadParameterTree(db, api.ID, paramType)
+	if err != nil {
+		return response.InternalError(c, "Failed to fetch parameters")
+	}
+
+	result := fiber.Map{"parameters": after}
+	if single && paths[0] != "" {
+		result["parameter"] = services.FindParameterNode(nodes, paths[0])
+	}
+	return response.Success(c, result)
+}
+
+// patchDraftParameters applies operations to the proposed tree of a draft
+func patchDraftParameters(draft *models.APIDraft, paramType string, ops []services.ParameterOperation) error {
+	current, err := services.DraftParameterNodes(*draft, paramType)
+	if err != nil {
+		return err
+	}
+
+	nodes, _, err := services.ApplyParameterOperations(current, ops)
+	if err != nil {
+		return err
+	}
+	return services.SetDraftParameterNodes(draft, paramType, nodes)
+}
+
+// findAPI loads an API by its ID route parameter
+func findAPI(db *gorm.DB, rawID string) (models.API, error) {
+	var api models.API
+
+	id, err := strconv.ParseUint(rawID, 10, 32)
+	if err != nil {
+		return api, errInvalidID
+	}
+
+	err = db.First(&api, id).Error
+	return api, err
+}
+
+// apiLookupError maps a findAPI error to a response
+func apiLookupError(c *fiber.Ctx, err error) error {
+	switch err {
+	case errInvalidID:
+		return response.BadRequest(c, "Invalid API ID")
+	case gorm.ErrRecordNotFound:
+		return response.NotFound(c, "API not found")
+	default:
+		return response.InternalError(c, "Failed to fetch API")
+	}
+}
+
+// findParameter loads a parameter and its API by the parameter ID route parameter
+func findParameter(db *gorm.DB, rawID string) (models.Parameter, models.API, error) {
+	var param models.Parameter
+	var api models.API
+
+	id, err := strconv.ParseUint(rawID, 10, 32)
+	if err != nil {
+		return param, api, errInvalidID
+	}
+
+	if err := db.First(&param, id).Error; err != nil {
+		return param, api, err
+	}
+	err = db.First(&api, param.APIID).Error
+	return param, api, err
+}
+
+// parameterLookupError maps a findParameter error to a response
+func parameterLookupError(c *fiber.Ctx, err error) error {
+	switch err {
+	case errInvalidID:
+		return response.BadRequest(c, "Invalid parameter ID")
+	case gorm.ErrRecordNotFound:
+		return response.NotFound(c, "Parameter not found")
+	default:
+		return response.InternalError(c, "Failed to fetch parameter")
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetAPISnippets returns ready-to-run calls of an API.
+// Query: lang (comma-separated, all languages if empty), environment and baseUrl.
+func GetAPISnippets(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		api, err := findAPI(db, c.Params("id"))
+		if err != nil {
+			return apiLookupError(c, err)
+		}
+
+		input := services.ExecuteInput{BaseURL: c.Query("baseUrl")}
+		if environment := c.Query("environment"); environment != "" {
+			env, err := services.LoadEnvironment(db, environment, api.GroupID)
+			if err != nil {
+				return environmentLookupError(c, err)
+			}
+			input.Env = env
+		}
+
+		var languages []string
+		for _, lang := range strings.Split(c.Query("lang"), ",") {
+			if lang = strings.TrimSpace(lang); lang != "" {
+				languages = append(languages, lang)
+			}
+		}
+
+		requestTree, err := loadParameterTree(db, api.ID, "request")
+		if err != nil {
+			return response.InternalError(c, "Failed to fetch parameters")
+		}
+
+		snippets, err := services.GenerateSnippets(api, requestTree, input, languages)
+		if err == services.ErrUnsupportedLanguage {
+			return response.BadRequest(c, "Invalid lang. Must be one of: "+strings.Join(services.SnippetLanguages, ", "))
+		}
+		if err != nil {
+			return response.BadRequest(c, err.Error())
+		}
+
+		return response.Success(c, snippets)
+	}
+}
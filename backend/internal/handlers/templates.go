@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetTemplates lists the built-in export template and the uploaded ones
+func GetTemplates() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		templates, err := services.ListExportTemplates()
+		if err != nil {
+			return response.InternalError(c, "Failed to list templates")
+		}
+		return response.Success(c, templates)
+	}
+}
+
+// GetTemplate returns the source of an export template
+func GetTemplate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		name := c.Params("name")
+
+		source, err := services.LoadExportTemplate(name)
+		if err != nil {
+			return templateError(c, err)
+		}
+
+		return response.Success(c, fiber.Map{
+			"name":    name,
+			"builtIn": name == services.DefaultTemplateName,
+			"content": source,
+		})
+	}
+}
+
+// SaveTemplate creates or replaces an uploaded export template.
+// The name carries the extension of the generated file, e.g. wiki.md.
+func SaveTemplate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body struct {
+			Content string `json:"content"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		if body.Content == "" {
+			return response.BadRequest(c, "Content is required")
+		}
+
+		name := c.Params("name")
+		if _, err := services.ParseExportTemplate(name, body.Content); err != nil {
+			return response.BadRequest(c, "Invalid template: "+err.Error())
+		}
+
+		if err := services.SaveExportTemplate(name, body.Content); err != nil {
+			return templateError(c, err)
+		}
+
+		return response.Success(c, fiber.Map{"name": name})
+	}
+}
+
+// DeleteTemplate deletes an uploaded export template
+func DeleteTemplate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := services.DeleteExportTemplate(c.Params("name")); err != nil {
+			return templateError(c, err)
+		}
+		return response.Success(c, nil)
+	}
+}
+
+// templateError maps an error of the template store to a response
+func templateError(c *fiber.Ctx, err error) error {
+	switch err {
+	case services.ErrTemplateNotFound:
+		return response.NotFound(c, "Template not found")
+	case services.ErrInvalidTemplateName:
+		return response.BadRequest(c, "Invalid template name. Use a file name with an extension, e.g. wiki.md")
+	case services.ErrTemplateReadOnly:
+		return response.Error(c, fiber.StatusConflict, "The built-in template cannot be changed")
+	}
+	return response.InternalError(c, "Failed to access template")
+}
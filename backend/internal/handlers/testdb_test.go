@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProjAnvil/knot/backend/internal/config"
+	"github.com/ProjAnvil/knot/backend/internal/database"
+	"gorm.io/gorm"
+)
+
+// newTestDB opens a migrated SQLite database in a temporary directory
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	database.Output = io.Discard
+	db, err := database.InitDatabase(&config.Config{
+		DatabaseType: "sqlite",
+		SQLitePath:   filepath.Join(t.TempDir(), "knot.db"),
+	})
+	if err != nil {
+		t.Fatalf("InitDatabase: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ValidateAPIPayload checks a captured request or response payload against the
+// documented parameters of an API
+func ValidateAPIPayload(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return response.BadRequest(c, "Invalid API ID")
+		}
+
+		var body struct {
+			ParamType string          `json:"paramType"`
+			Payload   json.RawMessage `json:"payload"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		if body.ParamType != "request" && body.ParamType != "response" {
+			return response.BadRequest(c, "Invalid paramType. Must be 'request' or 'response'")
+		}
+
+		if len(body.Payload) == 0 {
+			return response.BadRequest(c, "payload is required")
+		}
+
+		var api models.API
+		if err := db.First(&api, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return response.NotFound(c, "API not found")
+			}
+			return response.InternalError(c, "Failed to fetch API")
+		}
+
+		tree, err := loadParameterTree(db, api.ID, body.ParamType)
+		if err != nil {
+			return response.InternalError(c, "Failed to fetch parameters")
+		}
+
+		issues := services.ValidateJSON(tree, body.Payload)
+
+		return response.Success(c, fiber.Map{
+			"apiId":     api.ID,
+			"paramType": body.ParamType,
+			"valid":     len(issues) == 0,
+			"issues":    issues,
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// GetWebhooks returns all webhook subscriptions
+func GetWebhooks(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var hooks []models.Webhook
+		if err := db.Order("id ASC").Find(&hooks).Error; err != nil {
+			return response.InternalError(c, "Failed to fetch webhooks")
+		}
+
+		if hooks == nil {
+			hooks = []models.Webhook{}
+		}
+
+		return response.Success(c, hooks)
+	}
+}
+
+// CreateWebhook creates a new webhook subscription
+func CreateWebhook(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var body struct {
+			Name    string   `json:"name"`
+			URL     string   `json:"url"`
+			Events  []string `json:"events"`
+			GroupID *uint    `json:"groupId"`
+			Secret  string   `json:"secret"`
+			Active  *bool    `json:"active"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		if message := validateWebhookURL(body.URL); message != "" {
+			return response.BadRequest(c, message)
+		}
+		if message := validateWebhookEvents(body.Events); message != "" {
+			return response.BadRequest(c, message)
+		}
+
+		hook := models.Webhook{
+			Name:    body.Name,
+			URL:     body.URL,
+			Events:  strings.Join(body.Events, ","),
+			GroupID: normalizeGroupFilter(body.GroupID),
+			Secret:  body.Secret,
+			Active:  body.Active == nil || *body.Active,
+		}
+
+		if err := db.Create(&hook).Error; err != nil {
+			return response.InternalError(c, "Failed to create webhook")
+		}
+
+		return response.Success(c, hook)
+	}
+}
+
+// UpdateWebhook updates a webhook subscription.
+// A groupId of 0 removes the group filter, an empty secret disables signing.
+func UpdateWebhook(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return response.BadRequest(c, "Invalid webhook ID")
+		}
+
+		var body struct {
+			Name    *string  `json:"name"`
+			URL     *string  `json:"url"`
+			Events  []string `json:"events"`
+			GroupID *uint    `json:"groupId"`
+			Secret  *string  `json:"secret"`
+			Active  *bool    `json:"active"`
+		}
+
+		if err := c.BodyParser(&body); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+
+		var hook models.Webhook
+		if err := db.First(&hook, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return response.NotFound(c, "Webhook not found")
+			}
+			return response.InternalError(c, "Failed to fetch webhook")
+		}
+
+		if body.Name != nil {
+			hook.Name = *body.Name
+		}
+		if body.URL != nil {
+			if message := validateWebhookURL(*body.URL); message != "" {
+				return response.BadRequest(c, message)
+			}
+			hook.URL = *body.URL
+		}
+		if body.Events != nil {
+			if message := validateWebhookEvents(body.Events); message != "" {
+				return response.BadRequest(c, message)
+			}
+			hook.Events = strings.Join(body.Events, ",")
+		}
+		if body.GroupID != nil {
+			hook.GroupID = normalizeGroupFilter(body.GroupID)
+		}
+		if body.Secret != nil {
+			hook.Secret = *body.Secret
+		}
+		if body.Active != nil {
+			hook.Active = *body.Active
+		}
+
+		if err := db.Save(&hook).Error; err != nil {
+			return response.InternalError(c, "Failed to update webhook")
+		}
+
+		return response.Success(c, hook)
+	}
+}
+
+// DeleteWebhook deletes a webhook subscription and its delivery log
+func DeleteWebhook(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return response.BadRequest(c, "Invalid webhook ID")
+		}
+
+		var rowsAffected int64
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("webhook_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+				return err
+			}
+			result := tx.Delete(&models.Webhook{}, id)
+			rowsAffected = result.RowsAffected
+			return result.Error
+		})
+
+		if err != nil {
+			return response.InternalError(c, "Failed to delete webhook")
+		}
+
+		if rowsAffected == 0 {
+			return response.NotFound(c, "Webhook not found")
+		}
+
+		return response.Success(c, nil)
+	}
+}
+
+// GetWebhookDeliveries returns the most recent delivery attempts of a webhook
+func GetWebhookDeliveries(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return response.BadRequest(c, "Invalid webhook ID")
+		}
+
+		limit := c.QueryInt("limit", 50)
+		if limit <= 0 || limit > 500 {
+			limit = 50
+		}
+
+		var deliveries []models.WebhookDelivery
+		if err := db.Where("webhook_id = ?", id).Order("id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+			return response.InternalError(c, "Failed to fetch webhook deliveries")
+		}
+
+		if deliveries == nil {
+			deliveries = []models.WebhookDelivery{}
+		}
+
+		return response.Success(c, deliveries)
+	}
+}
+
+// TestWebhook sends a single ping event to a webhook and returns the recorded attempt
+func TestWebhook(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+		if err != nil {
+			return response.BadRequest(c, "Invalid webhook ID")
+		}
+
+		var hook models.Webhook
+		if err := db.First(&hook, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return response.NotFound(c, "Webhook not found")
+			}
+			return response.InternalError(c, "Failed to fetch webhook")
+		}
+
+		payload, _ := json.Marshal(fiber.Map{
+			"event":     "ping",
+			"webhookId": hook.ID,
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+
+		delivery, _ := services.SendWebhook(hook, services.NewWebhookDeliveryID(), "ping", payload, 1)
+		if err := db.Create(&delivery).Error; err != nil {
+			return response.InternalError(c, "Failed to record webhook delivery")
+		}
+
+		return response.Success(c, delivery)
+	}
+}
+
+// validateWebhookURL returns an error message if the URL is not an absolute http(s) URL
+func validateWebhookURL(rawURL string) string {
+	if rawURL == "" {
+		return "Webhook URL is required"
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return "Webhook URL must be an absolute http or https URL"
+	}
+
+	return ""
+}
+
+// validateWebhookEvents returns an error message if any event type is unknown
+func validateWebhookEvents(events []string) string {
+	for _, event := range events {
+		if event != "*" && !services.IsChangeEventType(event) {
+			return "Unknown event type: " + event
+		}
+	}
+	return ""
+}
+
+// normalizeGroupFilter treats a zero group ID as "all groups"
+func normalizeGroupFilter(groupID *uint) *uint {
+	if groupID == nil || *groupID == 0 {
+		return nil
+	}
+	return groupID
+}
@@ -0,0 +1,48 @@
+package mcpserver
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Paths of the MCP endpoints in the Fiber app
+const (
+	StreamableHTTPPath = "/mcp"
+	SSEPath            = "/mcp/sse"
+	SSEMessagePath     = "/mcp/message"
+)
+
+// sseKeepAlive is the interval of pings on SSE streams, which also detect closed connections
+const sseKeepAlive = 30 * time.Second
+
+// HTTPTransports are the Fiber handlers serving an MCP server over HTTP
+type HTTPTransports struct {
+	StreamableHTTP fiber.Handler // Streamable HTTP transport, for every method of StreamableHTTPPath
+	SSE            fiber.Handler // Event stream of the SSE transport, for GET SSEPath
+	SSEMessage     fiber.Handler // Messages of SSE clients, for POST SSEMessagePath
+}
+
+// NewHTTPTransports serves mcpServer over the Streamable HTTP transport and the older SSE
+// transport, for clients that do not support Streamable HTTP yet
+func NewHTTPTransports(mcpServer *server.MCPServer) HTTPTransports {
+	streamable := server.NewStreamableHTTPServer(mcpServer,
+		server.WithEndpointPath(StreamableHTTPPath),
+		server.WithHeartbeatInterval(sseKeepAlive),
+	)
+	sse := server.NewSSEServer(mcpServer,
+		server.WithStaticBasePath(StreamableHTTPPath),
+		server.WithSSEEndpoint("/sse"),
+		server.WithMessageEndpoint("/message"),
+		server.WithUseFullURLForMessageEndpoint(false),
+		server.WithKeepAliveInterval(sseKeepAlive),
+	)
+
+	return HTTPTransports{
+		StreamableHTTP: adaptor.HTTPHandler(streamable),
+		SSE:            adaptor.HTTPHandler(sse.SSEHandler()),
+		SSEMessage:     adaptor.HTTPHandler(sse.MessageHandler()),
+	}
+}
@@ -0,0 +1,465 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/ProjAnvil/knot/backend/pkg/logger"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// URIs of the resources. Every group and API is listed as a resource; the templates
+// also cover the schema and example of an API.
+const (
+	groupsURI             = "knot://groups"
+	groupURIPrefix        = "knot://group/"
+	apiURIPrefix          = "knot://api/"
+	groupURITemplate      = "knot://group/{name}"
+	apiURITemplate        = "knot://api/{id}"
+	apiSchemaURITemplate  = "knot://api/{id}/schema"
+	apiExampleURITemplate = "knot://api/{id}/example"
+)
+
+// MIME types of the resource renderings
+const (
+	markdownMIMEType   = "text/markdown"
+	jsonMIMEType       = "application/json"
+	jsonSchemaMIMEType = "application/schema+json"
+)
+
+// resourcePageSize is the number of resources, templates, tools and prompts per list page
+const resourcePageSize = 50
+
+// changeWatchInterval is how often the change history is checked for edits. Reading the
+// history instead of hooking into this process also catches edits made by other Knot processes.
+const changeWatchInterval = 2 * time.Second
+
+// addResourceTemplates registers the templates of the group and API resources
+func addResourceTemplates(mcpServer *server.MCPServer, db *gorm.DB) {
+	handler := func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return readResource(db, request.Params.URI)
+	}
+
+	mcpServer.AddResourceTemplates(
+		server.ServerResourceTemplate{
+			Template: mcp.NewResourceTemplate(groupURITemplate, "API group",
+				mcp.WithTemplateDescription("Markdown documentation of every API in a group, by exact group name"),
+				mcp.WithTemplateMIMEType(markdownMIMEType),
+			),
+			Handler: handler,
+		},
+		server.ServerResourceTemplate{
+			Template: mcp.NewResourceTemplate(apiURITemplate, "API",
+				mcp.WithTemplateDescription("Markdown documentation of an API: endpoint, note, parameters and examples"),
+				mcp.WithTemplateMIMEType(markdownMIMEType),
+			),
+			Handler: handler,
+		},
+		server.ServerResourceTemplate{
+			Template: mcp.NewResourceTemplate(apiSchemaURITemplate, "API schema",
+				mcp.WithTemplateDescription("JSON Schemas of the request and response of an API"),
+				mcp.WithTemplateMIMEType(jsonSchemaMIMEType),
+			),
+			Handler: handler,
+		},
+		server.ServerResourceTemplate{
+			Template: mcp.NewResourceTemplate(apiExampleURITemplate, "API example",
+				mcp.WithTemplateDescription("Example JSON of the request and response of an API"),
+				mcp.WithTemplateMIMEType(jsonMIMEType),
+			),
+			Handler: handler,
+		},
+	)
+}
+
+// readResource renders the group or API resource a URI points to
+func readResource(db *gorm.DB, uri string) ([]mcp.ResourceContents, error) {
+	if name, ok := strings.CutPrefix(uri, groupURIPrefix); ok {
+		groupName, err := url.PathUnescape(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid group name in %s", uri)
+		}
+		return readGroupResource(db, uri, groupName)
+	}
+
+	if rest, ok := strings.CutPrefix(uri, apiURIPrefix); ok {
+		rawID, view, _ := strings.Cut(rest, "/")
+		apiID, err := strconv.ParseUint(rawID, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid API ID in %s", uri)
+		}
+		return readAPIResource(db, uri, uint(apiID), view)
+	}
+
+	return nil, fmt.Errorf("unknown resource: %s", uri)
+}
+
+// readGroupResource renders the Markdown documentation of a group
+func readGroupResource(db *gorm.DB, uri, name string) ([]mcp.ResourceContents, error) {
+	var group models.Group
+	if err := db.Where("name = ?", name).First(&group).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("group not found: %s", name)
+		}
+		return nil, fmt.Errorf("failed to fetch group: %w", err)
+	}
+
+	apiIDs := []uint{}
+	if err := db.Model(&models.API{}).Where("group_id = ?", group.ID).Pluck("id", &apiIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch APIs: %w", err)
+	}
+
+	apis, err := services.LoadExportAPIs(db, apiIDs, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: markdownMIMEType,
+			Text:     services.GenerateGroupMarkdown(group.Name, apis, "en"),
+		},
+	}, nil
+}
+
+// readAPIResource renders an API as Markdown documentation, or its schema or example as JSON
+func readAPIResource(db *gorm.DB, uri string, apiID uint, view string) ([]mcp.ResourceContents, error) {
+	apis, err := services.LoadExportAPIs(db, []uint{apiID}, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(apis) == 0 {
+		return nil, fmt.Errorf("API not found: %d", apiID)
+	}
+	api := apis[0]
+
+	requestTree := services.BuildParameterTree(api.RequestParameters)
+	responseTree := services.BuildParameterTree(api.ResponseParameters)
+
+	var mimeType string
+	var rendering interface{}
+	switch view {
+	case "":
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      uri,
+				MIMEType: markdownMIMEType,
+				Text:     services.GenerateAPIMarkdown(api, "en"),
+			},
+		}, nil
+	case "schema":
+		mimeType = jsonSchemaMIMEType
+		rendering = map[string]interface{}{
+			"request":  services.GenerateJSONSchema(api.API, "request", requestTree),
+			"response": services.GenerateJSONSchema(api.API, "response", responseTree),
+		}
+	case "example":
+		mimeType = jsonMIMEType
+		var requestExample, responseExample interface{}
+		if len(requestTree) > 0 {
+			requestExample = services.GenerateExampleJSON(requestTree)
+		}
+		if len(responseTree) > 0 {
+			responseExample = services.GenerateExampleJSON(responseTree)
+		}
+		rendering = map[string]interface{}{
+			"request":  requestExample,
+			"response": responseExample,
+		}
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", uri)
+	}
+
+	text, err := json.MarshalIndent(rendering, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format resource: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: mimeType,
+			Text:     string(text),
+		},
+	}, nil
+}
+
+// groupURI returns the resource URI of a group
+func groupURI(name string) string {
+	return groupURIPrefix + url.PathEscape(name)
+}
+
+// apiURI returns the resource URI of an API, or of its schema or example with a view
+func apiURI(apiID uint, view string) string {
+	uri := apiURIPrefix + strconv.FormatUint(uint64(apiID), 10)
+	if view != "" {
+		uri += "/" + view
+	}
+	return uri
+}
+
+// resourceWatcher keeps the listed group and API resources in sync with the database and
+// sends resources/updated notifications for the resources of edited groups and APIs
+type resourceWatcher struct {
+	db         *gorm.DB
+	mcpServer  *server.MCPServer
+	read       server.ResourceHandlerFunc
+	listed     map[string]mcp.Resource
+	groupURIs  map[uint]string // URIs of the listed groups by group ID
+	apiGroups  map[uint]uint   // Group IDs of the listed APIs by API ID
+	lastChange uint
+}
+
+// watchResources lists every group and API as a resource and keeps the list up to date
+// until ctx is done
+func watchResources(ctx context.Context, mcpServer *server.MCPServer, db *gorm.DB) {
+	watcher := newResourceWatcher(mcpServer, db)
+
+	latest, err := services.LoadChangeHistory(db, services.ChangeHistoryFilter{Limit: 1})
+	if err == nil && len(latest) > 0 {
+		watcher.lastChange = latest[0].ID
+	}
+	if err := watcher.listAll(); err != nil {
+		logger.Log.Error("Failed to list MCP resources", zap.Error(err))
+	}
+
+	ticker := time.NewTicker(changeWatchInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := watcher.poll(); err != nil {
+					logger.Log.Error("Failed to watch MCP resources", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// newResourceWatcher creates a watcher that has not listed any resources yet
+func newResourceWatcher(mcpServer *server.MCPServer, db *gorm.DB) *resourceWatcher {
+	return &resourceWatcher{
+		db:        db,
+		mcpServer: mcpServer,
+		read: func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return readResource(db, request.Params.URI)
+		},
+		listed:    map[string]mcp.Resource{},
+		groupURIs: map[uint]string{},
+		apiGroups: map[uint]uint{},
+	}
+}
+
+// poll handles the changes recorded since the last poll
+func (w *resourceWatcher) poll() error {
+	changes, err := services.LoadChangeHistory(w.db, services.ChangeHistoryFilter{AfterID: w.lastChange})
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+
+	if err := w.syncChanges(changes); err != nil {
+		return err
+	}
+	w.lastChange = changes[0].ID
+
+	// Changes are newest first; notify in the order they happened
+	notified := map[string]bool{}
+	for i := len(changes) - 1; i >= 0; i-- {
+		for _, uri := range w.updatedURIs(changes[i]) {
+			if notified[uri] {
+				continue
+			}
+			notified[uri] = true
+			w.mcpServer.SendNotificationToAllClients(mcp.MethodNotificationResourceUpdated, map[string]interface{}{"uri": uri})
+		}
+	}
+	return nil
+}
+
+// updatedURIs returns the resources a change affects. Groups are named by their current
+// name and, after a rename or deletion, their previous one.
+func (w *resourceWatcher) updatedURIs(change models.ChangeRecord) []string {
+	var uris []string
+	if change.APIID != nil {
+		uris = append(uris, apiURI(*change.APIID, ""), apiURI(*change.APIID, "schema"), apiURI(*change.APIID, "example"))
+	}
+
+	var group models.Group
+	if err := w.db.First(&group, change.GroupID).Error; err == nil {
+		uris = append(uris, groupURI(group.Name))
+	}
+
+	if strings.HasPrefix(change.EventType, "group.") {
+		var diff []services.FieldChange
+		_ = json.Unmarshal([]byte(change.Diff), &diff)
+		for _, field := range diff {
+			if name, ok := field.Before.(string); ok && field.Path == "name" {
+				uris = append(uris, groupURI(name))
+			}
+		}
+		uris = append(uris, groupsURI)
+	}
+	return uris
+}
+
+// listAll lists a resource for every group and API
+func (w *resourceWatcher) listAll() error {
+	var groups []models.Group
+	if err := w.db.Order("`order` ASC").Find(&groups).Error; err != nil {
+		return fmt.Errorf("failed to fetch groups: %w", err)
+	}
+	var apis []models.API
+	if err := w.db.Order("`order` ASC").Find(&apis).Error; err != nil {
+		return fmt.Errorf("failed to fetch APIs: %w", err)
+	}
+
+	apiCounts := make(map[uint]int, len(groups))
+	for _, api := range apis {
+		apiCounts[api.GroupID]++
+	}
+
+	w.update(groups, apis, apiCounts, nil, nil)
+	return nil
+}
+
+// syncChanges updates the resources of the groups and APIs named in changes, and of the
+// APIs of changed groups, whose resource names include the group name
+func (w *resourceWatcher) syncChanges(changes []models.ChangeRecord) error {
+	groupIDs := map[uint]bool{}
+	apiIDs := map[uint]bool{}
+	for _, change := range changes {
+		groupIDs[change.GroupID] = true
+		if change.APIID != nil {
+			apiIDs[*change.APIID] = true
+		}
+		if strings.HasPrefix(change.EventType, "group.") {
+			for apiID, groupID := range w.apiGroups {
+				if groupID == change.GroupID {
+					apiIDs[apiID] = true
+				}
+			}
+		}
+	}
+
+	var apis []models.API
+	if err := w.db.Where("id IN ?", idList(apiIDs)).Find(&apis).Error; err != nil {
+		return fmt.Errorf("failed to fetch APIs: %w", err)
+	}
+	for _, api := range apis {
+		groupIDs[api.GroupID] = true
+	}
+
+	var groups []models.Group
+	if err := w.db.Where("id IN ?", idList(groupIDs)).Find(&groups).Error; err != nil {
+		return fmt.Errorf("failed to fetch groups: %w", err)
+	}
+
+	var counts []struct {
+		GroupID uint
+		Count   int
+	}
+	if err := w.db.Model(&models.API{}).Select("group_id, COUNT(*) AS count").
+		Where("group_id IN ?", idList(groupIDs)).Group("group_id").Scan(&counts).Error; err != nil {
+		return fmt.Errorf("failed to count APIs: %w", err)
+	}
+	apiCounts := make(map[uint]int, len(counts))
+	for _, count := range counts {
+		apiCounts[count.GroupID] = count.Count
+	}
+
+	w.update(groups, apis, apiCounts, idList(groupIDs), idList(apiIDs))
+	return nil
+}
+
+// update lists the resources of groups and apis, and removes the resources of the groups
+// and APIs of checkedGroups and checkedAPIs that no longer exist
+func (w *resourceWatcher) update(groups []models.Group, apis []models.API, apiCounts map[uint]int, checkedGroups, checkedAPIs []uint) {
+	groupNames := make(map[uint]string, len(groups))
+	for _, group := range groups {
+		groupNames[group.ID] = group.Name
+	}
+	existingAPIs := make(map[uint]bool, len(apis))
+	for _, api := range apis {
+		existingAPIs[api.ID] = true
+	}
+
+	// Deleted groups and APIs, and the previous names of renamed groups
+	var stale []string
+	remove := func(uri string) {
+		if _, ok := w.listed[uri]; ok {
+			stale = append(stale, uri)
+			delete(w.listed, uri)
+		}
+	}
+	for _, groupID := range checkedGroups {
+		uri, ok := w.groupURIs[groupID]
+		if name, exists := groupNames[groupID]; ok && (!exists || groupURI(name) != uri) {
+			remove(uri)
+			delete(w.groupURIs, groupID)
+		}
+	}
+	for _, apiID := range checkedAPIs {
+		if !existingAPIs[apiID] {
+			remove(apiURI(apiID, ""))
+			delete(w.apiGroups, apiID)
+		}
+	}
+
+	var added []server.ServerResource
+	put := func(resource mcp.Resource) {
+		if listed, ok := w.listed[resource.URI]; !ok || listed != resource {
+			added = append(added, server.ServerResource{Resource: resource, Handler: w.read})
+			w.listed[resource.URI] = resource
+		}
+	}
+	for _, group := range groups {
+		uri := groupURI(group.Name)
+		w.groupURIs[group.ID] = uri
+		put(mcp.Resource{
+			URI:         uri,
+			Name:        group.Name,
+			Description: fmt.Sprintf("Documentation of the %d APIs in group %s", apiCounts[group.ID], group.Name),
+			MIMEType:    markdownMIMEType,
+		})
+	}
+	for _, api := range apis {
+		w.apiGroups[api.ID] = api.GroupID
+		// Names are unique, as list pages continue after the last name
+		put(mcp.Resource{
+			URI:         apiURI(api.ID, ""),
+			Name:        fmt.Sprintf("%s / %s #%d", groupNames[api.GroupID], api.Name, api.ID),
+			Description: strings.TrimSpace(fmt.Sprintf("%s %s (%s)", api.Method, api.Endpoint, api.Type)),
+			MIMEType:    markdownMIMEType,
+		})
+	}
+
+	if len(stale) > 0 {
+		w.mcpServer.DeleteResources(stale...)
+	}
+	if len(added) > 0 {
+		w.mcpServer.AddResources(added...)
+	}
+}
+
+// idList returns the IDs of a set
+func idList(set map[uint]bool) []uint {
+	ids := make([]uint, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,146 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/internal/services"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gorm.io/gorm"
+)
+
+func TestResourceWatcherSyncsChangedResources(t *testing.T) {
+	db := newTestDB(t)
+	orders := createTestGroup(t, db, "orders")
+	users := createTestGroup(t, db, "users")
+	billing := createTestGroup(t, db, "billing")
+	getOrder := createTestAPI(t, db, orders.ID, "Get order", "/orders/:id")
+	deleteOrder := createTestAPI(t, db, orders.ID, "Delete order", "/orders/:id")
+	getUser := createTestAPI(t, db, users.ID, "Get user", "/users/:id")
+	for _, name := range []string{"Invoices", "Refunds", "Payouts"} {
+		createTestAPI(t, db, billing.ID, name, "/"+name)
+	}
+
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithResourceCapabilities(false, true))
+	watcher := newResourceWatcher(mcpServer, db)
+	if err := watcher.listAll(); err != nil {
+		t.Fatalf("listAll: %v", err)
+	}
+	if got := len(listTestResources(t, mcpServer)); got != 9 {
+		t.Fatalf("listed %d resources, want 3 groups and 6 APIs", got)
+	}
+
+	// Rename a group, delete one of its APIs and add an API to another group
+	if err := db.Model(&orders).Update("name", "shop").Error; err != nil {
+		t.Fatalf("rename group: %v", err)
+	}
+	if err := db.Delete(&models.API{}, deleteOrder.ID).Error; err != nil {
+		t.Fatalf("delete API: %v", err)
+	}
+	listUsers := createTestAPI(t, db, users.ID, "List users", "/users")
+	recordTestChange(t, db, services.EventGroupUpdated, orders.ID, 0,
+		[]services.FieldChange{{Path: "name", Before: "orders", After: "shop"}})
+	recordTestChange(t, db, services.EventAPIDeleted, orders.ID, deleteOrder.ID, nil)
+	recordTestChange(t, db, services.EventAPICreated, users.ID, listUsers.ID, nil)
+
+	loaded := 0
+	db.Callback().Query().After("gorm:query").Register("test:count_apis", func(tx *gorm.DB) {
+		if _, ok := tx.Statement.Dest.(*[]models.API); ok {
+			loaded += int(tx.Statement.RowsAffected)
+		}
+	})
+
+	if err := watcher.poll(); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if loaded != 2 {
+		t.Errorf("loaded %d APIs, want only the APIs of the changes and the renamed group (2)", loaded)
+	}
+
+	got := listTestResources(t, mcpServer)
+	want := map[string]string{
+		groupURI("shop"):         "Documentation of the 1 APIs in group shop",
+		groupURI("users"):        "Documentation of the 2 APIs in group users",
+		groupURI("billing"):      "Documentation of the 3 APIs in group billing",
+		apiURI(getOrder.ID, ""):  "shop / Get order #1",
+		apiURI(getUser.ID, ""):   "users / Get user #3",
+		apiURI(listUsers.ID, ""): "users / List users #7",
+		apiURI(4, ""):            "billing / Invoices #4",
+		apiURI(5, ""):            "billing / Refunds #5",
+		apiURI(6, ""):            "billing / Payouts #6",
+	}
+	if len(got) != len(want) {
+		t.Errorf("resources = %v, want %d", sortedKeys(got), len(want))
+	}
+	for uri, text := range want {
+		resource, ok := got[uri]
+		if !ok {
+			t.Errorf("missing resource %s", uri)
+			continue
+		}
+		if resource.Name != text && resource.Description != text {
+			t.Errorf("%s = %q (%q), want %q", uri, resource.Name, resource.Description, text)
+		}
+	}
+}
+
+func createTestGroup(t *testing.T, db *gorm.DB, name string) models.Group {
+	t.Helper()
+	group := models.Group{Name: name}
+	if err := db.Create(&group).Error; err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	return group
+}
+
+func createTestAPI(t *testing.T, db *gorm.DB, groupID uint, name, endpoint string) models.API {
+	t.Helper()
+	api := models.API{GroupID: groupID, Name: name, Endpoint: endpoint, Method: "GET", Type: "HTTP"}
+	if err := db.Create(&api).Error; err != nil {
+		t.Fatalf("create API: %v", err)
+	}
+	return api
+}
+
+func recordTestChange(t *testing.T, db *gorm.DB, eventType string, groupID, apiID uint, diff []services.FieldChange) {
+	t.Helper()
+	event := services.ChangeEvent{Type: eventType, GroupID: groupID, APIID: apiID, Diff: diff}
+	if err := services.RecordChange(db, event); err != nil {
+		t.Fatalf("RecordChange: %v", err)
+	}
+}
+
+// listTestResources returns the resources the server lists, by URI
+func listTestResources(t *testing.T, mcpServer *server.MCPServer) map[string]mcp.Resource {
+	t.Helper()
+	message := mcpServer.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`))
+	data, err := json.Marshal(message)
+	if err != nil {
+		t.Fatalf("marshal response: %v", err)
+	}
+	var response struct {
+		Result mcp.ListResourcesResult `json:"result"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		t.Fatalf("unmarshal response %s: %v", data, err)
+	}
+
+	resources := make(map[string]mcp.Resource, len(response.Result.Resources))
+	for _, resource := range response.Result.Resources {
+		resources[resource.URI] = resource
+	}
+	return resources
+}
+
+func sortedKeys(resources map[string]mcp.Resource) []string {
+	keys := make([]string, 0, len(resources))
+	for uri := range resources {
+		keys = append(keys, uri)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,167 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ProjAnvil/knot/backend/internal/config"
+	"github.com/ProjAnvil/knot/backend/internal/handlers"
+	"github.com/ProjAnvil/knot/backend/pkg/mcptools"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"gorm.io/gorm"
+)
+
+// Name and version the server reports to MCP clients
+const (
+	serverName    = "knot"
+	serverVersion = "1.0.0"
+)
+
+// New creates the MCP server of Knot with the tools of mcptools and the resources and prompts
+// of the standalone knot-mcp server, answered from db instead of through POST /api/mcp-tools.
+// The write tools are added when cfg.MCPWrite is set. Every group and API is also a resource,
+// kept up to date with resources/updated notifications until ctx is done.
+func New(ctx context.Context, db *gorm.DB, cfg *config.Config) *server.MCPServer {
+	mcpServer := server.NewMCPServer(
+		serverName,
+		serverVersion,
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, true),
+		server.WithPromptCapabilities(true),
+		server.WithPaginationLimit(resourcePageSize),
+	)
+
+	// Register tools
+	for _, tool := range mcptools.ReadTools {
+		mcpServer.AddTool(tool, toolHandler(db, cfg, tool.Name))
+	}
+	if cfg.MCPWrite {
+		for _, tool := range mcptools.WriteTools {
+			mcpServer.AddTool(tool, toolHandler(db, cfg, tool.Name))
+		}
+	}
+
+	// Register resource
+	mcpServer.AddResource(
+		mcp.Resource{
+			URI:         "knot://groups",
+			Name:        "All API Groups",
+			Description: "List of all available API groups in the Knot database",
+			MIMEType:    "application/json",
+		},
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			data, err := callTool(db, cfg, "list_groups", map[string]interface{}{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch groups: %w", err)
+			}
+
+			jsonData, err := json.MarshalIndent(data, "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("failed to format result: %w", err)
+			}
+
+			return []mcp.ResourceContents{
+				mcp.TextResourceContents{
+					URI:      "knot://groups",
+					MIMEType: "application/json",
+					Text:     string(jsonData),
+				},
+			}, nil
+		},
+	)
+
+	// Register group and API resources
+	addResourceTemplates(mcpServer, db)
+	watchResources(ctx, mcpServer, db)
+
+	// Register prompts
+	mcpServer.AddPrompt(
+		mcp.Prompt{
+			Name:        "explore-api-group",
+			Description: "Explore APIs in a specific group",
+			Arguments: []mcp.PromptArgument{
+				{
+					Name:        "groupName",
+					Description: "Name of the API group to explore",
+					Required:    true,
+				},
+			},
+		},
+		func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			groupName, ok := request.Params.Arguments["groupName"]
+			if !ok || groupName == "" {
+				return nil, fmt.Errorf("groupName argument is required")
+			}
+
+			return &mcp.GetPromptResult{
+				Messages: []mcp.PromptMessage{
+					{
+						Role: "user",
+						Content: mcp.TextContent{
+							Type: "text",
+							Text: fmt.Sprintf("Please show me all APIs in the \"%s\" group. Include their names, endpoints, and methods.", groupName),
+						},
+					},
+				},
+			}, nil
+		},
+	)
+
+	mcpServer.AddPrompt(
+		mcp.Prompt{
+			Name:        "find-api",
+			Description: "Find an API by name or endpoint",
+			Arguments: []mcp.PromptArgument{
+				{
+					Name:        "query",
+					Description: "Search term for API name or endpoint",
+					Required:    true,
+				},
+			},
+		},
+		func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			query, ok := request.Params.Arguments["query"]
+			if !ok || query == "" {
+				return nil, fmt.Errorf("query argument is required")
+			}
+
+			return &mcp.GetPromptResult{
+				Messages: []mcp.PromptMessage{
+					{
+						Role: "user",
+						Content: mcp.TextContent{
+							Type: "text",
+							Text: fmt.Sprintf("Search for APIs matching \"%s\" and show me the results with their details.", query),
+						},
+					},
+				},
+			}, nil
+		},
+	)
+
+	return mcpServer
+}
+
+// toolHandler answers calls of a tool with its result formatted like the standalone server does
+func toolHandler(db *gorm.DB, cfg *config.Config, name string) server.ToolHandlerFunc {
+	return mcptools.Handler(func(tool string, args map[string]interface{}) (json.RawMessage, error) {
+		return callTool(db, cfg, tool, args)
+	}, name)
+}
+
+// callTool runs a tool of handlers.CallMCPTool. The data is returned as JSON, like the
+// standalone server receives it, so that its keys keep their order when formatted.
+func callTool(db *gorm.DB, cfg *config.Config, tool string, args map[string]interface{}) (json.RawMessage, error) {
+	data, err := handlers.CallMCPTool(db, cfg, tool, args)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return json.RawMessage(raw), nil
+}
@@ -5,20 +5,45 @@ import (
 	"time"
 )
 
+// API types
+const (
+	APITypeHTTP    = "HTTP"
+	APITypeRPC     = "RPC"
+	APITypeEvent   = "EVENT"   // Messages on a channel of a broker; the request parameters describe the payload
+	APITypeGraphQL = "GRAPHQL" // Root field of a GraphQL schema; the request parameters describe its arguments
+)
+
+// Directions of EVENT APIs, stored in Method: the documented service publishes the
+// messages of the channel, or subscribes to them
+const (
+	EventDirectionPublish   = "PUBLISH"
+	EventDirectionSubscribe = "SUBSCRIBE"
+)
+
+// Operation types of GRAPHQL APIs, stored in Method
+const (
+	GraphQLQuery        = "QUERY"
+	GraphQLMutation     = "MUTATION"
+	GraphQLSubscription = "SUBSCRIPTION"
+)
+
 // API represents an API endpoint
 type API struct {
-	ID         uint        `gorm:"primaryKey;autoIncrement" json:"id"`
-	GroupID    uint        `gorm:"not null;index:idx_group_id" json:"groupId"`
-	Group      *Group      `gorm:"foreignKey:GroupID" json:"group,omitempty"`
-	Name       string      `gorm:"not null" json:"name"`
-	Endpoint   string      `gorm:"not null" json:"endpoint"`
-	Method     string      `gorm:"type:varchar(10)" json:"method"` // GET, POST, PUT, DELETE, PATCH
-	Type       string      `gorm:"not null" json:"type"`           // HTTP or RPC
-	Order      int         `gorm:"default:0" json:"order"`
-	Note       *string     `gorm:"type:text" json:"note"`
-	Parameters []Parameter `gorm:"foreignKey:APIID;constraint:OnDelete:CASCADE" json:"parameters,omitempty"`
-	CreatedAt  int64       `gorm:"autoCreateTime" json:"-"`
-	UpdatedAt  int64       `gorm:"autoUpdateTime" json:"-"`
+	ID                 uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	GroupID            uint        `gorm:"not null;index:idx_group_id" json:"groupId"`
+	Group              *Group      `gorm:"foreignKey:GroupID" json:"group,omitempty"`
+	Name               string      `gorm:"not null" json:"name"`
+	Endpoint           string      `gorm:"not null" json:"endpoint"`         // Path, channel name for EVENT APIs, or root field name for GRAPHQL APIs
+	Method             string      `gorm:"type:varchar(12)" json:"method"`   // GET, POST, PUT, DELETE, PATCH, PUBLISH and SUBSCRIBE for EVENT APIs, or the operation type for GRAPHQL APIs
+	Type               string      `gorm:"not null" json:"type"`             // HTTP, RPC, EVENT or GRAPHQL
+	Protocol           *string     `gorm:"type:varchar(20)" json:"protocol"` // Broker protocol of EVENT APIs, e.g. kafka, amqp or mqtt
+	Signature          *string     `gorm:"type:text" json:"signature"`       // SDL of the root field of GRAPHQL APIs and the types it uses
+	Order              int         `gorm:"default:0" json:"order"`
+	Note               *string     `gorm:"type:text" json:"note"`
+	Parameters         []Parameter `gorm:"foreignKey:APIID;constraint:OnDelete:CASCADE" json:"parameters,omitempty"`
+	UnresolvedComments int64       `gorm:"-" json:"unresolvedComments,omitempty"` // Open review threads, filled in by listing endpoints
+	CreatedAt          int64       `gorm:"autoCreateTime" json:"-"`
+	UpdatedAt          int64       `gorm:"autoUpdateTime" json:"-"`
 }
 
 // TableName specifies the table name for API
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ChangeRecord is an entry of the change history of the documentation.
+// Records are kept when the API or group they describe is deleted.
+type ChangeRecord struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	EventType string `gorm:"not null" json:"event"`
+	GroupID   uint   `gorm:"index:idx_change_group" json:"groupId"`
+	APIID     *uint  `gorm:"index:idx_change_api" json:"apiId"`
+	Source    string `gorm:"not null;index:idx_change_source" json:"source"` // Where the change was made, e.g. api or mcp
+	Diff      string `gorm:"type:text" json:"-"`                             // JSON-encoded list of field changes
+	CreatedAt int64  `gorm:"autoCreateTime" json:"-"`
+}
+
+// TableName specifies the table name for ChangeRecord
+func (ChangeRecord) TableName() string {
+	return "change_records"
+}
+
+// MarshalJSON customizes JSON serialization to inline the diff and convert timestamps to ISO 8601 strings
+func (r ChangeRecord) MarshalJSON() ([]byte, error) {
+	type Alias ChangeRecord
+
+	diff := json.RawMessage("[]")
+	if r.Diff != "" {
+		diff = json.RawMessage(r.Diff)
+	}
+
+	return json.Marshal(&struct {
+		*Alias
+		Diff      json.RawMessage `json:"diff"`
+		CreatedAt string          `json:"createdAt"`
+	}{
+		Alias:     (*Alias)(&r),
+		Diff:      diff,
+		CreatedAt: time.Unix(r.CreatedAt, 0).UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Comment represents a review comment on an API or on one of its parameters.
+// Comments without a ParentID start a thread, replies point at the thread root.
+type Comment struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	APIID       uint      `gorm:"not null;index:idx_comment_api" json:"apiId"`
+	API         *API      `gorm:"foreignKey:APIID;constraint:OnDelete:CASCADE" json:"-"`
+	ParameterID *uint     `gorm:"index:idx_comment_parameter" json:"parameterId"`
+	ParentID    *uint     `gorm:"index:idx_comment_parent" json:"parentId"`
+	Replies     []Comment `gorm:"foreignKey:ParentID" json:"replies,omitempty"`
+	Author      string    `gorm:"not null" json:"author"`
+	Body        string    `gorm:"type:text;not null" json:"body"`
+	Mentions    string    `gorm:"type:text" json:"-"` // Comma-separated mentioned user names
+	Resolved    bool      `gorm:"default:false" json:"resolved"`
+	ResolvedBy  *string   `json:"resolvedBy"`
+	ResolvedAt  *int64    `json:"-"`
+	CreatedAt   int64     `gorm:"autoCreateTime" json:"-"`
+	UpdatedAt   int64     `gorm:"autoUpdateTime" json:"-"`
+}
+
+// TableName specifies the table name for Comment
+func (Comment) TableName() string {
+	return "comments"
+}
+
+// MentionList returns the user names mentioned in the comment
+func (c Comment) MentionList() []string {
+	mentions := []string{}
+	for _, mention := range strings.Split(c.Mentions, ",") {
+		if mention = strings.TrimSpace(mention); mention != "" {
+			mentions = append(mentions, mention)
+		}
+	}
+	return mentions
+}
+
+// MarshalJSON customizes JSON serialization to convert timestamps to ISO 8601 strings
+func (c Comment) MarshalJSON() ([]byte, error) {
+	type Alias Comment
+
+	var resolvedAt *string
+	if c.ResolvedAt != nil {
+		formatted := time.Unix(*c.ResolvedAt, 0).UTC().Format(time.RFC3339)
+		resolvedAt = &formatted
+	}
+
+	return json.Marshal(&struct {
+		*Alias
+		Mentions   []string `json:"mentions"`
+		ResolvedAt *string  `json:"resolvedAt"`
+		CreatedAt  string   `json:"createdAt"`
+		UpdatedAt  string   `json:"updatedAt"`
+	}{
+		Alias:      (*Alias)(&c),
+		Mentions:   c.MentionList(),
+		ResolvedAt: resolvedAt,
+		CreatedAt:  time.Unix(c.CreatedAt, 0).UTC().Format(time.RFC3339),
+		UpdatedAt:  time.Unix(c.UpdatedAt, 0).UTC().Format(time.RFC3339),
+	})
+}
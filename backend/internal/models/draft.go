@@ -0,0 +1,83 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Draft statuses
+const (
+	DraftStatusOpen     = "open"
+	DraftStatusApproved = "approved"
+	DraftStatusRejected = "rejected"
+)
+
+// APIDraft represents a change request for an API. Edits are collected in the draft
+// and only merged into the published API and Parameter rows once approved.
+// A draft without an API proposes a new API in GroupID, e.g. one discovered by knot record.
+type APIDraft struct {
+	ID                 uint    `gorm:"primaryKey;autoIncrement" json:"id"`
+	APIID              *uint   `gorm:"index:idx_draft_api" json:"apiId"`
+	API                *API    `gorm:"foreignKey:APIID;constraint:OnDelete:CASCADE" json:"-"`
+	GroupID            *uint   `gorm:"index:idx_draft_group" json:"groupId"`
+	Group              *Group  `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE" json:"-"`
+	Status             string  `gorm:"not null;index:idx_draft_status" json:"status"` // open, approved or rejected
+	Author             string  `json:"author"`
+	Summary            *string `gorm:"type:text" json:"summary"`
+	Name               string  `gorm:"not null" json:"name"`
+	Endpoint           string  `gorm:"not null" json:"endpoint"`
+	Method             string  `gorm:"type:varchar(12)" json:"method"`
+	Type               string  `gorm:"not null" json:"type"`
+	Protocol           *string `gorm:"type:varchar(20)" json:"protocol"`
+	Note               *string `gorm:"type:text" json:"note"`
+	RequestParameters  string  `gorm:"type:text" json:"-"`            // JSON-encoded proposed request parameter tree
+	ResponseParameters string  `gorm:"type:text" json:"-"`            // JSON-encoded proposed response parameter tree
+	BaseChangeID       uint    `gorm:"default:0" json:"baseChangeId"` // Last change record of the API when the draft was opened or rebased
+	ReviewedBy         *string `json:"reviewedBy"`
+	ReviewComment      *string `gorm:"type:text" json:"reviewComment"`
+	ReviewedAt         *int64  `json:"-"`
+	CreatedAt          int64   `gorm:"autoCreateTime" json:"-"`
+	UpdatedAt          int64   `gorm:"autoUpdateTime" json:"-"`
+}
+
+// TableName specifies the table name for APIDraft
+func (APIDraft) TableName() string {
+	return "api_drafts"
+}
+
+// MarshalJSON customizes JSON serialization to inline the proposed parameter trees
+// and convert timestamps to ISO 8601 strings
+func (d APIDraft) MarshalJSON() ([]byte, error) {
+	type Alias APIDraft
+
+	requestParameters := json.RawMessage("[]")
+	if d.RequestParameters != "" {
+		requestParameters = json.RawMessage(d.RequestParameters)
+	}
+	responseParameters := json.RawMessage("[]")
+	if d.ResponseParameters != "" {
+		responseParameters = json.RawMessage(d.ResponseParameters)
+	}
+
+	var reviewedAt *string
+	if d.ReviewedAt != nil {
+		formatted := time.Unix(*d.ReviewedAt, 0).UTC().Format(time.RFC3339)
+		reviewedAt = &formatted
+	}
+
+	return json.Marshal(&struct {
+		*Alias
+		RequestParameters  json.RawMessage `json:"requestParameters"`
+		ResponseParameters json.RawMessage `json:"responseParameters"`
+		ReviewedAt         *string         `json:"reviewedAt"`
+		CreatedAt          string          `json:"createdAt"`
+		UpdatedAt          string          `json:"updatedAt"`
+	}{
+		Alias:              (*Alias)(&d),
+		RequestParameters:  requestParameters,
+		ResponseParameters: responseParameters,
+		ReviewedAt:         reviewedAt,
+		CreatedAt:          time.Unix(d.CreatedAt, 0).UTC().Format(time.RFC3339),
+		UpdatedAt:          time.Unix(d.UpdatedAt, 0).UTC().Format(time.RFC3339),
+	})
+}
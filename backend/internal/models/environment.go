@@ -0,0 +1,80 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MaskedSecret replaces the value of secret variables in every response
+const MaskedSecret = "********"
+
+// Environment represents a deployment target such as dev, staging or prod.
+// Environments without a group are global; group environments override global
+// variables of the same environment name.
+type Environment struct {
+	ID          uint                  `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string                `gorm:"not null;index:idx_environment_name" json:"name"`
+	GroupID     *uint                 `gorm:"index:idx_environment_group" json:"groupId"`
+	Group       *Group                `gorm:"foreignKey:GroupID;constraint:OnDelete:CASCADE" json:"-"`
+	Description *string               `gorm:"type:text" json:"description"`
+	Variables   []EnvironmentVariable `gorm:"foreignKey:EnvironmentID;constraint:OnDelete:CASCADE" json:"variables"`
+	CreatedAt   int64                 `gorm:"autoCreateTime" json:"-"`
+	UpdatedAt   int64                 `gorm:"autoUpdateTime" json:"-"`
+}
+
+// TableName specifies the table name for Environment
+func (Environment) TableName() string {
+	return "environments"
+}
+
+// MarshalJSON customizes JSON serialization to convert timestamps to ISO 8601 strings
+func (e Environment) MarshalJSON() ([]byte, error) {
+	type Alias Environment
+	if e.Variables == nil {
+		e.Variables = []EnvironmentVariable{}
+	}
+	return json.Marshal(&struct {
+		*Alias
+		CreatedAt string `json:"createdAt"`
+		UpdatedAt string `json:"updatedAt"`
+	}{
+		Alias:     (*Alias)(&e),
+		CreatedAt: time.Unix(e.CreatedAt, 0).UTC().Format(time.RFC3339),
+		UpdatedAt: time.Unix(e.UpdatedAt, 0).UTC().Format(time.RFC3339),
+	})
+}
+
+// EnvironmentVariable is a named value of an environment, e.g. baseUrl or tenantId.
+// Secret values are stored encrypted.
+type EnvironmentVariable struct {
+	ID            uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	EnvironmentID uint   `gorm:"not null;index:idx_variable_environment" json:"environmentId"`
+	Key           string `gorm:"not null" json:"key"`
+	Value         string `gorm:"type:text" json:"value"`
+	Secret        bool   `gorm:"not null" json:"secret"`
+	CreatedAt     int64  `gorm:"autoCreateTime" json:"-"`
+	UpdatedAt     int64  `gorm:"autoUpdateTime" json:"-"`
+}
+
+// TableName specifies the table name for EnvironmentVariable
+func (EnvironmentVariable) TableName() string {
+	return "environment_variables"
+}
+
+// MarshalJSON customizes JSON serialization to mask secret values
+// and convert timestamps to ISO 8601 strings
+func (v EnvironmentVariable) MarshalJSON() ([]byte, error) {
+	type Alias EnvironmentVariable
+	if v.Secret {
+		v.Value = MaskedSecret
+	}
+	return json.Marshal(&struct {
+		*Alias
+		CreatedAt string `json:"createdAt"`
+		UpdatedAt string `json:"updatedAt"`
+	}{
+		Alias:     (*Alias)(&v),
+		CreatedAt: time.Unix(v.CreatedAt, 0).UTC().Format(time.RFC3339),
+		UpdatedAt: time.Unix(v.UpdatedAt, 0).UTC().Format(time.RFC3339),
+	})
+}
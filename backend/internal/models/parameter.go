@@ -14,9 +14,10 @@ type Parameter struct {
 	Parent      *Parameter  `gorm:"foreignKey:ParentID" json:"-"`
 	Children    []Parameter `gorm:"foreignKey:ParentID" json:"children,omitempty"`
 	Name        string      `gorm:"not null" json:"name"`
-	Type        string      `gorm:"not null" json:"type"` // string, number, boolean, array, object
+	Type        string      `gorm:"not null" json:"type"` // string, integer, number, boolean, array, object, or a union such as string|number
 	Description *string     `gorm:"type:text" json:"description"`
 	Required    bool        `gorm:"default:false" json:"required"`
+	Nullable    bool        `gorm:"default:false" json:"nullable"`
 	ParamType   string      `gorm:"not null;index:idx_api_param" json:"paramType"` // request or response
 	Order       int         `gorm:"not null;index:idx_api_param" json:"order"`
 	CreatedAt   int64       `gorm:"autoCreateTime" json:"-"`
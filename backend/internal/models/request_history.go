@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RequestHistory records a request sent to a documented API through the executor
+type RequestHistory struct {
+	ID              uint    `gorm:"primaryKey;autoIncrement" json:"id"`
+	APIID           uint    `gorm:"not null;index:idx_history_api" json:"apiId"`
+	API             *API    `gorm:"foreignKey:APIID;constraint:OnDelete:CASCADE" json:"-"`
+	Method          string  `gorm:"type:varchar(10);not null" json:"method"`
+	URL             string  `gorm:"type:text;not null" json:"url"`
+	RequestHeaders  string  `gorm:"type:text" json:"-"` // JSON-encoded header map
+	RequestBody     *string `gorm:"type:text" json:"requestBody"`
+	StatusCode      int     `json:"statusCode"`
+	ResponseHeaders string  `gorm:"type:text" json:"-"` // JSON-encoded header map
+	ResponseBody    *string `gorm:"type:text" json:"responseBody"`
+	ResponseSize    int64   `json:"responseSize"`
+	Truncated       bool    `json:"truncated"`
+	DurationMs      int64   `json:"durationMs"`
+	Error           *string `gorm:"type:text" json:"error"`
+	CreatedAt       int64   `gorm:"autoCreateTime" json:"-"`
+}
+
+// TableName specifies the table name for RequestHistory
+func (RequestHistory) TableName() string {
+	return "request_history"
+}
+
+// MarshalJSON customizes JSON serialization to inline the header maps
+// and convert timestamps to ISO 8601 strings
+func (h RequestHistory) MarshalJSON() ([]byte, error) {
+	type Alias RequestHistory
+
+	requestHeaders := json.RawMessage("{}")
+	if h.RequestHeaders != "" {
+		requestHeaders = json.RawMessage(h.RequestHeaders)
+	}
+	responseHeaders := json.RawMessage("{}")
+	if h.ResponseHeaders != "" {
+		responseHeaders = json.RawMessage(h.ResponseHeaders)
+	}
+
+	return json.Marshal(&struct {
+		*Alias
+		RequestHeaders  json.RawMessage `json:"requestHeaders"`
+		ResponseHeaders json.RawMessage `json:"responseHeaders"`
+		CreatedAt       string          `json:"createdAt"`
+	}{
+		Alias:           (*Alias)(&h),
+		RequestHeaders:  requestHeaders,
+		ResponseHeaders: responseHeaders,
+		CreatedAt:       time.Unix(h.CreatedAt, 0).UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Webhook represents an outgoing webhook subscription
+type Webhook struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string `json:"name"`
+	URL       string `gorm:"not null" json:"url"`
+	Events    string `gorm:"type:text" json:"-"` // Comma-separated event types, empty means all events
+	GroupID   *uint  `gorm:"index:idx_webhook_group" json:"groupId"`
+	Secret    string `json:"-"` // HMAC secret used to sign payloads, never serialized
+	Active    bool   `gorm:"not null" json:"active"`
+	CreatedAt int64  `gorm:"autoCreateTime" json:"-"`
+	UpdatedAt int64  `gorm:"autoUpdateTime" json:"-"`
+}
+
+// TableName specifies the table name for Webhook
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// EventList returns the subscribed event types
+func (w Webhook) EventList() []string {
+	events := []string{}
+	for _, event := range strings.Split(w.Events, ",") {
+		if event = strings.TrimSpace(event); event != "" {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Matches reports whether the webhook should receive an event of the given type for the given group
+func (w Webhook) Matches(eventType string, groupID uint) bool {
+	if !w.Active {
+		return false
+	}
+
+	if w.GroupID != nil && *w.GroupID != groupID {
+		return false
+	}
+
+	events := w.EventList()
+	if len(events) == 0 {
+		return true
+	}
+	for _, event := range events {
+		if event == eventType || event == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON customizes JSON serialization to expose events as a list and hide the secret
+func (w Webhook) MarshalJSON() ([]byte, error) {
+	type Alias Webhook
+	return json.Marshal(&struct {
+		*Alias
+		Events    []string `json:"events"`
+		HasSecret bool     `json:"hasSecret"`
+		CreatedAt string   `json:"createdAt"`
+		UpdatedAt string   `json:"updatedAt"`
+	}{
+		Alias:     (*Alias)(&w),
+		Events:    w.EventList(),
+		HasSecret: w.Secret != "",
+		CreatedAt: time.Unix(w.CreatedAt, 0).UTC().Format(time.RFC3339),
+		UpdatedAt: time.Unix(w.UpdatedAt, 0).UTC().Format(time.RFC3339),
+	})
+}
+
+// WebhookDelivery records a single delivery attempt of a webhook
+type WebhookDelivery struct {
+	ID         uint    `gorm:"primaryKey;autoIncrement" json:"id"`
+	WebhookID  uint    `gorm:"not null;index:idx_delivery_webhook" json:"webhookId"`
+	DeliveryID string  `gorm:"not null;index:idx_delivery_id" json:"deliveryId"`
+	EventType  string  `gorm:"not null" json:"eventType"`
+	Payload    string  `gorm:"type:text" json:"payload"`
+	Attempt    int     `gorm:"not null" json:"attempt"`
+	StatusCode int     `json:"statusCode"`
+	Success    bool    `json:"success"`
+	Error      *string `gorm:"type:text" json:"error"`
+	DurationMs int64   `json:"durationMs"`
+	CreatedAt  int64   `gorm:"autoCreateTime" json:"-"`
+}
+
+// TableName specifies the table name for WebhookDelivery
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// MarshalJSON customizes JSON serialization to convert timestamps to ISO 8601 strings
+func (d WebhookDelivery) MarshalJSON() ([]byte, error) {
+	type Alias WebhookDelivery
+	return json.Marshal(&struct {
+		*Alias
+		CreatedAt string `json:"createdAt"`
+	}{
+		Alias:     (*Alias)(&d),
+		CreatedAt: time.Unix(d.CreatedAt, 0).UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,614 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// AsyncAPI errors
+var (
+	ErrInvalidEventDirection      = errors.New("the method of EVENT APIs must be PUBLISH or SUBSCRIBE")
+	ErrInvalidAsyncAPI            = errors.New("invalid AsyncAPI document")
+	ErrUnsupportedAsyncAPIVersion = errors.New("unsupported AsyncAPI version, use 2.6.0 or 3.0.0")
+)
+
+// AsyncAPIVersions are the versions AsyncAPI documents are exported in
+var AsyncAPIVersions = []string{"2.6.0", "3.0.0"}
+
+// asyncAPIInfoVersion is the application version of exported documents, which Knot does not track
+const asyncAPIInfoVersion = "1.0.0"
+
+// NormalizeAPIMethod checks the method of an API of the given type. The method of EVENT APIs
+// is their direction and must be PUBLISH or SUBSCRIBE, and that of GRAPHQL APIs their operation
+// type, QUERY, MUTATION or SUBSCRIPTION, in any case; they are returned upper-cased.
+// Methods of other APIs are returned unchanged.
+func NormalizeAPIMethod(apiType, method string) (string, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(method))
+	switch apiType {
+	case models.APITypeEvent:
+		if normalized != models.EventDirectionPublish && normalized != models.EventDirectionSubscribe {
+			return method, ErrInvalidEventDirection
+		}
+	case models.APITypeGraphQL:
+		if normalized != models.GraphQLQuery && normalized != models.GraphQLMutation && normalized != models.GraphQLSubscription {
+			return method, ErrInvalidGraphQLOperation
+		}
+	default:
+		return method, nil
+	}
+	return normalized, nil
+}
+
+// ResolveAsyncAPIVersion returns the export version for a requested version such as "2", "2.6"
+// or "3.0.0". The default is 3.0.0.
+func ResolveAsyncAPIVersion(version string) (string, error) {
+	switch strings.TrimPrefix(strings.TrimSpace(version), "v") {
+	case "", "3", "3.0", "3.0.0":
+		return "3.0.0", nil
+	case "2", "2.6", "2.6.0":
+		return "2.6.0", nil
+	}
+	return "", ErrUnsupportedAsyncAPIVersion
+}
+
+// GenerateAsyncAPI generates an AsyncAPI document of the EVENT APIs among apis; other APIs are
+// left out. Version is one of AsyncAPIVersions. Each API becomes an operation on the channel
+// named by its endpoint, with a message whose payload is the schema of the request parameters
+// and the broker protocol as a channel binding. PUBLISH APIs become send operations in 3.0.0
+// and subscribe operations in 2.6.0, whose operations are named from the client's side.
+func GenerateAsyncAPI(apis []APIWithParams, title, version string) (*OrderedObject, error) {
+	if !containsString(AsyncAPIVersions, version) {
+		return nil, ErrUnsupportedAsyncAPIVersion
+	}
+
+	doc := NewOrderedObject()
+	doc.Set("asyncapi", version)
+	info := NewOrderedObject()
+	info.Set("title", title)
+	info.Set("version", asyncAPIInfoVersion)
+	doc.Set("info", info)
+	doc.Set("defaultContentType", "application/json")
+
+	channels := NewOrderedObject()
+	operations := NewOrderedObject()
+	channelIDs := make(map[string]string)
+	usedChannelIDs := make(map[string]bool)
+	usedMessageIDs := make(map[string]map[string]bool)
+	usedOperationIDs := make(map[string]bool)
+
+	for _, api := range apis {
+		if api.API.Type != models.APITypeEvent {
+			continue
+		}
+
+		address := api.API.Endpoint
+		operationID := uniqueSlug(Slugify(api.API.Name), usedOperationIDs)
+		message := asyncAPIMessage(api, operationID)
+
+		if version == "2.6.0" {
+			channel := asyncAPIChannel(channels, address)
+			setAsyncAPIBinding(channel, api.API.Protocol)
+
+			// Operations of 2.x describe what clients do: they subscribe to what the service publishes
+			key := "publish"
+			if api.API.Method == models.EventDirectionPublish {
+				key = "subscribe"
+			}
+			if existing, ok := channel.Values[key].(*OrderedObject); ok {
+				// One operation per direction: further APIs add alternative messages
+				alternatives := []interface{}{existing.Values["message"]}
+				if current, ok := existing.Values["message"].(*OrderedObject); ok {
+					if oneOf, ok := current.Values["oneOf"].([]interface{}); ok {
+						alternatives = oneOf
+					}
+				}
+				oneOf := NewOrderedObject()
+				oneOf.Set("oneOf", append(alternatives, message))
+				existing.Set("message", oneOf)
+				continue
+			}
+
+			operation := NewOrderedObject()
+			operation.Set("operationId", operationID)
+			operation.Set("summary", api.API.Name)
+			setAsyncAPIDescription(operation, api.API.Note)
+			operation.Set("message", message)
+			channel.Set(key, operation)
+			continue
+		}
+
+		// Channels of 3.0.0 are keyed by ID and name their address
+		channelID, ok := channelIDs[address]
+		if !ok {
+			channelID = uniqueSlug(Slugify(address), usedChannelIDs)
+			channelIDs[address] = channelID
+			usedMessageIDs[channelID] = make(map[string]bool)
+			asyncAPIChannel(channels, channelID).Set("address", address)
+		}
+		channel := asyncAPIChannel(channels, channelID)
+		setAsyncAPIBinding(channel, api.API.Protocol)
+
+		messages, ok := channel.Values["messages"].(*OrderedObject)
+		if !ok {
+			messages = NewOrderedObject()
+			channel.Set("messages", messages)
+		}
+		messageID := uniqueSlug(operationID, usedMessageIDs[channelID])
+		messages.Set(messageID, message)
+
+		action := "receive"
+		if api.API.Method == models.EventDirectionPublish {
+			action = "send"
+		}
+		channelRef := NewOrderedObject()
+		channelRef.Set("$ref", "#/channels/"+channelID)
+		messageRef := NewOrderedObject()
+		messageRef.Set("$ref", "#/channels/"+channelID+"/messages/"+messageID)
+
+		operation := NewOrderedObject()
+		operation.Set("action", action)
+		operation.Set("channel", channelRef)
+		operation.Set("summary", api.API.Name)
+		setAsyncAPIDescription(operation, api.API.Note)
+		operation.Set("messages", []interface{}{messageRef})
+		operations.Set(operationID, operation)
+	}
+
+	doc.Set("channels", channels)
+	if version != "2.6.0" {
+		doc.Set("operations", operations)
+	}
+	return doc, nil
+}
+
+// asyncAPIChannel returns the channel with the given key, adding it if needed
+func asyncAPIChannel(channels *OrderedObject, key string) *OrderedObject {
+	if channel, ok := channels.Values[key].(*OrderedObject); ok {
+		return channel
+	}
+	channel := NewOrderedObject()
+	channels.Set(key, channel)
+	return channel
+}
+
+// asyncAPIMessage returns the message of an EVENT API with the schema of its payload
+func asyncAPIMessage(api APIWithParams, name string) *OrderedObject {
+	payload := NewOrderedObject()
+	writeJSONSchemaObject(payload, BuildParameterTree(api.RequestParameters))
+
+	message := NewOrderedObject()
+	message.Set("name", name)
+	message.Set("title", api.API.Name)
+	message.Set("payload", payload)
+	return message
+}
+
+// setAsyncAPIBinding declares the broker protocol of a channel as an empty binding
+func setAsyncAPIBinding(channel *OrderedObject, protocol *string) {
+	if protocol == nil || strings.TrimSpace(*protocol) == "" {
+		return
+	}
+	name := strings.TrimSpace(*protocol)
+	bindings, ok := channel.Values["bindings"].(*OrderedObject)
+	if !ok {
+		bindings = NewOrderedObject()
+		channel.Set("bindings", bindings)
+	}
+	if _, exists := bindings.Get(name); !exists {
+		bindings.Set(name, NewOrderedObject())
+	}
+}
+
+// setAsyncAPIDescription sets the description of an operation from the note of an API
+func setAsyncAPIDescription(operation *OrderedObject, note *string) {
+	if note != nil && strings.TrimSpace(*note) != "" {
+		operation.Set("description", strings.TrimSpace(*note))
+	}
+}
+
+// AsyncAPIImportCandidate is an EVENT API found in an AsyncAPI document
+type AsyncAPIImportCandidate struct {
+	Key               string          `json:"key"` // Direction and channel, e.g. "PUBLISH orders.created"
+	Name              string          `json:"name"`
+	Channel           string          `json:"channel"`
+	Direction         string          `json:"direction"` // PUBLISH or SUBSCRIBE, seen from the documented service
+	Protocol          string          `json:"protocol,omitempty"`
+	Description       string          `json:"description,omitempty"`
+	ExistingAPIID     *uint           `json:"existingApiId,omitempty"` // EVENT API of the group with the same key
+	PayloadParameters []ParameterNode `json:"payloadParameters"`
+}
+
+// PreviewAsyncAPIImport lists the EVENT APIs an AsyncAPI 2.x or 3.x document, in YAML or JSON,
+// would create in a group: one per operation, in document order. Subscribe operations of 2.x
+// documents and send operations of 3.x documents become PUBLISH APIs, as the former are
+// described from the client's side. Payload schemas are converted like JSON Schema imports;
+// operations with several messages combine their payloads as oneOf alternatives, and payloads
+// in other schema formats such as Avro are left empty. The protocol is taken from the channel
+// bindings, or from the servers of the channel or document. Operations matching an EVENT API
+// of the group are marked with its ID.
+func PreviewAsyncAPIImport(db *gorm.DB, group models.Group, data []byte) ([]AsyncAPIImportCandidate, error) {
+	document, err := DecodeOrderedYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAsyncAPI, err)
+	}
+	root, ok := document.(*OrderedObject)
+	if !ok {
+		return nil, fmt.Errorf("%w: the document must be an object", ErrInvalidAsyncAPI)
+	}
+
+	version, _ := root.Values["asyncapi"].(string)
+	reader := &asyncAPIReader{root: root}
+	var candidates []AsyncAPIImportCandidate
+	switch {
+	case strings.HasPrefix(version, "2."):
+		candidates, err = reader.candidates2()
+	case strings.HasPrefix(version, "3."):
+		candidates, err = reader.candidates3()
+	default:
+		return nil, fmt.Errorf("%w: the asyncapi field must name version 2.x or 3.x", ErrInvalidAsyncAPI)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var existing []models.API
+	if err := db.Where("group_id = ? AND type = ?", group.ID, models.APITypeEvent).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	existingIDs := make(map[string]uint, len(existing))
+	for _, api := range existing {
+		existingIDs[api.Method+" "+api.Endpoint] = api.ID
+	}
+
+	// Keep the first operation of a direction on a channel
+	seen := make(map[string]bool)
+	result := make([]AsyncAPIImportCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if seen[candidate.Key] {
+			continue
+		}
+		seen[candidate.Key] = true
+		if id, ok := existingIDs[candidate.Key]; ok {
+			candidate.ExistingAPIID = &id
+		}
+		result = append(result, candidate)
+	}
+	return result, nil
+}
+
+// ImportAsyncAPICandidates creates the EVENT APIs of the selected candidates in a group, with
+// their payload as request parameters, in a single transaction. An empty selection imports
+// every candidate. Candidates matching an existing API are skipped. It returns the created APIs.
+func ImportAsyncAPICandidates(db *gorm.DB, group models.Group, candidates []AsyncAPIImportCandidate, keys []string) ([]models.API, error) {
+	selected := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		selected[key] = true
+	}
+
+	created := []models.API{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var maxOrder int
+		if err := tx.Model(&models.API{}).Where("group_id = ?", group.ID).Select("COALESCE(MAX(`order`), 0)").Scan(&maxOrder).Error; err != nil {
+			return err
+		}
+
+		for _, candidate := range candidates {
+			if (len(keys) > 0 && !selected[candidate.Key]) || candidate.ExistingAPIID != nil {
+				continue
+			}
+
+			maxOrder++
+			api := models.API{
+				GroupID:  group.ID,
+				Name:     candidate.Name,
+				Endpoint: candidate.Channel,
+				Method:   candidate.Direction,
+				Type:     models.APITypeEvent,
+				Order:    maxOrder,
+			}
+			if candidate.Protocol != "" {
+				protocol := candidate.Protocol
+				api.Protocol = &protocol
+			}
+			if candidate.Description != "" {
+				note := candidate.Description
+				api.Note = &note
+			}
+			if err := tx.Create(&api).Error; err != nil {
+				return err
+			}
+			if _, err := ReplaceParameterTree(tx, api.ID, "request", candidate.PayloadParameters); err != nil {
+				return err
+			}
+			created = append(created, api)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// asyncAPIReader reads the operations of a decoded AsyncAPI document
+type asyncAPIReader struct {
+	root *OrderedObject
+}
+
+// candidates2 reads the publish and subscribe operations of the channels of a 2.x document
+func (r *asyncAPIReader) candidates2() ([]AsyncAPIImportCandidate, error) {
+	channels, ok := r.root.Values["channels"].(*OrderedObject)
+	if !ok {
+		return nil, fmt.Errorf("%w: channels are missing", ErrInvalidAsyncAPI)
+	}
+
+	var candidates []AsyncAPIImportCandidate
+	for _, address := range channels.Keys {
+		channel := r.object(channels.Values[address])
+		if channel == nil {
+			continue
+		}
+		for _, op := range []struct{ key, direction string }{
+			{"subscribe", models.EventDirectionPublish},
+			{"publish", models.EventDirectionSubscribe},
+		} {
+			operation := r.object(channel.Values[op.key])
+			if operation == nil {
+				continue
+			}
+
+			var messages []interface{}
+			if message := r.object(operation.Values["message"]); message != nil {
+				if alternatives, ok := message.Values["oneOf"].([]interface{}); ok {
+					messages = alternatives
+				} else {
+					messages = []interface{}{message}
+				}
+			}
+
+			var servers []*OrderedObject
+			if names, ok := channel.Values["servers"].([]interface{}); ok {
+				definitions, _ := r.root.Values["servers"].(*OrderedObject)
+				for _, name := range names {
+					if name, ok := name.(string); ok && definitions != nil {
+						if server := r.object(definitions.Values[name]); server != nil {
+							servers = append(servers, server)
+						}
+					}
+				}
+			}
+
+			candidate, err := r.candidate(address, op.direction, stringField(operation, "operationId"), operation, channel, messages, servers)
+			if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates, nil
+}
+
+// candidates3 reads the send and receive operations of a 3.x document
+func (r *asyncAPIReader) candidates3() ([]AsyncAPIImportCandidate, error) {
+	operations, ok := r.root.Values["operations"].(*OrderedObject)
+	if !ok {
+		if _, ok := r.root.Values["channels"].(*OrderedObject); !ok {
+			return nil, fmt.Errorf("%w: channels are missing", ErrInvalidAsyncAPI)
+		}
+		// Channels without operations do not tell who publishes
+		return []AsyncAPIImportCandidate{}, nil
+	}
+
+	var candidates []AsyncAPIImportCandidate
+	for _, id := range operations.Keys {
+		operation := r.object(operations.Values[id])
+		if operation == nil {
+			continue
+		}
+
+		var direction string
+		switch stringField(operation, "action") {
+		case "send":
+			direction = models.EventDirectionPublish
+		case "receive":
+			direction = models.EventDirectionSubscribe
+		default:
+			continue
+		}
+
+		channel := r.object(operation.Values["channel"])
+		if channel == nil {
+			continue
+		}
+		address := stringField(channel, "address")
+		if address == "" {
+			// Channels without an address are named by their ID
+			if ref, ok := operation.Values["channel"].(*OrderedObject); ok {
+				target, _ := ref.Values["$ref"].(string)
+				address = target[strings.LastIndex(target, "/")+1:]
+			}
+		}
+		if address == "" {
+			continue
+		}
+
+		messages, _ := operation.Values["messages"].([]interface{})
+		if len(messages) == 0 {
+			if channelMessages, ok := channel.Values["messages"].(*OrderedObject); ok {
+				for _, name := range channelMessages.Keys {
+					messages = append(messages, channelMessages.Values[name])
+				}
+			}
+		}
+
+		var servers []*OrderedObject
+		if refs, ok := channel.Values["servers"].([]interface{}); ok {
+			for _, ref := range refs {
+				if server := r.object(ref); server != nil {
+					servers = append(servers, server)
+				}
+			}
+		}
+
+		candidate, err := r.candidate(address, direction, id, operation, channel, messages, servers)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+// candidate builds the import candidate of an operation
+func (r *asyncAPIReader) candidate(address, direction, operationID string, operation, channel *OrderedObject, messages []interface{}, servers []*OrderedObject) (AsyncAPIImportCandidate, error) {
+	key := direction + " " + address
+
+	var resolved []*OrderedObject
+	for _, message := range messages {
+		if message := r.object(message); message != nil {
+			resolved = append(resolved, message)
+		}
+	}
+
+	name := firstNonEmpty(stringField(operation, "summary"), stringField(operation, "title"))
+	description := stringField(operation, "description")
+	if len(resolved) > 0 {
+		name = firstNonEmpty(name, stringField(resolved[0], "title"), stringField(resolved[0], "summary"))
+		description = firstNonEmpty(description, stringField(resolved[0], "description"))
+	}
+	name = firstNonEmpty(name, operationID, key)
+
+	payload, err := r.payload(resolved)
+	if err != nil {
+		return AsyncAPIImportCandidate{}, fmt.Errorf("%w: %s: %v", ErrInvalidAsyncAPI, key, err)
+	}
+
+	return AsyncAPIImportCandidate{
+		Key:               key,
+		Name:              name,
+		Channel:           address,
+		Direction:         direction,
+		Protocol:          r.protocol(channel, operation, servers),
+		Description:       description,
+		PayloadParameters: payload,
+	}, nil
+}
+
+// payload converts the payload schemas of the messages of an operation into parameters
+func (r *asyncAPIReader) payload(messages []*OrderedObject) ([]ParameterNode, error) {
+	var schemas []interface{}
+	for _, message := range messages {
+		format := stringField(message, "schemaFormat")
+		schema := message.Values["payload"]
+		if multiFormat, ok := schema.(*OrderedObject); ok {
+			// Multi-format schemas of 3.x wrap the schema with its format
+			if inner, ok := multiFormat.Get("schema"); ok {
+				if _, ok := multiFormat.Get("schemaFormat"); ok {
+					format, schema = stringField(multiFormat, "schemaFormat"), inner
+				}
+			}
+		}
+		if schema == nil || !isJSONSchemaFormat(format) {
+			continue
+		}
+		schemas = append(schemas, schema)
+	}
+
+	var schema interface{}
+	switch len(schemas) {
+	case 0:
+		return []ParameterNode{}, nil
+	case 1:
+		schema = schemas[0]
+	default:
+		alternatives := NewOrderedObject()
+		alternatives.Set("oneOf", schemas)
+		schema = alternatives
+	}
+
+	nodes, err := parameterNodesFromSchema(r.root, schema)
+	if err == errSchemaNotObject {
+		// Payloads such as plain strings have no fields
+		return []ParameterNode{}, nil
+	}
+	return nodes, err
+}
+
+// protocol returns the broker protocol of an operation: the first channel or operation
+// binding, the protocol of the channel's servers, or the protocol shared by all servers
+func (r *asyncAPIReader) protocol(channel, operation *OrderedObject, servers []*OrderedObject) string {
+	for _, owner := range []*OrderedObject{channel, operation} {
+		if bindings := r.object(owner.Values["bindings"]); bindings != nil && bindings.Len() > 0 {
+			return bindings.Keys[0]
+		}
+	}
+
+	if len(servers) == 0 {
+		if definitions, ok := r.root.Values["servers"].(*OrderedObject); ok {
+			for _, name := range definitions.Keys {
+				if server := r.object(definitions.Values[name]); server != nil {
+					servers = append(servers, server)
+				}
+			}
+		}
+	}
+
+	protocol := ""
+	for _, server := range servers {
+		serverProtocol := stringField(server, "protocol")
+		if protocol != "" && serverProtocol != protocol {
+			// Servers of different protocols leave it open
+			return ""
+		}
+		protocol = serverProtocol
+	}
+	return protocol
+}
+
+// object returns a value as an object, following local $ref
+func (r *asyncAPIReader) object(value interface{}) *OrderedObject {
+	imp := &jsonSchemaImporter{root: r.root}
+	for hops := 0; hops < 10; hops++ {
+		obj, ok := value.(*OrderedObject)
+		if !ok {
+			return nil
+		}
+		ref, ok := obj.Values["$ref"].(string)
+		if !ok {
+			return obj
+		}
+		target, err := imp.resolve(ref)
+		if err != nil {
+			return nil
+		}
+		value = target
+	}
+	return nil
+}
+
+// isJSONSchemaFormat reports whether a message schema format is JSON Schema or the AsyncAPI
+// schema format derived from it, e.g. application/vnd.aai.asyncapi+json;version=3.0.0
+func isJSONSchemaFormat(format string) bool {
+	format = strings.ToLower(format)
+	return format == "" || strings.Contains(format, "asyncapi") || strings.Contains(format, "schema+json") || strings.Contains(format, "schema+yaml")
+}
+
+// stringField returns a string field of an object, trimmed, or "" if it is missing
+func stringField(obj *OrderedObject, key string) string {
+	value, _ := obj.Values[key].(string)
+	return strings.TrimSpace(value)
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
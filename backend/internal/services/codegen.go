@@ -0,0 +1,762 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+)
+
+// CodegenLanguages lists the languages typed models can be generated in
+var CodegenLanguages = []string{"typescript", "go", "java", "kotlin", "python", "pydantic"}
+
+// ErrUnsupportedLanguage is returned for languages missing from CodegenLanguages
+var ErrUnsupportedLanguage = errors.New("unsupported language")
+
+// CodegenOptions configures model generation
+type CodegenOptions struct {
+	ParamType string // request or response, both if empty
+	Package   string // Go, Java or Kotlin package; "models" for Go and none otherwise if empty
+}
+
+// codegenRef is the type of a field: a primitive (string, integer, number, boolean), any,
+// map (an object without documented fields), array, object (a named type) or union
+type codegenRef struct {
+	kind    string
+	elem    *codegenRef // Items of an array
+	name    string      // Name of an object type
+	members []string    // Primitive members of a union
+}
+
+// codegenField is a field of a generated type
+type codegenField struct {
+	name        string // JSON name
+	ref         codegenRef
+	required    bool
+	nullable    bool
+	description string
+}
+
+// codegenType is a generated type for an object with documented fields
+type codegenType struct {
+	name        string
+	description string
+	fields      []codegenField
+}
+
+// codegenModel collects the types of one generation. Nested objects become types named
+// after their parent type and field, e.g. CreateOrderRequestCustomer, and array items
+// get an Item suffix.
+type codegenModel struct {
+	types []codegenType
+	used  map[string]bool
+}
+
+// GenerateModels generates request and response types of APIs in a language of CodegenLanguages.
+// Types are named after the API, e.g. CreateOrderRequest and CreateOrderResponse for "Create order".
+func GenerateModels(apis []APIWithParams, lang string, opts CodegenOptions) (string, error) {
+	model := &codegenModel{used: make(map[string]bool)}
+
+	for _, api := range apis {
+		base := codegenTypeName(api.API.Name)
+		if base == "" {
+			base = fmt.Sprintf("API%d", api.API.ID)
+		}
+		endpoint := strings.TrimSpace(api.API.Method + " " + api.API.Endpoint)
+
+		if opts.ParamType != "response" {
+			if tree := BuildParameterTree(api.RequestParameters); len(tree) > 0 {
+				model.addType(base+"Request", "Request body of "+endpoint, tree)
+			}
+		}
+		if opts.ParamType != "request" {
+			if tree := BuildParameterTree(api.ResponseParameters); len(tree) > 0 {
+				model.addType(base+"Response", "Response body of "+endpoint, tree)
+			}
+		}
+	}
+
+	outerName := "ApiModels"
+	if len(apis) == 1 {
+		if base := codegenTypeName(apis[0].API.Name); base != "" {
+			outerName = base + "Models"
+		}
+	}
+
+	switch lang {
+	case "typescript":
+		return model.typescript(), nil
+	case "go":
+		pkg := opts.Package
+		if pkg == "" {
+			pkg = "models"
+		}
+		return model.golang(pkg), nil
+	case "java":
+		return model.java(opts.Package, outerName), nil
+	case "kotlin":
+		return model.kotlin(opts.Package), nil
+	case "python":
+		return model.python(false), nil
+	case "pydantic":
+		return model.python(true), nil
+	}
+	return "", ErrUnsupportedLanguage
+}
+
+// addType adds a type for an object with documented fields and returns its unique name.
+// The type is added before the types of its nested objects.
+func (m *codegenModel) addType(name, description string, params []models.Parameter) string {
+	name = uniqueTypeName(name, m.used)
+	index := len(m.types)
+	m.types = append(m.types, codegenType{name: name, description: description})
+
+	fields := make([]codegenField, 0, len(params))
+	for _, param := range params {
+		description := ""
+		if param.Description != nil {
+			description = strings.TrimSpace(*param.Description)
+		}
+		fields = append(fields, codegenField{
+			name:        param.Name,
+			ref:         m.ref(name+codegenTypeName(param.Name), param),
+			required:    param.Required,
+			nullable:    param.Nullable,
+			description: description,
+		})
+	}
+	m.types[index].fields = fields
+	return name
+}
+
+// ref returns the type of a parameter, adding types for nested objects named after nameHint
+func (m *codegenModel) ref(nameHint string, param models.Parameter) codegenRef {
+	if strings.Contains(param.Type, "|") {
+		var members []string
+		for _, member := range strings.Split(param.Type, "|") {
+			switch member {
+			case "string", "integer", "number", "boolean":
+				members = append(members, member)
+			default:
+				return codegenRef{kind: "any"}
+			}
+		}
+		return codegenRef{kind: "union", members: members}
+	}
+
+	switch param.Type {
+	case "string", "integer", "number", "boolean":
+		return codegenRef{kind: param.Type}
+	case "object":
+		if len(param.Children) == 0 {
+			return codegenRef{kind: "map"}
+		}
+		return codegenRef{kind: "object", name: m.addType(nameHint, "", param.Children)}
+	case "array":
+		var elem codegenRef
+		switch {
+		case len(param.Children) == 0:
+			elem = codegenRef{kind: "any"}
+		case len(param.Children) == 1 && param.Children[0].Name == "item":
+			// The single child of a primitive array describes its items
+			elem = m.ref(nameHint+"Item", param.Children[0])
+		default:
+			elem = codegenRef{kind: "object", name: m.addType(nameHint+"Item", "", param.Children)}
+		}
+		return codegenRef{kind: "array", elem: &elem}
+	}
+	return codegenRef{kind: "any"}
+}
+
+// codegenWords splits a name into words at non-alphanumeric characters and lower-to-upper
+// case changes, e.g. "user_id" and "userId" both into user and id
+func codegenWords(name string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+			flush()
+		}
+		word = append(word, r)
+	}
+	flush()
+	return words
+}
+
+// codegenPascal joins the words of a name in PascalCase
+func codegenPascal(name string) string {
+	var out strings.Builder
+	for _, word := range codegenWords(name) {
+		runes := []rune(word)
+		out.WriteRune(unicode.ToUpper(runes[0]))
+		out.WriteString(string(runes[1:]))
+	}
+	return out.String()
+}
+
+// codegenTypeName returns a PascalCase type name, prefixed when it would start with a digit
+func codegenTypeName(name string) string {
+	pascal := codegenPascal(name)
+	if pascal != "" && unicode.IsDigit([]rune(pascal)[0]) {
+		return "T" + pascal
+	}
+	return pascal
+}
+
+// codegenCamelName returns a camelCase field name, prefixed when it would start with a digit
+func codegenCamelName(name string) string {
+	pascal := codegenPascal(name)
+	if pascal == "" || unicode.IsDigit([]rune(pascal)[0]) {
+		return "field" + pascal
+	}
+	runes := []rune(pascal)
+	return string(unicode.ToLower(runes[0])) + string(runes[1:])
+}
+
+// uniqueTypeName returns name, or name with a numeric suffix if it is already used, and marks it used
+func uniqueTypeName(name string, used map[string]bool) string {
+	candidate := name
+	for i := 2; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// isPlainIdentifier reports whether a name is an ASCII identifier that is valid as is
+// in every generated language
+func isPlainIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if !(r == '_' || r < unicode.MaxASCII && unicode.IsLetter(r) || i > 0 && r < unicode.MaxASCII && unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeDocComment writes a description as a block doc comment with an indent
+func writeDocComment(out *strings.Builder, indent, description string) {
+	if description == "" {
+		return
+	}
+	lines := strings.Split(strings.ReplaceAll(description, "*/", "* /"), "\n")
+	if len(lines) == 1 {
+		out.WriteString(indent + "/** " + lines[0] + " */\n")
+		return
+	}
+	out.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		out.WriteString(strings.TrimRight(indent+" * "+strings.TrimSpace(line), " ") + "\n")
+	}
+	out.WriteString(indent + " */\n")
+}
+
+// writeLineComment writes a description as line comments with a prefix such as // or #
+func writeLineComment(out *strings.Builder, indent, prefix, description string) {
+	if description == "" {
+		return
+	}
+	for _, line := range strings.Split(description, "\n") {
+		out.WriteString(strings.TrimRight(indent+prefix+" "+strings.TrimSpace(line), " ") + "\n")
+	}
+}
+
+const codegenHeader = "Code generated by Knot from the API documentation. DO NOT EDIT."
+
+// typescript renders the types as TypeScript interfaces
+func (m *codegenModel) typescript() string {
+	var out strings.Builder
+	out.WriteString("// " + codegenHeader + "\n")
+
+	for _, t := range m.types {
+		out.WriteString("\n")
+		writeDocComment(&out, "", t.description)
+		out.WriteString("export interface " + t.name + " {\n")
+		for _, field := range t.fields {
+			writeDocComment(&out, "  ", field.description)
+
+			name := field.name
+			if !isPlainIdentifier(name) {
+				name = fmt.Sprintf("%q", name)
+			}
+			optional := ""
+			if !field.required {
+				optional = "?"
+			}
+			fieldType := typescriptType(field.ref)
+			if field.nullable {
+				fieldType += " | null"
+			}
+			out.WriteString(fmt.Sprintf("  %s%s: %s;\n", name, optional, fieldType))
+		}
+		out.WriteString("}\n")
+	}
+	return out.String()
+}
+
+func typescriptType(ref codegenRef) string {
+	switch ref.kind {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "map":
+		return "Record<string, unknown>"
+	case "object":
+		return ref.name
+	case "array":
+		elem := typescriptType(*ref.elem)
+		if ref.elem.kind == "union" {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case "union":
+		var members []string
+		for _, member := range ref.members {
+			member := typescriptType(codegenRef{kind: member})
+			if !containsString(members, member) {
+				members = append(members, member)
+			}
+		}
+		return strings.Join(members, " | ")
+	}
+	return "unknown"
+}
+
+// goInitialisms are words written in upper case in Go names
+var goInitialisms = map[string]bool{
+	"id": true, "url": true, "uri": true, "api": true, "http": true, "https": true, "json": true,
+	"uuid": true, "ip": true, "sql": true, "html": true, "xml": true, "sku": true,
+}
+
+// golang renders the types as Go structs with json tags
+func (m *codegenModel) golang(pkg string) string {
+	var out strings.Builder
+	out.WriteString("// " + codegenHeader + "\n\n")
+	out.WriteString("package " + pkg + "\n")
+
+	for _, t := range m.types {
+		out.WriteString("\n")
+		if t.description != "" {
+			writeLineComment(&out, "", "//", t.name+" is the "+lowerFirst(t.description))
+		}
+		out.WriteString("type " + t.name + " struct {\n")
+
+		used := make(map[string]bool)
+		for _, field := range t.fields {
+			writeLineComment(&out, "\t", "//", field.description)
+
+			name := goFieldName(field.name)
+			name = uniqueTypeName(name, used)
+
+			fieldType := goType(field.ref)
+			if (field.nullable || !field.required && field.ref.kind == "object") && !strings.HasPrefix(fieldType, "[]") &&
+				!strings.HasPrefix(fieldType, "map[") && fieldType != "interface{}" {
+				fieldType = "*" + fieldType
+			}
+			tag := field.name
+			if !field.required {
+				tag += ",omitempty"
+			}
+			out.WriteString(fmt.Sprintf("\t%s %s `json:%q`\n", name, fieldType, tag))
+		}
+		out.WriteString("}\n")
+	}
+
+	// Align fields like gofmt; the source is valid Go, so formatting cannot fail
+	formatted, err := format.Source([]byte(out.String()))
+	if err != nil {
+		return out.String()
+	}
+	return string(formatted)
+}
+
+// goFieldName returns the exported Go name of a JSON field, e.g. UserID for userId
+func goFieldName(name string) string {
+	var out strings.Builder
+	for _, word := range codegenWords(name) {
+		if goInitialisms[strings.ToLower(word)] {
+			out.WriteString(strings.ToUpper(word))
+			continue
+		}
+		runes := []rune(word)
+		out.WriteRune(unicode.ToUpper(runes[0]))
+		out.WriteString(string(runes[1:]))
+	}
+	result := out.String()
+	if result == "" || unicode.IsDigit([]rune(result)[0]) || !unicode.IsUpper([]rune(result)[0]) {
+		result = "Field" + result
+	}
+	return result
+}
+
+func goType(ref codegenRef) string {
+	switch ref.kind {
+	case "string":
+		return "string"
+	case "integer":
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "map":
+		return "map[string]interface{}"
+	case "object":
+		return ref.name
+	case "array":
+		return "[]" + goType(*ref.elem)
+	}
+	return "interface{}"
+}
+
+// javaKeywords are reserved words that cannot be field names in Java
+var javaKeywords = map[string]bool{
+	"abstract": true, "assert": true, "boolean": true, "break": true, "byte": true, "case": true,
+	"catch": true, "char": true, "class": true, "const": true, "continue": true, "default": true,
+	"do": true, "double": true, "else": true, "enum": true, "extends": true, "final": true,
+	"finally": true, "float": true, "for": true, "goto": true, "if": true, "implements": true,
+	"import": true, "instanceof": true, "int": true, "interface": true, "long": true, "native": true,
+	"new": true, "package": true, "private": true, "protected": true, "public": true, "return": true,
+	"short": true, "static": true, "strictfp": true, "super": true, "switch": true, "synchronized": true,
+	"this": true, "throw": true, "throws": true, "transient": true, "try": true, "void": true,
+	"volatile": true, "while": true, "true": true, "false": true, "null": true, "record": true,
+}
+
+// java renders the types as static classes with Jackson annotations, nested in one outer class
+func (m *codegenModel) java(pkg, outerName string) string {
+	var body strings.Builder
+	usesList, usesMap := false, false
+
+	for i, t := range m.types {
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		writeDocComment(&body, "    ", t.description)
+		body.WriteString("    public static class " + t.name + " {\n")
+
+		used := make(map[string]bool)
+		for j, field := range t.fields {
+			if j > 0 {
+				body.WriteString("\n")
+			}
+			writeDocComment(&body, "        ", field.description)
+
+			name := codegenCamelName(field.name)
+			if javaKeywords[name] {
+				name += "_"
+			}
+			name = uniqueTypeName(name, used)
+
+			fieldType := javaType(field.ref)
+			usesList = usesList || strings.Contains(fieldType, "List<")
+			usesMap = usesMap || strings.Contains(fieldType, "Map<")
+
+			if field.required {
+				body.WriteString(fmt.Sprintf("        @JsonProperty(value = %q, required = true)\n", field.name))
+			} else {
+				body.WriteString(fmt.Sprintf("        @JsonProperty(%q)\n", field.name))
+			}
+			body.WriteString(fmt.Sprintf("        public %s %s;\n", fieldType, name))
+		}
+		body.WriteString("    }\n")
+	}
+
+	var out strings.Builder
+	out.WriteString("// " + codegenHeader + "\n\n")
+	if pkg != "" {
+		out.WriteString("package " + pkg + ";\n\n")
+	}
+	out.WriteString("import com.fasterxml.jackson.annotation.JsonProperty;\n")
+	if usesList {
+		out.WriteString("import java.util.List;\n")
+	}
+	if usesMap {
+		out.WriteString("import java.util.Map;\n")
+	}
+	out.WriteString("\npublic final class " + outerName + " {\n")
+	out.WriteString("    private " + outerName + "() {\n    }\n")
+	if body.Len() > 0 {
+		out.WriteString("\n" + body.String())
+	}
+	out.WriteString("}\n")
+	return out.String()
+}
+
+func javaType(ref codegenRef) string {
+	switch ref.kind {
+	case "string":
+		return "String"
+	case "integer":
+		return "Long"
+	case "number":
+		return "Double"
+	case "boolean":
+		return "Boolean"
+	case "map":
+		return "Map<String, Object>"
+	case "object":
+		return ref.name
+	case "array":
+		return "List<" + javaType(*ref.elem) + ">"
+	}
+	return "Object"
+}
+
+// kotlinKeywords are hard keywords that must be quoted as field names in Kotlin
+var kotlinKeywords = map[string]bool{
+	"as": true, "break": true, "class": true, "continue": true, "do": true, "else": true,
+	"false": true, "for": true, "fun": true, "if": true, "in": true, "interface": true, "is": true,
+	"null": true, "object": true, "package": true, "return": true, "super": true, "this": true,
+	"throw": true, "true": true, "try": true, "typealias": true, "typeof": true, "val": true,
+	"var": true, "when": true, "while": true,
+}
+
+// kotlin renders the types as kotlinx.serialization data classes
+func (m *codegenModel) kotlin(pkg string) string {
+	var body strings.Builder
+	usesElement, usesObject := false, false
+
+	for _, t := range m.types {
+		body.WriteString("\n")
+		writeDocComment(&body, "", t.description)
+		body.WriteString("@Serializable\ndata class " + t.name + "(\n")
+
+		used := make(map[string]bool)
+		for _, field := range t.fields {
+			writeDocComment(&body, "    ", field.description)
+
+			name := uniqueTypeName(codegenCamelName(field.name), used)
+			if name != field.name {
+				body.WriteString(fmt.Sprintf("    @SerialName(%q)\n", field.name))
+			}
+			if kotlinKeywords[name] {
+				name = "`" + name + "`"
+			}
+
+			fieldType := kotlinType(field.ref)
+			usesElement = usesElement || strings.Contains(fieldType, "JsonElement")
+			usesObject = usesObject || strings.Contains(fieldType, "JsonObject")
+
+			switch {
+			case !field.required:
+				body.WriteString(fmt.Sprintf("    val %s: %s? = null,\n", name, fieldType))
+			case field.nullable:
+				body.WriteString(fmt.Sprintf("    val %s: %s?,\n", name, fieldType))
+			default:
+				body.WriteString(fmt.Sprintf("    val %s: %s,\n", name, fieldType))
+			}
+		}
+		body.WriteString(")\n")
+	}
+
+	var out strings.Builder
+	out.WriteString("// " + codegenHeader + "\n\n")
+	if pkg != "" {
+		out.WriteString("package " + pkg + "\n\n")
+	}
+	out.WriteString("import kotlinx.serialization.SerialName\nimport kotlinx.serialization.Serializable\n")
+	if usesElement {
+		out.WriteString("import kotlinx.serialization.json.JsonElement\n")
+	}
+	if usesObject {
+		out.WriteString("import kotlinx.serialization.json.JsonObject\n")
+	}
+	out.WriteString(body.String())
+	return out.String()
+}
+
+func kotlinType(ref codegenRef) string {
+	switch ref.kind {
+	case "string":
+		return "String"
+	case "integer":
+		return "Long"
+	case "number":
+		return "Double"
+	case "boolean":
+		return "Boolean"
+	case "map":
+		return "JsonObject"
+	case "object":
+		return ref.name
+	case "array":
+		return "List<" + kotlinType(*ref.elem) + ">"
+	}
+	return "JsonElement"
+}
+
+// pythonKeywords are reserved words that cannot be field names in Python
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true,
+	"await": true, "break": true, "class": true, "continue": true, "def": true, "del": true,
+	"elif": true, "else": true, "except": true, "finally": true, "for": true, "from": true,
+	"global": true, "if": true, "import": true, "in": true, "is": true, "lambda": true,
+	"nonlocal": true, "not": true, "or": true, "pass": true, "raise": true, "return": true,
+	"try": true, "while": true, "with": true, "yield": true,
+}
+
+// python renders the types as dataclasses, or as pydantic models. Nested types are written
+// before the types that use them.
+func (m *codegenModel) python(pydantic bool) string {
+	var body strings.Builder
+	typingNames := map[string]bool{}
+	usesField := false
+
+	for i := len(m.types) - 1; i >= 0; i-- {
+		t := m.types[i]
+		body.WriteString("\n\n")
+		if pydantic {
+			body.WriteString("class " + t.name + "(BaseModel):\n")
+		} else {
+			body.WriteString("@dataclass\nclass " + t.name + ":\n")
+		}
+		if t.description != "" {
+			body.WriteString(fmt.Sprintf("    %q\n\n", t.description))
+		}
+
+		// Dataclass fields with defaults must follow the fields without
+		fields := append([]codegenField{}, t.fields...)
+		if !pydantic {
+			sort.SliceStable(fields, func(a, b int) bool {
+				return fields[a].required && !fields[b].required
+			})
+		}
+
+		used := make(map[string]bool)
+		for _, field := range fields {
+			writeLineComment(&body, "    ", "#", field.description)
+
+			name := field.name
+			if !isPlainIdentifier(name) || pythonKeywords[name] {
+				name = strings.ToLower(strings.Join(codegenWords(name), "_"))
+				if name == "" || unicode.IsDigit([]rune(name)[0]) || pythonKeywords[name] {
+					name = "field_" + name
+				}
+			}
+			name = uniqueTypeName(name, used)
+
+			fieldType := pythonType(field.ref, typingNames)
+			if field.nullable || !field.required {
+				fieldType = "Optional[" + fieldType + "]"
+				typingNames["Optional"] = true
+			}
+
+			var args []string
+			if !field.required {
+				args = append(args, "default=None")
+			}
+			if name != field.name {
+				if !pydantic {
+					body.WriteString(fmt.Sprintf("    # JSON name: %q\n", field.name))
+				} else {
+					args = append(args, fmt.Sprintf("alias=%q", field.name))
+				}
+			}
+
+			switch {
+			case pydantic && name != field.name:
+				usesField = true
+				body.WriteString(fmt.Sprintf("    %s: %s = Field(%s)\n", name, fieldType, strings.Join(args, ", ")))
+			case !field.required:
+				body.WriteString(fmt.Sprintf("    %s: %s = None\n", name, fieldType))
+			default:
+				body.WriteString(fmt.Sprintf("    %s: %s\n", name, fieldType))
+			}
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString("# " + codegenHeader + "\n\n")
+	if !pydantic {
+		out.WriteString("from dataclasses import dataclass\n")
+	}
+	if len(typingNames) > 0 {
+		names := make([]string, 0, len(typingNames))
+		for name := range typingNames {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		out.WriteString("from typing import " + strings.Join(names, ", ") + "\n")
+	}
+	if pydantic {
+		if usesField {
+			out.WriteString("\nfrom pydantic import BaseModel, Field\n")
+		} else {
+			out.WriteString("\nfrom pydantic import BaseModel\n")
+		}
+	}
+	out.WriteString(body.String())
+	return out.String()
+}
+
+func pythonType(ref codegenRef, typingNames map[string]bool) string {
+	switch ref.kind {
+	case "string":
+		return "str"
+	case "integer":
+		return "int"
+	case "number":
+		return "float"
+	case "boolean":
+		return "bool"
+	case "map":
+		typingNames["Any"] = true
+		typingNames["Dict"] = true
+		return "Dict[str, Any]"
+	case "object":
+		return ref.name
+	case "array":
+		typingNames["List"] = true
+		return "List[" + pythonType(*ref.elem, typingNames) + "]"
+	case "union":
+		typingNames["Union"] = true
+		var members []string
+		for _, member := range ref.members {
+			members = append(members, pythonType(codegenRef{kind: member}, typingNames))
+		}
+		return "Union[" + strings.Join(members, ", ") + "]"
+	}
+	typingNames["Any"] = true
+	return "Any"
+}
+
+// lowerFirst lower-cases the first letter of a sentence
+func lowerFirst(s string) string {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return s
+	}
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"regexp"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w][\w.-]*)`)
+
+// ParseMentions extracts the unique @mentions from a comment body in order of appearance
+func ParseMentions(body string) []string {
+	mentions := []string{}
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		name := match[1]
+		if !seen[name] {
+			seen[name] = true
+			mentions = append(mentions, name)
+		}
+	}
+	return mentions
+}
+
+// CountUnresolvedComments returns the number of unresolved comment threads per API.
+// When apiIDs is empty, counts for all APIs are returned.
+func CountUnresolvedComments(db *gorm.DB, apiIDs []uint) (map[uint]int64, error) {
+	var rows []struct {
+		APIID uint
+		Count int64
+	}
+
+	query := db.Model(&models.Comment{}).
+		Select("api_id, COUNT(*) AS count").
+		Where("parent_id IS NULL AND resolved = ?", false)
+	if len(apiIDs) > 0 {
+		query = query.Where("api_id IN ?", apiIDs)
+	}
+
+	if err := query.Group("api_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.APIID] = row.Count
+	}
+	return counts, nil
+}
+
+// LoadCommentThreads loads the comment threads of an API with their replies, oldest first.
+// Resolved threads are skipped unless includeResolved is set.
+func LoadCommentThreads(db *gorm.DB, apiID uint, parameterID *uint, includeResolved bool) ([]models.Comment, error) {
+	query := db.Where("api_id = ? AND parent_id IS NULL", apiID)
+	if parameterID != nil {
+		query = query.Where("parameter_id = ?", *parameterID)
+	}
+	if !includeResolved {
+		query = query.Where("resolved = ?", false)
+	}
+
+	var threads []models.Comment
+	err := query.Preload("Replies", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Order("id ASC").Find(&threads).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if threads == nil {
+		threads = []models.Comment{}
+	}
+	return threads, nil
+}
+
+// ParameterPaths maps every parameter ID in a tree to its dotted path, e.g. "items[].id"
+func ParameterPaths(tree []models.Parameter) map[uint]string {
+	paths := make(map[uint]string)
+
+	var walk func(params []models.Parameter, prefix string)
+	walk = func(params []models.Parameter, prefix string) {
+		for _, param := range params {
+			path := param.Name
+			if prefix != "" {
+				path = prefix + "." + param.Name
+			}
+			paths[param.ID] = path
+
+			childPrefix := path
+			if param.Type == "array" {
+				childPrefix = path + "[]"
+			}
+			walk(param.Children, childPrefix)
+		}
+	}
+	walk(tree, "")
+
+	return paths
+}
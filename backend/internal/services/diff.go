@@ -0,0 +1,174 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+)
+
+// FieldChange describes a single difference between two versions of an entity
+type FieldChange struct {
+	Path   string      `json:"path"`
+	Op     string      `json:"op"` // added, removed or changed
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// DiffValues compares two JSON-serializable values and returns every changed path
+func DiffValues(before, after interface{}) []FieldChange {
+	normalizedBefore := normalizeJSON(before)
+	normalizedAfter := normalizeJSON(after)
+
+	// Report created or deleted entities field by field
+	if _, ok := normalizedAfter.(map[string]interface{}); ok && normalizedBefore == nil {
+		normalizedBefore = map[string]interface{}{}
+	}
+	if _, ok := normalizedBefore.(map[string]interface{}); ok && normalizedAfter == nil {
+		normalizedAfter = map[string]interface{}{}
+	}
+
+	changes := []FieldChange{}
+	diffNormalized("", normalizedBefore, normalizedAfter, &changes)
+	return changes
+}
+
+// normalizeJSON converts a value into its generic JSON representation
+func normalizeJSON(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil
+	}
+	return normalized
+}
+
+func diffNormalized(path string, before, after interface{}, changes *[]FieldChange) {
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+	if beforeIsMap && afterIsMap {
+		keys := make([]string, 0, len(beforeMap)+len(afterMap))
+		for key := range beforeMap {
+			keys = append(keys, key)
+		}
+		for key := range afterMap {
+			if _, exists := beforeMap[key]; !exists {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+
+			beforeValue, inBefore := beforeMap[key]
+			afterValue, inAfter := afterMap[key]
+			switch {
+			case !inBefore:
+				*changes = append(*changes, FieldChange{Path: childPath, Op: "added", After: afterValue})
+			case !inAfter:
+				*changes = append(*changes, FieldChange{Path: childPath, Op: "removed", Before: beforeValue})
+			default:
+				diffNormalized(childPath, beforeValue, afterValue, changes)
+			}
+		}
+		return
+	}
+
+	beforeList, beforeIsList := before.([]interface{})
+	afterList, afterIsList := after.([]interface{})
+	if beforeIsList && afterIsList {
+		length := len(beforeList)
+		if len(afterList) > length {
+			length = len(afterList)
+		}
+
+		for i := 0; i < length; i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(beforeList):
+				*changes = append(*changes, FieldChange{Path: childPath, Op: "added", After: afterList[i]})
+			case i >= len(afterList):
+				*changes = append(*changes, FieldChange{Path: childPath, Op: "removed", Before: beforeList[i]})
+			default:
+				diffNormalized(childPath, beforeList[i], afterList[i], changes)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		switch {
+		case before == nil:
+			*changes = append(*changes, FieldChange{Path: path, Op: "added", After: after})
+		case after == nil:
+			*changes = append(*changes, FieldChange{Path: path, Op: "removed", Before: before})
+		default:
+			*changes = append(*changes, FieldChange{Path: path, Op: "changed", Before: before, After: after})
+		}
+	}
+}
+
+// GroupSnapshot returns the documented fields of a group used for change diffs
+func GroupSnapshot(group models.Group) map[string]interface{} {
+	return map[string]interface{}{
+		"name": group.Name,
+	}
+}
+
+// APISnapshot returns the documented fields of an API used for change diffs
+func APISnapshot(api models.API) map[string]interface{} {
+	return map[string]interface{}{
+		"name":     api.Name,
+		"endpoint": api.Endpoint,
+		"method":   api.Method,
+		"type":     api.Type,
+		"protocol": api.Protocol,
+		"note":     api.Note,
+	}
+}
+
+// ParameterSnapshot flattens a parameter tree into dotted paths for change diffs.
+// Fields of array items are addressed as "items[].field".
+func ParameterSnapshot(tree []models.Parameter) map[string]interface{} {
+	snapshot := make(map[string]interface{})
+
+	var walk func(params []models.Parameter, prefix string)
+	walk = func(params []models.Parameter, prefix string) {
+		for _, param := range params {
+			path := param.Name
+			if prefix != "" {
+				path = prefix + "." + param.Name
+			}
+
+			snapshot[path] = map[string]interface{}{
+				"type":        param.Type,
+				"required":    param.Required,
+				"nullable":    param.Nullable,
+				"description": param.Description,
+			}
+
+			childPrefix := path
+			if param.Type == "array" {
+				childPrefix = path + "[]"
+			}
+			walk(param.Children, childPrefix)
+		}
+	}
+	walk(tree, "")
+
+	return snapshot
+}
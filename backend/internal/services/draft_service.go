@@ -0,0 +1,454 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Draft errors
+var (
+	// ErrDraftNotOpen is returned when a draft that is already approved or rejected is modified
+	ErrDraftNotOpen = errors.New("draft is not open")
+	// ErrDraftWithoutTarget is returned when a draft has neither an API nor a group
+	ErrDraftWithoutTarget = errors.New("draft has no API or group")
+	// ErrDraftConflict is returned when the API of a draft was changed after the draft was opened
+	ErrDraftConflict = errors.New("API was changed after the draft was opened")
+)
+
+// DraftPreview shows a draft next to the published API it changes
+type DraftPreview struct {
+	Draft     models.APIDraft `json:"draft"`
+	Published APIVersion      `json:"published"`
+	Proposed  APIVersion      `json:"proposed"`
+	Diff      []FieldChange   `json:"diff"`
+}
+
+// APIVersion is a complete view of an API's documentation at one point in time
+type APIVersion struct {
+	Name               string             `json:"name"`
+	Endpoint           string             `json:"endpoint"`
+	Method             string             `json:"method"`
+	Type               string             `json:"type"`
+	Protocol           *string            `json:"protocol"`
+	Note               *string            `json:"note"`
+	RequestParameters  []models.Parameter `json:"requestParameters"`
+	ResponseParameters []models.Parameter `json:"responseParameters"`
+	RequestExample     interface{}        `json:"requestExample"`
+	ResponseExample    interface{}        `json:"responseExample"`
+}
+
+// snapshot returns the fields of a version used for change diffs
+func (v APIVersion) snapshot() map[string]interface{} {
+	return map[string]interface{}{
+		"api": APISnapshot(models.API{
+			Name:     v.Name,
+			Endpoint: v.Endpoint,
+			Method:   v.Method,
+			Type:     v.Type,
+			Protocol: v.Protocol,
+			Note:     v.Note,
+		}),
+		"request":  ParameterSnapshot(v.RequestParameters),
+		"response": ParameterSnapshot(v.ResponseParameters),
+	}
+}
+
+// newAPIVersion builds a version from basic info and parameter trees
+func newAPIVersion(api models.API, requestTree, responseTree []models.Parameter) APIVersion {
+	version := APIVersion{
+		Name:               api.Name,
+		Endpoint:           api.Endpoint,
+		Method:             api.Method,
+		Type:               api.Type,
+		Protocol:           api.Protocol,
+		Note:               api.Note,
+		RequestParameters:  requestTree,
+		ResponseParameters: responseTree,
+	}
+	if len(requestTree) > 0 {
+		version.RequestExample = GenerateExampleJSON(requestTree)
+	}
+	if len(responseTree) > 0 {
+		version.ResponseExample = GenerateExampleJSON(responseTree)
+	}
+	return version
+}
+
+// LoadPublishedVersion loads the published documentation of an API
+func LoadPublishedVersion(db *gorm.DB, api models.API) (APIVersion, error) {
+	var params []models.Parameter
+	if err := db.Where("api_id = ?", api.ID).Order("`order` ASC").Find(&params).Error; err != nil {
+		return APIVersion{}, err
+	}
+
+	requestParams := make([]models.Parameter, 0)
+	responseParams := make([]models.Parameter, 0)
+	for _, p := range params {
+		if p.ParamType == "request" {
+			requestParams = append(requestParams, p)
+		} else if p.ParamType == "response" {
+			responseParams = append(responseParams, p)
+		}
+	}
+
+	return newAPIVersion(api, BuildParameterTree(requestParams), BuildParameterTree(responseParams)), nil
+}
+
+// DraftParameterNodes returns the proposed request or response tree of a draft
+func DraftParameterNodes(draft models.APIDraft, paramType string) ([]ParameterNode, error) {
+	raw := draft.RequestParameters
+	if paramType == "response" {
+		raw = draft.ResponseParameters
+	}
+
+	nodes := []ParameterNode{}
+	if raw == "" {
+		return nodes, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &nodes); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// SetDraftParameterNodes stores a proposed request or response tree in a draft
+func SetDraftParameterNodes(draft *models.APIDraft, paramType string, nodes []ParameterNode) error {
+	if nodes == nil {
+		nodes = []ParameterNode{}
+	}
+
+	data, err := json.Marshal(nodes)
+	if err != nil {
+		return err
+	}
+
+	if paramType == "response" {
+		draft.ResponseParameters = string(data)
+	} else {
+		draft.RequestParameters = string(data)
+	}
+	return nil
+}
+
+// ProposedVersion returns the documentation of an API as proposed by a draft
+func ProposedVersion(draft models.APIDraft) (APIVersion, error) {
+	requestNodes, err := DraftParameterNodes(draft, "request")
+	if err != nil {
+		return APIVersion{}, err
+	}
+	responseNodes, err := DraftParameterNodes(draft, "response")
+	if err != nil {
+		return APIVersion{}, err
+	}
+
+	api := models.API{
+		Name:     draft.Name,
+		Endpoint: draft.Endpoint,
+		Method:   draft.Method,
+		Type:     draft.Type,
+		Protocol: draft.Protocol,
+		Note:     draft.Note,
+	}
+
+	var apiID uint
+	if draft.APIID != nil {
+		apiID = *draft.APIID
+	}
+
+	return newAPIVersion(api,
+		ParametersFromNodes(requestNodes, apiID, "request"),
+		ParametersFromNodes(responseNodes, apiID, "response"),
+	), nil
+}
+
+// LatestChangeID returns the ID of the last change record of an API, or 0 if none
+// was recorded. Drafts keep it as the version of the API they are based on.
+func LatestChangeID(db *gorm.DB, apiID uint) (uint, error) {
+	var id uint
+	err := db.Model(&models.ChangeRecord{}).Where("api_id = ?", apiID).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+// OpenDraft returns the open draft of an API, creating one seeded from the
+// published version if none exists. Drafts of the recording proxy are kept apart,
+// so recorded traffic never overwrites edits made by people.
+func OpenDraft(db *gorm.DB, api models.API, author string) (models.APIDraft, error) {
+	var draft models.APIDraft
+	err := db.Where("api_id = ? AND status = ? AND author <> ?", api.ID, models.DraftStatusOpen, RecorderAuthor).
+		Order("id DESC").First(&draft).Error
+	if err == gorm.ErrRecordNotFound {
+		return newDraft(db, api, author)
+	}
+	return draft, err
+}
+
+// newDraft opens a draft of an API seeded from its published version
+func newDraft(db *gorm.DB, api models.API, author string) (models.APIDraft, error) {
+	var draft models.APIDraft
+	published, err := LoadPublishedVersion(db, api)
+	if err != nil {
+		return draft, err
+	}
+	baseChangeID, err := LatestChangeID(db, api.ID)
+	if err != nil {
+		return draft, err
+	}
+
+	apiID, groupID := api.ID, api.GroupID
+	draft = models.APIDraft{
+		APIID:        &apiID,
+		GroupID:      &groupID,
+		Status:       models.DraftStatusOpen,
+		Author:       author,
+		Name:         api.Name,
+		Endpoint:     api.Endpoint,
+		Method:       api.Method,
+		Type:         api.Type,
+		Protocol:     api.Protocol,
+		Note:         api.Note,
+		BaseChangeID: baseChangeID,
+	}
+	if err := SetDraftParameterNodes(&draft, "request", NodesFromParameters(published.RequestParameters)); err != nil {
+		return draft, err
+	}
+	if err := SetDraftParameterNodes(&draft, "response", NodesFromParameters(published.ResponseParameters)); err != nil {
+		return draft, err
+	}
+
+	if err := db.Create(&draft).Error; err != nil {
+		return draft, err
+	}
+	return draft, nil
+}
+
+// ProposeNewAPI opens a draft proposing a new API in the group of api
+func ProposeNewAPI(db *gorm.DB, api models.API, author string) (models.APIDraft, error) {
+	groupID := api.GroupID
+	draft := models.APIDraft{
+		GroupID:  &groupID,
+		Status:   models.DraftStatusOpen,
+		Author:   author,
+		Name:     api.Name,
+		Endpoint: api.Endpoint,
+		Method:   api.Method,
+		Type:     api.Type,
+		Protocol: api.Protocol,
+		Note:     api.Note,
+	}
+	if err := SetDraftParameterNodes(&draft, "request", nil); err != nil {
+		return draft, err
+	}
+	if err := SetDraftParameterNodes(&draft, "response", nil); err != nil {
+		return draft, err
+	}
+
+	if err := db.Create(&draft).Error; err != nil {
+		return draft, err
+	}
+	return draft, nil
+}
+
+// PreviewDraft compares a draft with the published version of its API.
+// Drafts proposing a new API are compared with an empty version.
+func PreviewDraft(db *gorm.DB, draft models.APIDraft) (DraftPreview, error) {
+	published := newAPIVersion(models.API{}, []models.Parameter{}, []models.Parameter{})
+	if draft.APIID != nil {
+		var api models.API
+		if err := db.First(&api, *draft.APIID).Error; err != nil {
+			return DraftPreview{}, err
+		}
+
+		var err error
+		if published, err = LoadPublishedVersion(db, api); err != nil {
+			return DraftPreview{}, err
+		}
+	}
+
+	proposed, err := ProposedVersion(draft)
+	if err != nil {
+		return DraftPreview{}, err
+	}
+
+	return DraftPreview{
+		Draft:     draft,
+		Published: published,
+		Proposed:  proposed,
+		Diff:      DiffValues(published.snapshot(), proposed.snapshot()),
+	}, nil
+}
+
+// ApproveDraft merges an open draft into the published API and its parameters
+// in a single transaction and notifies subscribers about the published changes.
+// A draft proposing a new API creates it at the end of its group. Proposed parameters
+// that carry the ID of a published parameter keep it. A draft whose API was changed after
+// it was opened is refused with ErrDraftConflict, since approving it would revert the change;
+// RebaseDraft accepts it once the preview was reviewed.
+func ApproveDraft(db *gorm.DB, draft models.APIDraft, reviewer string, comment *string) (models.APIDraft, error) {
+	if draft.Status != models.DraftStatusOpen {
+		return draft, ErrDraftNotOpen
+	}
+	if draft.APIID == nil && draft.GroupID == nil {
+		return draft, ErrDraftWithoutTarget
+	}
+
+	requestNodes, err := DraftParameterNodes(draft, "request")
+	if err != nil {
+		return draft, err
+	}
+	responseNodes, err := DraftParameterNodes(draft, "response")
+	if err != nil {
+		return draft, err
+	}
+
+	var api models.API
+	var before APIVersion
+	created := draft.APIID == nil
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if created {
+			var maxOrder int
+			tx.Model(&models.API{}).Where("group_id = ?", *draft.GroupID).Select("COALESCE(MAX(`order`), 0)").Scan(&maxOrder)
+			api = models.API{GroupID: *draft.GroupID, Order: maxOrder + 1}
+			before = newAPIVersion(models.API{}, []models.Parameter{}, []models.Parameter{})
+		} else {
+			if err := tx.First(&api, *draft.APIID).Error; err != nil {
+				return err
+			}
+			latest, err := LatestChangeID(tx, api.ID)
+			if err != nil {
+				return err
+			}
+			if latest != draft.BaseChangeID {
+				return ErrDraftConflict
+			}
+			if before, err = LoadPublishedVersion(tx, api); err != nil {
+				return err
+			}
+		}
+
+		api.Name = draft.Name
+		api.Endpoint = draft.Endpoint
+		api.Method = draft.Method
+		api.Type = draft.Type
+		api.Protocol = draft.Protocol
+		api.Note = draft.Note
+		if err := tx.Save(&api).Error; err != nil {
+			return err
+		}
+		draft.APIID = &api.ID
+
+		if _, err := SyncParameterTree(tx, api.ID, "request", requestNodes); err != nil {
+			return err
+		}
+		if _, err := SyncParameterTree(tx, api.ID, "response", responseNodes); err != nil {
+			return err
+		}
+
+		markReviewed(&draft, models.DraftStatusApproved, reviewer, comment)
+		return tx.Save(&draft).Error
+	})
+	if err != nil {
+		return draft, err
+	}
+
+	after, err := LoadPublishedVersion(db, api)
+	if err != nil {
+		return draft, nil
+	}
+
+	beforeAPI := before.snapshot()["api"]
+	afterAPI := after.snapshot()["api"]
+	if created {
+		PublishChange(db, ChangeEvent{
+			Type:    EventAPICreated,
+			GroupID: api.GroupID,
+			APIID:   api.ID,
+			Entity:  api,
+			Diff:    DiffValues(nil, afterAPI),
+		})
+	} else if diff := DiffValues(beforeAPI, afterAPI); len(diff) > 0 {
+		PublishChange(db, ChangeEvent{
+			Type:    EventAPIUpdated,
+			GroupID: api.GroupID,
+			APIID:   api.ID,
+			Entity:  api,
+			Diff:    diff,
+		})
+	}
+
+	for _, paramType := range []string{"request", "response"} {
+		beforeTree, afterTree := before.RequestParameters, after.RequestParameters
+		if paramType == "response" {
+			beforeTree, afterTree = before.ResponseParameters, after.ResponseParameters
+		}
+
+		diff := DiffValues(
+			map[string]interface{}{paramType: ParameterSnapshot(beforeTree)},
+			map[string]interface{}{paramType: ParameterSnapshot(afterTree)},
+		)
+		if len(diff) > 0 {
+			PublishChange(db, ChangeEvent{
+				Type:    EventParametersUpdated,
+				GroupID: api.GroupID,
+				APIID:   api.ID,
+				Entity: map[string]interface{}{
+					"api":        api,
+					"paramType":  paramType,
+					"parameters": afterTree,
+				},
+				Diff: diff,
+			})
+		}
+	}
+
+	return draft, nil
+}
+
+// RebaseDraft bases an open draft on the current published version of its API. The
+// proposed content is kept, so the preview shows what approving the draft replaces
+// from the changes made since it was opened.
+func RebaseDraft(db *gorm.DB, draft models.APIDraft) (models.APIDraft, error) {
+	if draft.Status != models.DraftStatusOpen {
+		return draft, ErrDraftNotOpen
+	}
+	if draft.APIID == nil {
+		return draft, nil
+	}
+
+	latest, err := LatestChangeID(db, *draft.APIID)
+	if err != nil {
+		return draft, err
+	}
+	draft.BaseChangeID = latest
+	if err := db.Save(&draft).Error; err != nil {
+		return draft, err
+	}
+	return draft, nil
+}
+
+// RejectDraft closes an open draft without merging it
+func RejectDraft(db *gorm.DB, draft models.APIDraft, reviewer string, comment *string) (models.APIDraft, error) {
+	if draft.Status != models.DraftStatusOpen {
+		return draft, ErrDraftNotOpen
+	}
+
+	markReviewed(&draft, models.DraftStatusRejected, reviewer, comment)
+	if err := db.Save(&draft).Error; err != nil {
+		return draft, err
+	}
+	return draft, nil
+}
+
+// markReviewed records the review outcome on a draft
+func markReviewed(draft *models.APIDraft, status, reviewer string, comment *string) {
+	now := time.Now().Unix()
+	draft.Status = status
+	draft.ReviewedAt = &now
+	draft.ReviewComment = comment
+	if reviewer != "" {
+		draft.ReviewedBy = &reviewer
+	}
+}
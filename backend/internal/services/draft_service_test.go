@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+)
+
+func TestApproveDraftRefusesOutdatedDraft(t *testing.T) {
+	db := newTestDB(t)
+	group := models.Group{Name: "orders"}
+	if err := db.Create(&group).Error; err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	api := models.API{GroupID: group.ID, Name: "Get order", Endpoint: "/orders/:id", Method: "GET", Type: "HTTP"}
+	if err := db.Create(&api).Error; err != nil {
+		t.Fatalf("create API: %v", err)
+	}
+
+	draft, err := OpenDraft(db, api, "alice")
+	if err != nil {
+		t.Fatalf("OpenDraft: %v", err)
+	}
+	draft.Name = "Fetch order"
+	if err := db.Save(&draft).Error; err != nil {
+		t.Fatalf("save draft: %v", err)
+	}
+
+	// Someone publishes a change while the draft is open
+	api.Endpoint = "/v2/orders/:id"
+	if err := db.Save(&api).Error; err != nil {
+		t.Fatalf("update API: %v", err)
+	}
+	if err := RecordChange(db, ChangeEvent{Type: EventAPIUpdated, GroupID: group.ID, APIID: api.ID}); err != nil {
+		t.Fatalf("RecordChange: %v", err)
+	}
+
+	if _, err := ApproveDraft(db, draft, "bob", nil); err != ErrDraftConflict {
+		t.Fatalf("ApproveDraft error = %v, want ErrDraftConflict", err)
+	}
+	var stored models.API
+	db.First(&stored, api.ID)
+	if stored.Name != "Get order" || stored.Endpoint != "/v2/orders/:id" {
+		t.Fatalf("API = %s %s, want the published change untouched", stored.Name, stored.Endpoint)
+	}
+
+	draft, err = RebaseDraft(db, draft)
+	if err != nil {
+		t.Fatalf("RebaseDraft: %v", err)
+	}
+	draft, err = ApproveDraft(db, draft, "bob", nil)
+	if err != nil {
+		t.Fatalf("ApproveDraft after rebase: %v", err)
+	}
+	if draft.Status != models.DraftStatusApproved {
+		t.Errorf("status = %s, want approved", draft.Status)
+	}
+	db.First(&stored, api.ID)
+	if stored.Name != "Fetch order" {
+		t.Errorf("name = %s, want the draft's name", stored.Name)
+	}
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// BaseURLVariable is the environment variable that holds the server address endpoints are relative to
+const BaseURLVariable = "baseUrl"
+
+// HeaderVariablePrefix marks environment variables that are sent as request headers,
+// e.g. "header.Authorization" with the value "Bearer {{token}}"
+const HeaderVariablePrefix = "header."
+
+// ErrEnvironmentNotFound is returned when no global or group environment has the requested name
+var ErrEnvironmentNotFound = errors.New("environment not found")
+
+// templatePattern matches "{{name}}" placeholders, allowing spaces inside the braces
+var templatePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+// EnvironmentVariableInput is a variable as submitted by a client.
+// A secret submitted without a value, or with the masked value, keeps its stored value.
+type EnvironmentVariableInput struct {
+	Key    string  `json:"key"`
+	Value  *string `json:"value"`
+	Secret bool    `json:"secret"`
+}
+
+// ResolvedEnvironment holds the decrypted variables of an environment as seen by one group.
+// A nil environment resolves nothing and masks nothing.
+type ResolvedEnvironment struct {
+	Name      string
+	Variables map[string]string
+	secrets   []string
+}
+
+// LoadEnvironment loads the environment with the given name for a group.
+// Variables of a group environment override those of the global environment with the same name.
+func LoadEnvironment(db *gorm.DB, name string, groupID uint) (*ResolvedEnvironment, error) {
+	var environments []models.Environment
+	if err := db.Preload("Variables").
+		Where("name = ? AND (group_id IS NULL OR group_id = ?)", name, groupID).
+		Find(&environments).Error; err != nil {
+		return nil, err
+	}
+	if len(environments) == 0 {
+		return nil, ErrEnvironmentNotFound
+	}
+
+	// Apply the global environment first so group variables win
+	sort.SliceStable(environments, func(i, j int) bool {
+		return environments[i].GroupID == nil && environments[j].GroupID != nil
+	})
+
+	resolved := &ResolvedEnvironment{Name: name, Variables: make(map[string]string)}
+	secrets := make(map[string]string)
+	for _, environment := range environments {
+		for _, variable := range environment.Variables {
+			value := variable.Value
+			if variable.Secret {
+				plain, err := DecryptSecret(value)
+				if err != nil {
+					return nil, err
+				}
+				value = plain
+				secrets[variable.Key] = plain
+			} else {
+				delete(secrets, variable.Key)
+			}
+			resolved.Variables[variable.Key] = value
+		}
+	}
+
+	for _, secret := range secrets {
+		if secret != "" {
+			resolved.secrets = append(resolved.secrets, secret)
+		}
+	}
+	// Mask longer secrets first so a secret containing another is fully hidden
+	sort.Slice(resolved.secrets, func(i, j int) bool {
+		return len(resolved.secrets[i]) > len(resolved.secrets[j])
+	})
+
+	return resolved, nil
+}
+
+// Resolve replaces "{{name}}" placeholders with variable values.
+// Unknown placeholders are left unchanged.
+func (e *ResolvedEnvironment) Resolve(template string) string {
+	if e == nil {
+		return template
+	}
+	return templatePattern.ReplaceAllStringFunc(template, func(match string) string {
+		name := templatePattern.FindStringSubmatch(match)[1]
+		if value, ok := e.Variables[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// ResolveValue resolves placeholders in every string of a decoded JSON value
+func (e *ResolvedEnvironment) ResolveValue(value interface{}) interface{} {
+	if e == nil {
+		return value
+	}
+	switch v := value.(type) {
+	case string:
+		return e.Resolve(v)
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			resolved[key] = e.ResolveValue(item)
+		}
+		return resolved
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved[i] = e.ResolveValue(item)
+		}
+		return resolved
+	default:
+		return value
+	}
+}
+
+// URL returns the full URL of an endpoint: the resolved endpoint if it is already
+// absolute, otherwise the resolved endpoint appended to the baseUrl variable
+func (e *ResolvedEnvironment) URL(endpoint string) string {
+	resolved := e.Resolve(endpoint)
+	if e == nil || strings.HasPrefix(resolved, "http://") || strings.HasPrefix(resolved, "https://") {
+		return resolved
+	}
+
+	base, ok := e.Variables[BaseURLVariable]
+	if !ok || base == "" {
+		return resolved
+	}
+	return strings.TrimRight(e.Resolve(base), "/") + "/" + strings.TrimLeft(resolved, "/")
+}
+
+// Headers returns the resolved request headers of the "header.<Name>" variables
+func (e *ResolvedEnvironment) Headers() map[string]string {
+	headers := make(map[string]string)
+	if e == nil {
+		return headers
+	}
+	for key, value := range e.Variables {
+		if name := strings.TrimPrefix(key, HeaderVariablePrefix); name != key && name != "" {
+			headers[name] = e.Resolve(value)
+		}
+	}
+	return headers
+}
+
+// Mask replaces every secret value in a string with the masked placeholder
+func (e *ResolvedEnvironment) Mask(s string) string {
+	if e == nil {
+		return s
+	}
+	for _, secret := range e.secrets {
+		s = strings.ReplaceAll(s, secret, models.MaskedSecret)
+	}
+	return s
+}
+
+// BuildEnvironmentVariables converts submitted variables into rows, encrypting secrets.
+// Secrets submitted without a new value keep the stored value of the existing variable with the same key.
+func BuildEnvironmentVariables(inputs []EnvironmentVariableInput, existing []models.EnvironmentVariable) ([]models.EnvironmentVariable, error) {
+	stored := make(map[string]models.EnvironmentVariable, len(existing))
+	for _, variable := range existing {
+		stored[variable.Key] = variable
+	}
+
+	seen := make(map[string]bool, len(inputs))
+	variables := make([]models.EnvironmentVariable, 0, len(inputs))
+	for _, input := range inputs {
+		key := strings.TrimSpace(input.Key)
+		if key == "" {
+			return nil, errors.New("variable key is required")
+		}
+		if seen[key] {
+			return nil, errors.New("duplicate variable key: " + key)
+		}
+		seen[key] = true
+
+		variable := models.EnvironmentVariable{Key: key, Secret: input.Secret}
+
+		keep := input.Value == nil || *input.Value == models.MaskedSecret
+		if previous, ok := stored[key]; input.Secret && keep && ok && previous.Secret {
+			variable.Value = previous.Value
+		} else if input.Secret {
+			plain := ""
+			if !keep {
+				plain = *input.Value
+			} else if ok {
+				plain = previous.Value
+			}
+			encrypted, err := EncryptSecret(plain)
+			if err != nil {
+				return nil, err
+			}
+			variable.Value = encrypted
+		} else if input.Value != nil {
+			variable.Value = *input.Value
+		} else if ok && !previous.Secret {
+			variable.Value = previous.Value
+		}
+
+		variables = append(variables, variable)
+	}
+
+	return variables, nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Change event types emitted by the group, API and parameter mutation points
+const (
+	EventGroupCreated      = "group.created"
+	EventGroupUpdated      = "group.updated"
+	EventGroupDeleted      = "group.deleted"
+	EventAPICreated        = "api.created"
+	EventAPIUpdated        = "api.updated"
+	EventAPIDeleted        = "api.deleted"
+	EventParametersUpdated = "parameters.updated"
+)
+
+// Sources of a change event
+const (
+	ChangeSourceAPI = "api" // REST API, used by the web UI and scripts
+	ChangeSourceMCP = "mcp" // Write tools of the MCP server
+	ChangeSourceCLI = "cli" // knot import commands
+)
+
+// ChangeEventTypes lists every event type that can be subscribed to
+var ChangeEventTypes = []string{
+	EventGroupCreated,
+	EventGroupUpdated,
+	EventGroupDeleted,
+	EventAPICreated,
+	EventAPIUpdated,
+	EventAPIDeleted,
+	EventParametersUpdated,
+}
+
+// IsChangeEventType reports whether the given name is a known event type
+func IsChangeEventType(eventType string) bool {
+	for _, known := range ChangeEventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangeEvent describes a mutation of documentation data
+type ChangeEvent struct {
+	Type      string        `json:"event"`
+	GroupID   uint          `json:"groupId"`
+	APIID     uint          `json:"apiId,omitempty"`
+	Entity    interface{}   `json:"entity"`
+	Diff      []FieldChange `json:"diff"`
+	Source    string        `json:"source"`
+	Timestamp string        `json:"timestamp"`
+}
+
+// PublishChange records a change in the change history and notifies subscribers about it.
+// Events without a source come from the REST API. Delivery happens asynchronously.
+func PublishChange(db *gorm.DB, event ChangeEvent) {
+	if event.Timestamp == "" {
+		event.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	if event.Diff == nil {
+		event.Diff = []FieldChange{}
+	}
+	if event.Source == "" {
+		event.Source = ChangeSourceAPI
+	}
+
+	if err := RecordChange(db, event); err != nil {
+		logger.Log.Error("Failed to record change", zap.String("event", event.Type), zap.Error(err))
+	}
+
+	go DispatchWebhooks(db, event)
+}
+
+// RecordChange stores an event in the change history without notifying webhooks. Commands
+// that exit right away, such as knot import, use it so running servers still see the change.
+func RecordChange(db *gorm.DB, event ChangeEvent) error {
+	if event.Diff == nil {
+		event.Diff = []FieldChange{}
+	}
+	if event.Source == "" {
+		event.Source = ChangeSourceAPI
+	}
+	diff, err := json.Marshal(event.Diff)
+	if err != nil {
+		return err
+	}
+
+	record := models.ChangeRecord{
+		EventType: event.Type,
+		GroupID:   event.GroupID,
+		Source:    event.Source,
+		Diff:      string(diff),
+	}
+	if event.APIID != 0 {
+		apiID := event.APIID
+		record.APIID = &apiID
+	}
+	return db.Create(&record).Error
+}
+
+// ChangeHistoryFilter selects entries of the change history. Zero fields match every entry.
+type ChangeHistoryFilter struct {
+	GroupID uint
+	APIID   uint
+	Source  string
+	AfterID uint // Only entries recorded after the entry with this ID
+	Limit   int
+}
+
+// LoadChangeHistory returns the most recent entries of the change history, newest first
+func LoadChangeHistory(db *gorm.DB, filter ChangeHistoryFilter) ([]models.ChangeRecord, error) {
+	query := db.Order("id DESC")
+	if filter.GroupID != 0 {
+		query = query.Where("group_id = ?", filter.GroupID)
+	}
+	if filter.APIID != 0 {
+		query = query.Where("api_id = ?", filter.APIID)
+	}
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+	if filter.AfterID != 0 {
+		query = query.Where("id > ?", filter.AfterID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	records := []models.ChangeRecord{}
+	if err := query.Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
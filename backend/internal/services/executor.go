@@ -0,0 +1,340 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Request executor settings
+var (
+	// ExecutorDefaultTimeout is used when a request does not set its own timeout
+	ExecutorDefaultTimeout = 30 * time.Second
+	// ExecutorMaxTimeout caps the timeout a request may ask for
+	ExecutorMaxTimeout = 2 * time.Minute
+	// ExecutorMaxRequestBodyBytes is the largest request body the executor sends
+	ExecutorMaxRequestBodyBytes int64 = 1 << 20
+	// ExecutorMaxResponseBodyBytes is the largest response body the executor reads; the rest is truncated
+	ExecutorMaxResponseBodyBytes int64 = 1 << 20
+	// ExecutorClient is the HTTP client used to send requests. Replace it to point at a test server.
+	ExecutorClient = &http.Client{}
+)
+
+// Executor errors
+var (
+	ErrInvalidBaseURL      = errors.New("base URL must be an absolute http or https URL")
+	ErrRequestBodyTooLarge = errors.New("request body exceeds the size limit")
+	ErrEventAPI            = errors.New("EVENT APIs are delivered through a broker and cannot be called over HTTP")
+	ErrGraphQLAPI          = errors.New("GRAPHQL APIs document fields of a schema and cannot be called as single HTTP requests")
+)
+
+// pathParamPattern matches ":name" and "{name}" placeholders in an endpoint path
+var pathParamPattern = regexp.MustCompile(`:([A-Za-z_][A-Za-z0-9_]*)|\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ExecuteInput describes a request to send to a documented API.
+// Values that are not given are prefilled from the example generated from the request parameters.
+// With an environment, "{{var}}" placeholders are resolved, the base URL defaults to its baseUrl variable
+// and its "header.<Name>" variables are sent as headers.
+type ExecuteInput struct {
+	BaseURL     string               `json:"baseUrl"`
+	Environment string               `json:"environment"`
+	PathParams  map[string]string    `json:"pathParams"`
+	Query       map[string]string    `json:"query"`
+	Headers     map[string]string    `json:"headers"`
+	Body        interface{}          `json:"body"`
+	TimeoutMs   int                  `json:"timeoutMs"`
+	Env         *ResolvedEnvironment `json:"-"`
+}
+
+// ExecuteResult is the outcome of an executed request
+type ExecuteResult struct {
+	HistoryID      uint              `json:"historyId,omitempty"`
+	Method         string            `json:"method"`
+	URL            string            `json:"url"`
+	RequestHeaders map[string]string `json:"requestHeaders"`
+	RequestBody    *string           `json:"requestBody"`
+	StatusCode     int               `json:"statusCode"`
+	Status         string            `json:"status"`
+	Headers        map[string]string `json:"headers"`
+	Body           string            `json:"body"`
+	Size           int64             `json:"size"`
+	Truncated      bool              `json:"truncated"`
+	DurationMs     int64             `json:"durationMs"`
+	Error          string            `json:"error,omitempty"`
+}
+
+// BuildRequest builds the HTTP request for an API from its request parameter tree and the given input
+func BuildRequest(ctx context.Context, api models.API, requestTree []models.Parameter, input ExecuteInput) (*http.Request, []byte, error) {
+	if api.Type == models.APITypeEvent {
+		return nil, nil, ErrEventAPI
+	}
+	if api.Type == models.APITypeGraphQL {
+		return nil, nil, ErrGraphQLAPI
+	}
+
+	env := input.Env
+
+	method := strings.ToUpper(api.Method)
+	if method == "" {
+		method = http.MethodGet
+		if api.Type == "RPC" {
+			method = http.MethodPost
+		}
+	}
+
+	var example *OrderedObject
+	if len(requestTree) > 0 {
+		example = GenerateExampleJSON(requestTree)
+	}
+
+	// Fill path placeholders from explicit values, then from the example
+	used := make(map[string]bool)
+	path := pathParamPattern.ReplaceAllStringFunc(env.Resolve(api.Endpoint), func(match string) string {
+		name := strings.Trim(match, ":{}")
+		if value, ok := input.PathParams[name]; ok {
+			used[name] = true
+			return url.PathEscape(env.Resolve(value))
+		}
+		if example == nil {
+			return match
+		}
+		if value, ok := example.Get(name); ok && isScalar(value) {
+			used[name] = true
+			return url.PathEscape(fmt.Sprint(value))
+		}
+		return match
+	})
+
+	// Endpoints that resolve to an absolute URL ignore the base URL
+	target := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		baseURL := strings.TrimSpace(input.BaseURL)
+		if baseURL == "" && env != nil {
+			baseURL = env.Variables[BaseURLVariable]
+		}
+		target = strings.TrimRight(env.Resolve(baseURL), "/") + "/" + strings.TrimLeft(path, "/")
+	}
+
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, nil, ErrInvalidBaseURL
+	}
+
+	query := u.Query()
+	sendsBody := method != http.MethodGet && method != http.MethodHead && method != http.MethodDelete && method != http.MethodOptions
+
+	// Without an explicit body, the example becomes the JSON body or, for
+	// methods without a body, the query string
+	body := env.ResolveValue(input.Body)
+	if body == nil && example != nil {
+		if sendsBody {
+			for name := range used {
+				example.Delete(name)
+			}
+			body = example
+		} else {
+			for _, name := range example.Keys {
+				if value := example.Values[name]; !used[name] && isScalar(value) {
+					query.Set(name, fmt.Sprint(value))
+				}
+			}
+		}
+	}
+	for name, value := range input.Query {
+		query.Set(name, env.Resolve(value))
+	}
+	u.RawQuery = query.Encode()
+
+	var payload []byte
+	contentType := ""
+	switch v := body.(type) {
+	case nil:
+	case string:
+		payload = []byte(v)
+		contentType = "text/plain; charset=utf-8"
+	default:
+		if payload, err = json.Marshal(v); err != nil {
+			return nil, nil, fmt.Errorf("invalid request body: %w", err)
+		}
+		contentType = "application/json"
+	}
+
+	if int64(len(payload)) > ExecutorMaxRequestBodyBytes {
+		return nil, nil, ErrRequestBodyTooLarge
+	}
+
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range env.Headers() {
+		req.Header.Set(name, value)
+	}
+	for name, value := range input.Headers {
+		req.Header.Set(name, env.Resolve(value))
+	}
+
+	return req, payload, nil
+}
+
+// ExecuteRequest sends a request to a documented API and returns the response.
+// Transport failures such as timeouts are reported in the result; an error is only
+// returned when the request cannot be built. Environment secrets are masked in the result.
+func ExecuteRequest(api models.API, requestTree []models.Parameter, input ExecuteInput) (ExecuteResult, error) {
+	result, err := sendRequest(api, requestTree, input)
+	if err != nil {
+		return result, err
+	}
+
+	env := input.Env
+	result.URL = env.Mask(result.URL)
+	result.Body = env.Mask(result.Body)
+	result.Error = env.Mask(result.Error)
+	if result.RequestBody != nil {
+		masked := env.Mask(*result.RequestBody)
+		result.RequestBody = &masked
+	}
+	for name, value := range result.RequestHeaders {
+		result.RequestHeaders[name] = env.Mask(value)
+	}
+	for name, value := range result.Headers {
+		result.Headers[name] = env.Mask(value)
+	}
+
+	return result, nil
+}
+
+// sendRequest builds and sends a request and reads the response within the size limit
+func sendRequest(api models.API, requestTree []models.Parameter, input ExecuteInput) (ExecuteResult, error) {
+	timeout := ExecutorDefaultTimeout
+	if input.TimeoutMs > 0 {
+		timeout = time.Duration(input.TimeoutMs) * time.Millisecond
+	}
+	if timeout > ExecutorMaxTimeout {
+		timeout = ExecutorMaxTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, payload, err := BuildRequest(ctx, api, requestTree, input)
+	if err != nil {
+		return ExecuteResult{}, err
+	}
+
+	result := ExecuteResult{
+		Method:         req.Method,
+		URL:            req.URL.String(),
+		RequestHeaders: flattenHeaders(req.Header),
+		Headers:        map[string]string{},
+	}
+	if payload != nil {
+		requestBody := string(payload)
+		result.RequestBody = &requestBody
+	}
+
+	start := time.Now()
+	resp, err := ExecutorClient.Do(req)
+	if err != nil {
+		result.DurationMs = time.Since(start).Milliseconds()
+		if errors.Is(err, context.DeadlineExceeded) {
+			result.Error = fmt.Sprintf("request timed out after %s", timeout)
+		} else {
+			result.Error = err.Error()
+		}
+		return result, nil
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, ExecutorMaxResponseBodyBytes+1))
+	result.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to read response: %v", err)
+	}
+	if int64(len(data)) > ExecutorMaxResponseBodyBytes {
+		data = data[:ExecutorMaxResponseBodyBytes]
+		result.Truncated = true
+	}
+
+	result.StatusCode = resp.StatusCode
+	result.Status = resp.Status
+	result.Headers = flattenHeaders(resp.Header)
+	result.Body = string(data)
+	result.Size = int64(len(data))
+
+	return result, nil
+}
+
+// RecordRequestHistory stores an executed request in the history of its API
+func RecordRequestHistory(db *gorm.DB, apiID uint, result ExecuteResult) (models.RequestHistory, error) {
+	requestHeaders, err := json.Marshal(result.RequestHeaders)
+	if err != nil {
+		return models.RequestHistory{}, err
+	}
+	responseHeaders, err := json.Marshal(result.Headers)
+	if err != nil {
+		return models.RequestHistory{}, err
+	}
+
+	entry := models.RequestHistory{
+		APIID:           apiID,
+		Method:          result.Method,
+		URL:             result.URL,
+		RequestHeaders:  string(requestHeaders),
+		RequestBody:     result.RequestBody,
+		StatusCode:      result.StatusCode,
+		ResponseHeaders: string(responseHeaders),
+		ResponseSize:    result.Size,
+		Truncated:       result.Truncated,
+		DurationMs:      result.DurationMs,
+	}
+	if result.StatusCode != 0 {
+		entry.ResponseBody = &result.Body
+	}
+	if result.Error != "" {
+		entry.Error = &result.Error
+	}
+
+	if err := db.Create(&entry).Error; err != nil {
+		return entry, err
+	}
+	return entry, nil
+}
+
+// flattenHeaders joins multi-valued headers into a single comma-separated value
+func flattenHeaders(header http.Header) map[string]string {
+	flat := make(map[string]string, len(header))
+	for name, values := range header {
+		flat[name] = strings.Join(values, ", ")
+	}
+	return flat
+}
+
+// isScalar reports whether an example value can be sent as a path or query value
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, bool, int, int64, float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+)
+
+// newEchoServer starts a server that answers with the request line and body it received
+func newEchoServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		io.WriteString(w, r.Method+" "+r.URL.RequestURI()+"\n"+string(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestExecuteRequestPrefillsPathAndQuery(t *testing.T) {
+	server := newEchoServer(t)
+	status := "active"
+	api := models.API{Method: "GET", Endpoint: "/users/{id}"}
+	tree := []models.Parameter{
+		{Name: "id", Type: "integer", Required: true},
+		{Name: "status", Type: "string", Description: &status},
+	}
+
+	result, err := ExecuteRequest(api, tree, ExecuteInput{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("ExecuteRequest: %v", err)
+	}
+	if want := "GET /users/0?status=active\n"; result.Body != want {
+		t.Errorf("body = %q, want %q", result.Body, want)
+	}
+
+	result, err = ExecuteRequest(api, tree, ExecuteInput{
+		BaseURL:    server.URL,
+		PathParams: map[string]string{"id": "42"},
+		Query:      map[string]string{"status": "blocked"},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteRequest: %v", err)
+	}
+	if want := "GET /users/42?status=blocked\n"; result.Body != want {
+		t.Errorf("body = %q, want %q", result.Body, want)
+	}
+}
+
+func TestExecuteRequestBody(t *testing.T) {
+	server := newEchoServer(t)
+	api := models.API{Method: "POST", Endpoint: "/orders"}
+	tree := []models.Parameter{
+		{Name: "item", Type: "string", Required: true},
+		{Name: "qty", Type: "integer"},
+	}
+
+	tests := []struct {
+		name        string
+		body        interface{}
+		contentType string
+		want        string
+	}{
+		{name: "default from example", contentType: "application/json", want: `{"item":"string","qty":0}`},
+		{name: "override", body: map[string]interface{}{"qty": 3}, contentType: "application/json", want: `{"qty":3}`},
+		{name: "raw text", body: "hello", contentType: "text/plain; charset=utf-8", want: "hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExecuteRequest(api, tree, ExecuteInput{BaseURL: server.URL, Body: tt.body})
+			if err != nil {
+				t.Fatalf("ExecuteRequest: %v", err)
+			}
+			if result.RequestBody == nil || *result.RequestBody != tt.want {
+				t.Errorf("request body = %v, want %q", result.RequestBody, tt.want)
+			}
+			if got := result.RequestHeaders["Content-Type"]; got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if want := "POST /orders\n" + tt.want; result.Body != want {
+				t.Errorf("response body = %q, want %q", result.Body, want)
+			}
+		})
+	}
+}
+
+func TestExecuteRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	api := models.API{Method: "GET", Endpoint: "/slow"}
+	result, err := ExecuteRequest(api, nil, ExecuteInput{BaseURL: server.URL, TimeoutMs: 50})
+	if err != nil {
+		t.Fatalf("ExecuteRequest: %v", err)
+	}
+	if want := "request timed out after 50ms"; result.Error != want {
+		t.Errorf("error = %q, want %q", result.Error, want)
+	}
+	if result.StatusCode != 0 {
+		t.Errorf("status code = %d, want 0", result.StatusCode)
+	}
+}
+
+func TestExecuteRequestTruncatesResponse(t *testing.T) {
+	defer func(limit int64) { ExecutorMaxResponseBodyBytes = limit }(ExecutorMaxResponseBodyBytes)
+	ExecutorMaxResponseBodyBytes = 16
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Repeat("x", 64))
+	}))
+	t.Cleanup(server.Close)
+
+	api := models.API{Method: "GET", Endpoint: "/large"}
+	result, err := ExecuteRequest(api, nil, ExecuteInput{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("ExecuteRequest: %v", err)
+	}
+	if !result.Truncated {
+		t.Error("truncated = false, want true")
+	}
+	if result.Body != strings.Repeat("x", 16) || result.Size != 16 {
+		t.Errorf("body = %q (size %d), want 16 bytes", result.Body, result.Size)
+	}
+}
+
+func TestExecuteRequestBodyTooLarge(t *testing.T) {
+	defer func(limit int64) { ExecutorMaxRequestBodyBytes = limit }(ExecutorMaxRequestBodyBytes)
+	ExecutorMaxRequestBodyBytes = 8
+
+	server := newEchoServer(t)
+	api := models.API{Method: "POST", Endpoint: "/upload"}
+
+	_, err := ExecuteRequest(api, nil, ExecuteInput{BaseURL: server.URL, Body: "more than eight bytes"})
+	if !errors.Is(err, ErrRequestBodyTooLarge) {
+		t.Errorf("err = %v, want ErrRequestBodyTooLarge", err)
+	}
+}
@@ -1,114 +1,62 @@
 package services
 
 import (
-	"encoding/json"
+	"archive/zip"
 	"fmt"
+	"io"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/ProjAnvil/knot/backend/internal/models"
+	"gorm.io/gorm"
 )
 
-// BuildParameterTree builds a hierarchical tree structure from flat parameter list
+// BuildParameterTree builds a hierarchical tree structure from flat parameter list.
+// Siblings keep the order of the list.
 func BuildParameterTree(params []models.Parameter) []models.Parameter {
 	if len(params) == 0 {
 		return []models.Parameter{}
 	}
 
-	// Create a map for quick lookup - store pointers to work with references
-	paramMap := make(map[uint]*models.Parameter)
-
-	// Make a copy of parameters to avoid modifying the original slice
-	paramsCopy := make([]models.Parameter, len(params))
-	for i := range params {
-		paramsCopy[i] = params[i]
-		paramsCopy[i].Children = []models.Parameter{}
-		paramMap[paramsCopy[i].ID] = &paramsCopy[i]
-	}
-
-	// Build tree structure
+	// Group copies of the parameters by parent to avoid modifying the original slice
 	var roots []models.Parameter
-	for i := range paramsCopy {
-		if paramsCopy[i].ParentID == nil {
-			// This is a root node, we'll add it later after building the tree
+	childrenOf := make(map[uint][]models.Parameter)
+	for _, param := range params {
+		if param.ParentID == nil {
+			roots = append(roots, param)
 		} else {
-			parent := paramMap[*paramsCopy[i].ParentID]
-			if parent != nil {
-				parent.Children = append(parent.Children, paramsCopy[i])
-			}
+			childrenOf[*param.ParentID] = append(childrenOf[*param.ParentID], param)
 		}
 	}
 
-	// Collect root nodes with their fully built children
-	for i := range paramsCopy {
-		if paramsCopy[i].ParentID == nil {
-			roots = append(roots, *paramMap[paramsCopy[i].ID])
+	// Attach children depth-first so that every level is complete before it is copied
+	var attach func(nodes []models.Parameter) []models.Parameter
+	attach = func(nodes []models.Parameter) []models.Parameter {
+		for i := range nodes {
+			nodes[i].Children = attach(append([]models.Parameter{}, childrenOf[nodes[i].ID]...))
 		}
+		return nodes
 	}
 
-	return roots
+	return attach(roots)
 }
 
-// GenerateParameterHTML generates HTML table for parameters
-func GenerateParameterHTML(params []models.Parameter, depth int) string {
-	if len(params) == 0 {
-		return `<p class="text-muted">No parameters</p>`
-	}
-
-	var html strings.Builder
-	if depth == 0 {
-		html.WriteString(`<table class="param-table"><thead><tr><th>Name</th><th>Type</th><th>Required</th><th>Description</th></tr></thead><tbody>`)
-	}
-
-	for _, param := range params {
-		indent := strings.Repeat("&nbsp;", depth*4)
-		prefix := ""
-		if depth > 0 {
-			prefix = "└─ "
-		}
-
-		required := `<span class="badge-optional">Optional</span>`
-		if param.Required {
-			required = `<span class="badge-required">Required</span>`
-		}
-
-		description := "-"
-		if param.Description != nil && *param.Description != "" {
-			description = *param.Description
-		}
-
-		html.WriteString(fmt.Sprintf(`<tr>
-      <td class="param-name">%s%s%s</td>
-      <td><span class="type-badge type-%s">%s</span></td>
-      <td>%s</td>
-      <td>%s</td>
-    </tr>`, indent, prefix, param.Name, param.Type, param.Type, required, description))
-
-		if len(param.Children) > 0 {
-			html.WriteString(GenerateParameterHTML(param.Children, depth+1))
-		}
-	}
-
-	if depth == 0 {
-		html.WriteString(`</tbody></table>`)
-	}
-	return html.String()
-}
-
-// GenerateExampleJSON generates example JSON from parameters
-func GenerateExampleJSON(params []models.Parameter) map[string]interface{} {
-	result := make(map[string]interface{})
+// GenerateExampleJSON generates example JSON from parameters.
+// Keys follow the documented parameter order.
+func GenerateExampleJSON(params []models.Parameter) *OrderedObject {
+	result := NewOrderedObject()
 
 	for _, param := range params {
 		var value interface{}
-		switch param.Type {
+		switch exampleType(param.Type) {
 		case "string":
 			if param.Description != nil && *param.Description != "" {
 				value = *param.Description
 			} else {
 				value = "string"
 			}
-		case "number":
+		case "number", "integer":
 			value = 0
 		case "boolean":
 			value = false
@@ -117,7 +65,7 @@ func GenerateExampleJSON(params []models.Parameter) map[string]interface{} {
 				// Check if all children are primitive types
 				allPrimitive := true
 				for _, child := range param.Children {
-					if child.Type == "object" || child.Type == "array" {
+					if childType := exampleType(child.Type); childType == "object" || childType == "array" {
 						allPrimitive = false
 						break
 					}
@@ -126,14 +74,14 @@ func GenerateExampleJSON(params []models.Parameter) map[string]interface{} {
 				if allPrimitive && len(param.Children) == 1 {
 					child := param.Children[0]
 					var primitiveValue interface{}
-					switch child.Type {
+					switch exampleType(child.Type) {
 					case "string":
 						if child.Description != nil && *child.Description != "" {
 							primitiveValue = *child.Description
 						} else {
 							primitiveValue = "string"
 						}
-					case "number":
+					case "number", "integer":
 						primitiveValue = 0
 					case "boolean":
 						primitiveValue = false
@@ -158,404 +106,325 @@ func GenerateExampleJSON(params []models.Parameter) map[string]interface{} {
 			value = nil
 		}
 
-		result[param.Name] = value
+		result.Set(param.Name, value)
 	}
 
 	return result
 }
 
+// exampleType returns the type used to build examples: the first member of a union such as string|number
+func exampleType(paramType string) string {
+	return strings.SplitN(paramType, "|", 2)[0]
+}
+
+// parameterPath returns the dotted path of a parameter below prefix. The single child of a
+// primitive array describes its items and is named by the array path, e.g. tags[].
+func parameterPath(prefix string, param models.Parameter, siblings int) string {
+	if strings.HasSuffix(prefix, "[].") && param.Name == "item" && siblings == 1 && exampleType(param.Type) != "object" {
+		return strings.TrimSuffix(prefix, ".")
+	}
+	return prefix + param.Name
+}
+
+// childPathPrefix returns the path prefix of the children of a parameter
+func childPathPrefix(path, paramType string) string {
+	if exampleType(paramType) == "array" {
+		return path + "[]."
+	}
+	return path + "."
+}
+
 // APIWithParams represents an API with its parameters separated by type
 type APIWithParams struct {
 	API                models.API
 	GroupName          string
+	URL                string               // Full URL resolved for an environment, empty to show the endpoint
+	Env                *ResolvedEnvironment // Environment of the export, nil without one
 	RequestParameters  []models.Parameter
 	ResponseParameters []models.Parameter
 }
 
-// GenerateHTML generates a complete HTML document from APIs
-func GenerateHTML(apis []APIWithParams, locale string) string {
-	title := "API Documentation"
-	generatedAt := "Generated at"
-	requestParams := "Request Parameters"
-	responseParams := "Response Parameters"
-	requestExample := "Request Example"
-	responseExample := "Response Example"
-	tableOfContents := "Table of Contents"
-
-	if locale == "zh" {
-		title = "API 文档"
-		generatedAt = "生成时间"
-		requestParams = "请求参数"
-		responseParams = "响应参数"
-		requestExample = "请求示例"
-		responseExample = "响应示例"
-		tableOfContents = "目录"
+// LoadExportAPIs loads APIs with their parameters and group names for export, ordered by
+// group and position. A nil apiIDs exports every API. With an environment, endpoints are
+// resolved to full URLs with secrets masked.
+func LoadExportAPIs(db *gorm.DB, apiIDs []uint, environment string) ([]APIWithParams, error) {
+	query := db.Order("group_id ASC").Order("`order` ASC").Order("id ASC")
+	if apiIDs != nil {
+		query = query.Where("id IN ?", apiIDs)
 	}
 
-	// Group APIs by group
-	type GroupedAPIs struct {
-		GroupID   uint
-		GroupName string
-		APIs      []APIWithParams
+	var apis []models.API
+	if err := query.Find(&apis).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch APIs: %w", err)
 	}
 
-	groupMap := make(map[uint]*GroupedAPIs)
+	// Fetch all groups that these APIs belong to
+	groupIDs := make([]uint, 0)
+	groupIDMap := make(map[uint]bool)
 	for _, api := range apis {
-		groupID := api.API.GroupID
-		if _, exists := groupMap[groupID]; !exists {
-			groupMap[groupID] = &GroupedAPIs{
-				GroupID:   groupID,
-				GroupName: api.GroupName,
-				APIs:      []APIWithParams{},
-			}
+		if !groupIDMap[api.GroupID] {
+			groupIDs = append(groupIDs, api.GroupID)
+			groupIDMap[api.GroupID] = true
 		}
-		groupMap[groupID].APIs = append(groupMap[groupID].APIs, api)
 	}
 
-	// Convert map to slice
-	var groupedAPIs []*GroupedAPIs
-	for _, group := range groupMap {
-		groupedAPIs = append(groupedAPIs, group)
+	var groups []models.Group
+	if len(groupIDs) > 0 {
+		if err := db.Where("id IN ?", groupIDs).Find(&groups).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch groups: %w", err)
+		}
 	}
 
-	// Generate sidebar
-	var sidebarItems strings.Builder
-	for _, group := range groupedAPIs {
-		sidebarItems.WriteString(`<div class="sidebar-group">`)
-		sidebarItems.WriteString(fmt.Sprintf(`<div class="sidebar-group-title">%s</div>`, group.GroupName))
-		sidebarItems.WriteString(`<div class="sidebar-group-items">`)
-
-		for j, api := range group.APIs {
-			globalIndex := 0
-			for i, a := range apis {
-				if a.API.ID == api.API.ID {
-					globalIndex = i
-					break
-				}
+	groupMap := make(map[uint]string)
+	for _, g := range groups {
+		groupMap[g.ID] = g.Name
+	}
+
+	// Resolve the environment once per group
+	environments := make(map[uint]*ResolvedEnvironment)
+	if environment != "" {
+		for _, groupID := range groupIDs {
+			env, err := LoadEnvironment(db, environment, groupID)
+			if err != nil && err != ErrEnvironmentNotFound {
+				return nil, fmt.Errorf("failed to resolve environment: %w", err)
+			}
+			environments[groupID] = env
+		}
+	}
+
+	// Fetch parameters for each API
+	apisWithParams := make([]APIWithParams, 0, len(apis))
+	for _, api := range apis {
+		var allParams []models.Parameter
+		if err := db.Where("api_id = ?", api.ID).Order("`order` ASC").Find(&allParams).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch parameters: %w", err)
+		}
+
+		requestParams := make([]models.Parameter, 0)
+		responseParams := make([]models.Parameter, 0)
+
+		for _, p := range allParams {
+			if p.ParamType == "request" {
+				requestParams = append(requestParams, p)
+			} else if p.ParamType == "response" {
+				responseParams = append(responseParams, p)
 			}
-			_ = j
-			sidebarItems.WriteString(fmt.Sprintf(`<a href="#api-%d" class="sidebar-api-item">%s</a>`, globalIndex, api.API.Name))
 		}
 
-		sidebarItems.WriteString(`</div></div>`)
+		groupName := groupMap[api.GroupID]
+		if groupName == "" {
+			groupName = "Ungrouped"
+		}
+
+		env := environments[api.GroupID]
+		var url string
+		if env != nil && api.Type != models.APITypeEvent && api.Type != models.APITypeGraphQL {
+			// Channel names of EVENT APIs and fields of GRAPHQL APIs are not URLs
+			url = env.Mask(env.URL(api.Endpoint))
+		}
+
+		apisWithParams = append(apisWithParams, APIWithParams{
+			API:                api,
+			GroupName:          groupName,
+			URL:                url,
+			Env:                env,
+			RequestParameters:  requestParams,
+			ResponseParameters: responseParams,
+		})
 	}
 
-	// Generate API sections
-	var apisHTML strings.Builder
-	for index, api := range apis {
-		requestTree := BuildParameterTree(api.RequestParameters)
-		responseTree := BuildParameterTree(api.ResponseParameters)
-		requestJSON := GenerateExampleJSON(requestTree)
-		responseJSON := GenerateExampleJSON(responseTree)
-
-		requestJSONBytes, _ := json.MarshalIndent(requestJSON, "", "  ")
-		responseJSONBytes, _ := json.MarshalIndent(responseJSON, "", "  ")
-
-		apisHTML.WriteString(fmt.Sprintf(`
-      <div class="api-section" id="api-%d">
-        <div class="api-header">
-          <h2>%s</h2>
-          <div class="api-meta">
-            <span class="badge badge-%s">%s</span>
-            <code class="endpoint">%s</code>
-            <span class="badge badge-type">%s</span>
-          </div>
-        </div>
-
-        <div class="section">
-          <h3>%s</h3>
-          %s
-        </div>
-`, index, api.API.Name, strings.ToLower(api.API.Method), api.API.Method, api.API.Endpoint, api.API.Type, requestParams, GenerateParameterHTML(requestTree, 0)))
-
-		if len(requestJSON) > 0 {
-			apisHTML.WriteString(fmt.Sprintf(`
-        <div class="section">
-          <h3>%s</h3>
-          <pre><code>%s</code></pre>
-        </div>
-`, requestExample, string(requestJSONBytes)))
+	return apisWithParams, nil
+}
+
+// GenerateHTML generates a complete HTML document from APIs with the default template
+func GenerateHTML(apis []APIWithParams, locale string) string {
+	var html strings.Builder
+	if err := defaultExportTemplate.Execute(&html, BuildTemplateData(apis, locale)); err != nil {
+		return fmt.Sprintf("<!-- %s -->", err)
+	}
+	return html.String()
+}
+
+// ExportFile is one generated file of a multi-file export
+type ExportFile struct {
+	Name    string
+	Content string
+}
+
+// WriteExportZip writes exported files into a ZIP archive. Entries carry a fixed
+// modification time so that the archive only changes when the files do.
+func WriteExportZip(w io.Writer, files []ExportFile) error {
+	archive := zip.NewWriter(w)
+	modified := time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, file := range files {
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.Name,
+			Method:   zip.Deflate,
+			Modified: modified,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(entry, file.Content); err != nil {
+			return err
 		}
+	}
+	return archive.Close()
+}
 
-		apisHTML.WriteString(fmt.Sprintf(`
-        <div class="section">
-          <h3>%s</h3>
-          %s
-        </div>
-`, responseParams, GenerateParameterHTML(responseTree, 0)))
-
-		if len(responseJSON) > 0 {
-			apisHTML.WriteString(fmt.Sprintf(`
-        <div class="section">
-          <h3>%s</h3>
-          <pre><code>%s</code></pre>
-        </div>
-`, responseExample, string(responseJSONBytes)))
+// exportLabels holds the localized headings of file exports
+type exportLabels struct {
+	title           string
+	generatedAt     string
+	tableOfContents string
+	requestParams   string
+	responseParams  string
+	requestExample  string
+	responseExample string
+	snippets        string
+	messagePayload  string
+	payloadExample  string
+	signature       string
+	note            string
+	noParameters    string
+	name            string
+	paramType       string
+	required        string
+	description     string
+	yes             string
+	no              string
+	groups          string
+	apis            string
+	search          string
+	previous        string
+	next            string
+}
+
+func newExportLabels(locale string) exportLabels {
+	if locale == "zh" {
+		return exportLabels{
+			title:           "API 文档",
+			generatedAt:     "生成时间",
+			tableOfContents: "目录",
+			requestParams:   "请求参数",
+			responseParams:  "响应参数",
+			requestExample:  "请求示例",
+			responseExample: "响应示例",
+			snippets:        "调用示例",
+			messagePayload:  "消息体",
+			payloadExample:  "消息示例",
+			signature:       "SDL 签名",
+			note:            "备注",
+			noParameters:    "无参数",
+			name:            "名称",
+			paramType:       "类型",
+			required:        "必填",
+			description:     "描述",
+			yes:             "是",
+			no:              "否",
+			groups:          "分组",
+			apis:            "接口",
+			search:          "搜索接口…",
+			previous:        "上一个",
+			next:            "下一个",
+		}
+	}
+	return exportLabels{
+		title:           "API Documentation",
+		generatedAt:     "Generated at",
+		tableOfContents: "Table of Contents",
+		requestParams:   "Request Parameters",
+		responseParams:  "Response Parameters",
+		requestExample:  "Request Example",
+		responseExample: "Response Example",
+		snippets:        "Code Snippets",
+		messagePayload:  "Message Payload",
+		payloadExample:  "Payload Example",
+		signature:       "SDL Signature",
+		note:            "Note",
+		noParameters:    "No parameters",
+		name:            "Name",
+		paramType:       "Type",
+		required:        "Required",
+		description:     "Description",
+		yes:             "Yes",
+		no:              "No",
+		groups:          "Groups",
+		apis:            "APIs",
+		search:          "Search APIs…",
+		previous:        "Previous",
+		next:            "Next",
+	}
+}
+
+// exportGroup holds the APIs of one group in export order with their slugs
+type exportGroup struct {
+	id       uint
+	name     string
+	slug     string // Unique among the exported groups
+	apis     []APIWithParams
+	apiSlugs []string // Unique within the group, parallel to apis
+}
+
+// reservedSlugs are file and directory names of multi-file exports that groups must not take
+var reservedSlugs = []string{"readme", "index", "assets", "search"}
+
+// groupExportAPIs groups APIs by group, keeping the order in which groups first appear.
+// Slugs are derived from the names, so they stay the same as long as the names and the
+// order of groups and APIs with clashing names do.
+func groupExportAPIs(apis []APIWithParams) []*exportGroup {
+	var groups []*exportGroup
+	byID := make(map[uint]*exportGroup)
+	apiSlugsUsed := make(map[uint]map[string]bool)
+
+	groupSlugsUsed := make(map[string]bool)
+	for _, slug := range reservedSlugs {
+		groupSlugsUsed[slug] = true
+	}
+
+	for _, api := range apis {
+		group, exists := byID[api.API.GroupID]
+		if !exists {
+			group = &exportGroup{id: api.API.GroupID, name: api.GroupName, slug: uniqueSlug(Slugify(api.GroupName), groupSlugsUsed)}
+			byID[api.API.GroupID] = group
+			apiSlugsUsed[api.API.GroupID] = map[string]bool{"index": true}
+			groups = append(groups, group)
 		}
+		group.apis = append(group.apis, api)
+		group.apiSlugs = append(group.apiSlugs, uniqueSlug(Slugify(api.API.Name), apiSlugsUsed[api.API.GroupID]))
+	}
+	return groups
+}
 
-		apisHTML.WriteString(`      </div>`)
+// Slugify turns a name into a lowercase, URL and file name safe slug. Letters of any script
+// and digits are kept; other runs of characters become a single dash.
+func Slugify(name string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if slug.Len() == 0 {
+		return "untitled"
 	}
+	return slug.String()
+}
 
-	// Generate full HTML
-	return fmt.Sprintf(`<!DOCTYPE html>
-<html lang="%s">
-<head>
-  <meta charset="UTF-8">
-  <meta name="viewport" content="width=device-width, initial-scale=1.0">
-  <title>%s</title>
-  <style>
-    * { margin: 0; padding: 0; box-sizing: border-box; }
-    body {
-      font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif;
-      line-height: 1.6;
-      color: #333;
-      background: #f5f5f5;
-      display: flex;
-      min-height: 100vh;
-    }
-    .sidebar {
-      width: 280px;
-      background: white;
-      border-right: 1px solid #e5e5e5;
-      padding: 20px;
-      position: fixed;
-      height: 100vh;
-      overflow-y: auto;
-      left: 0;
-      top: 0;
-    }
-    .sidebar h1 {
-      font-size: 1.5em;
-      margin-bottom: 10px;
-      color: #1a1a1a;
-      border-bottom: 2px solid #0070f3;
-      padding-bottom: 10px;
-    }
-    .sidebar-meta {
-      font-size: 0.75em;
-      color: #666;
-      margin-bottom: 20px;
-    }
-    .sidebar-group {
-      margin-bottom: 20px;
-    }
-    .sidebar-group-title {
-      font-size: 0.85em;
-      font-weight: 700;
-      color: #1a1a1a;
-      text-transform: uppercase;
-      letter-spacing: 0.5px;
-      padding: 8px 12px;
-      margin-bottom: 8px;
-      background: #f8f9fa;
-      border-radius: 6px;
-      border-left: 3px solid #0070f3;
-    }
-    .sidebar-api-item {
-      display: block;
-      padding: 8px 12px;
-      color: #333;
-      text-decoration: none;
-      border-radius: 6px;
-      margin-bottom: 4px;
-      transition: all 0.2s;
-      font-size: 0.9em;
-    }
-    .sidebar-api-item:hover {
-      background: #f0f0f0;
-      color: #0070f3;
-    }
-    .sidebar-api-item.active {
-      background: #e3f2fd;
-      color: #0070f3;
-      font-weight: 500;
-    }
-    .main-content {
-      flex: 1;
-      margin-left: 280px;
-      padding: 40px;
-      max-width: calc(100%% - 280px);
-    }
-    .container {
-      max-width: 1000px;
-      margin: 0 auto;
-      background: white;
-      padding: 40px;
-      border-radius: 8px;
-      box-shadow: 0 2px 4px rgba(0,0,0,0.1);
-    }
-    .api-section {
-      margin-bottom: 60px;
-      padding-bottom: 40px;
-      border-bottom: 2px solid #e5e5e5;
-      scroll-margin-top: 20px;
-    }
-    .api-section:last-child {
-      border-bottom: none;
-    }
-    .api-header h2 {
-      font-size: 2em;
-      margin-bottom: 15px;
-      color: #1a1a1a;
-    }
-    .api-meta {
-      display: flex;
-      gap: 12px;
-      align-items: center;
-      margin-bottom: 30px;
-      flex-wrap: wrap;
-    }
-    .badge {
-      display: inline-block;
-      padding: 4px 12px;
-      border-radius: 4px;
-      font-size: 0.85em;
-      font-weight: 600;
-      text-transform: uppercase;
-    }
-    .badge-get { background: #e3f2fd; color: #1976d2; }
-    .badge-post { background: #e8f5e9; color: #388e3c; }
-    .badge-put { background: #fff3e0; color: #f57c00; }
-    .badge-delete { background: #ffebee; color: #d32f2f; }
-    .badge-patch { background: #f3e5f5; color: #7b1fa2; }
-    .badge-type { background: #f5f5f5; color: #666; }
-    .endpoint {
-      background: #f5f5f5;
-      padding: 6px 12px;
-      border-radius: 4px;
-      font-family: 'Monaco', 'Menlo', monospace;
-      font-size: 0.9em;
-      color: #d63384;
-    }
-    .section {
-      margin-bottom: 30px;
-    }
-    .section h3 {
-      font-size: 1.4em;
-      margin-bottom: 15px;
-      color: #333;
-      border-left: 4px solid #0070f3;
-      padding-left: 12px;
-    }
-    .param-table {
-      width: 100%%;
-      border-collapse: collapse;
-      margin-bottom: 20px;
-      font-size: 0.95em;
-    }
-    .param-table th {
-      background: #f8f9fa;
-      padding: 12px;
-      text-align: left;
-      font-weight: 600;
-      border-bottom: 2px solid #dee2e6;
-      color: #495057;
-    }
-    .param-table td {
-      padding: 12px;
-      border-bottom: 1px solid #e9ecef;
-      vertical-align: top;
-    }
-    .param-table tr:hover {
-      background: #f8f9fa;
-    }
-    .param-name {
-      font-family: 'Monaco', 'Menlo', monospace;
-      font-weight: 500;
-      color: #0066cc;
-    }
-    .type-badge {
-      display: inline-block;
-      padding: 2px 8px;
-      border-radius: 3px;
-      font-size: 0.85em;
-      font-weight: 500;
-    }
-    .type-string { background: #e3f2fd; color: #1976d2; }
-    .type-number { background: #e8f5e9; color: #388e3c; }
-    .type-boolean { background: #f3e5f5; color: #7b1fa2; }
-    .type-array { background: #fff3e0; color: #f57c00; }
-    .type-object { background: #ffebee; color: #d32f2f; }
-    .badge-required {
-      color: #d32f2f;
-      font-weight: 600;
-      font-size: 0.85em;
-    }
-    .badge-optional {
-      color: #666;
-      font-size: 0.85em;
-    }
-    .text-muted {
-      color: #999;
-      font-style: italic;
-    }
-    pre {
-      background: #2d2d2d;
-      color: #f8f8f2;
-      padding: 20px;
-      border-radius: 6px;
-      overflow-x: auto;
-      font-size: 0.9em;
-      line-height: 1.5;
-    }
-    code {
-      font-family: 'Monaco', 'Menlo', monospace;
-    }
-    @media print {
-      body { display: block; }
-      .sidebar { display: none; }
-      .main-content { margin-left: 0; max-width: 100%%; padding: 20px; }
-      .container { box-shadow: none; padding: 20px; }
-      .api-section { page-break-inside: avoid; }
-    }
-  </style>
-</head>
-<body>
-  <div class="sidebar">
-    <h1>%s</h1>
-    <div class="sidebar-meta">%s: %s</div>
-    <div class="sidebar-section">
-      <h3>%s</h3>
-      %s
-    </div>
-  </div>
-  <div class="main-content">
-    <div class="container">
-      %s
-    </div>
-  </div>
-  <script>
-    document.querySelectorAll('.sidebar-api-item').forEach(link => {
-      link.addEventListener('click', function(e) {
-        e.preventDefault();
-        const targetId = this.getAttribute('href');
-        const targetElement = document.querySelector(targetId);
-        if (targetElement) {
-          targetElement.scrollIntoView({ behavior: 'smooth', block: 'start' });
-          document.querySelectorAll('.sidebar-api-item').forEach(item => item.classList.remove('active'));
-          this.classList.add('active');
-        }
-      });
-    });
-
-    const observer = new IntersectionObserver((entries) => {
-      entries.forEach(entry => {
-        if (entry.isIntersecting) {
-          const id = entry.target.getAttribute('id');
-          document.querySelectorAll('.sidebar-api-item').forEach(item => {
-            item.classList.remove('active');
-            if (item.getAttribute('href') === '#' + id) {
-              item.classList.add('active');
-            }
-          });
-        }
-      });
-    }, { rootMargin: '-20%% 0px -70%% 0px' });
-
-    document.querySelectorAll('.api-section').forEach(section => {
-      observer.observe(section);
-    });
-  </script>
-</body>
-</html>`, locale, title, title, generatedAt, time.Now().Format(time.RFC3339), tableOfContents, sidebarItems.String(), apisHTML.String())
+// uniqueSlug returns slug, or slug with a numeric suffix if it is already used, and marks it used
+func uniqueSlug(slug string, used map[string]bool) string {
+	candidate := slug
+	for i := 2; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d", slug, i)
+	}
+	used[candidate] = true
+	return candidate
 }
@@ -0,0 +1,416 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidGraphQLOperation is returned for GRAPHQL APIs whose method is not an operation type
+var ErrInvalidGraphQLOperation = errors.New("the method of GRAPHQL APIs must be QUERY, MUTATION or SUBSCRIPTION")
+
+// Depths to which the return types of GraphQL fields are expanded into response parameters
+const (
+	DefaultGraphQLDepth = 3
+	MaxGraphQLDepth     = 10
+)
+
+// graphQLOperations are the operation types in the order their fields are imported
+var graphQLOperations = []string{"query", "mutation", "subscription"}
+
+// GraphQLImportCandidate is a root field of a GraphQL schema that can be imported as an API
+type GraphQLImportCandidate struct {
+	Key                string          `json:"key"` // Operation type and field, e.g. "QUERY user"
+	Name               string          `json:"name"`
+	Operation          string          `json:"operation"` // QUERY, MUTATION or SUBSCRIPTION
+	Field              string          `json:"field"`
+	Description        string          `json:"description,omitempty"`
+	Signature          string          `json:"signature"`               // SDL of the field and the types it uses
+	ExistingAPIID      *uint           `json:"existingApiId,omitempty"` // GRAPHQL API of the group with the same key
+	RequestParameters  []ParameterNode `json:"requestParameters"`
+	ResponseParameters []ParameterNode `json:"responseParameters"`
+}
+
+// PreviewGraphQLImport lists the GRAPHQL APIs an SDL document would create in a group: one per
+// field of the query, mutation and subscription root types. The arguments of a field become
+// its request parameters. Its response parameters are a single parameter named after the field,
+// as in the data of a response, whose object types are expanded depth levels deep; a type is
+// not expanded again inside itself. Depth is DefaultGraphQLDepth when not positive.
+func PreviewGraphQLImport(db *gorm.DB, group models.Group, sdl string, depth int) ([]GraphQLImportCandidate, error) {
+	if depth <= 0 {
+		depth = DefaultGraphQLDepth
+	}
+	if depth > MaxGraphQLDepth {
+		depth = MaxGraphQLDepth
+	}
+
+	schema, err := parseGraphQLSchema(sdl)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing []models.API
+	if err := db.Where("group_id = ? AND type = ?", group.ID, models.APITypeGraphQL).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	existingIDs := make(map[string]uint, len(existing))
+	for _, api := range existing {
+		existingIDs[api.Method+" "+api.Endpoint] = api.ID
+	}
+
+	converter := &graphQLConverter{schema: schema, depth: depth}
+	candidates := []GraphQLImportCandidate{}
+	for _, operation := range graphQLOperations {
+		rootName, ok := schema.roots[operation]
+		if !ok {
+			continue
+		}
+		root := schema.types[rootName]
+		if root == nil || root.kind != "type" {
+			return nil, fmt.Errorf("%w: the %s root type %s is not an object type", ErrInvalidSDL, operation, rootName)
+		}
+
+		for _, field := range root.fields {
+			candidate := GraphQLImportCandidate{
+				Key:         strings.ToUpper(operation) + " " + field.name,
+				Name:        field.name,
+				Operation:   strings.ToUpper(operation),
+				Field:       field.name,
+				Description: field.description,
+				Signature:   converter.signature(root, field),
+			}
+			for _, arg := range field.args {
+				candidate.RequestParameters = append(candidate.RequestParameters, converter.node(arg.name, arg.typ, arg.description, arg.defaultValue, true, 0, nil))
+			}
+			candidate.ResponseParameters = []ParameterNode{converter.node(field.name, field.typ, field.description, "", false, 0, nil)}
+			if id, ok := existingIDs[candidate.Key]; ok {
+				candidate.ExistingAPIID = &id
+			}
+			candidates = append(candidates, candidate)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: the schema has no query, mutation or subscription fields", ErrInvalidSDL)
+	}
+	return candidates, nil
+}
+
+// ImportGraphQLCandidates creates GRAPHQL APIs in a group from the candidates listed in keys,
+// or from all of them if keys is empty. Candidates matching an existing API are skipped.
+func ImportGraphQLCandidates(db *gorm.DB, group models.Group, candidates []GraphQLImportCandidate, keys []string) ([]models.API, error) {
+	selected := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		selected[key] = true
+	}
+
+	created := []models.API{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var maxOrder int
+		if err := tx.Model(&models.API{}).Where("group_id = ?", group.ID).Select("COALESCE(MAX(`order`), 0)").Scan(&maxOrder).Error; err != nil {
+			return err
+		}
+
+		for _, candidate := range candidates {
+			if (len(keys) > 0 && !selected[candidate.Key]) || candidate.ExistingAPIID != nil {
+				continue
+			}
+
+			maxOrder++
+			signature := candidate.Signature
+			api := models.API{
+				GroupID:   group.ID,
+				Name:      candidate.Name,
+				Endpoint:  candidate.Field,
+				Method:    candidate.Operation,
+				Type:      models.APITypeGraphQL,
+				Signature: &signature,
+				Order:     maxOrder,
+			}
+			if candidate.Description != "" {
+				note := candidate.Description
+				api.Note = &note
+			}
+			if err := tx.Create(&api).Error; err != nil {
+				return err
+			}
+			if _, err := ReplaceParameterTree(tx, api.ID, "request", candidate.RequestParameters); err != nil {
+				return err
+			}
+			if _, err := ReplaceParameterTree(tx, api.ID, "response", candidate.ResponseParameters); err != nil {
+				return err
+			}
+			created = append(created, api)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// graphQLConverter turns the fields of a schema into parameter trees and SDL signatures
+type graphQLConverter struct {
+	schema *gqlSchema
+	depth  int
+}
+
+// node converts a field, argument or input field of the given type. Level counts the object
+// types expanded above it, and path holds their names.
+func (c *graphQLConverter) node(name string, ref gqlTypeRef, description, defaultValue string, input bool, level int, path []string) ParameterNode {
+	node := ParameterNode{Name: name}
+	if input {
+		node.Required = ref.nonNull && defaultValue == ""
+	} else {
+		node.Required = true
+		node.Nullable = !ref.nonNull
+	}
+
+	var notes []string
+	if ref.elem != nil {
+		node.Type = "array"
+		elem := *ref.elem
+		if t := c.schema.types[elem.name]; elem.elem == nil && t != nil && t.kind != "scalar" && t.kind != "enum" {
+			// Lists of objects describe the fields of their items, as arrays of objects do elsewhere
+			item := c.node(name, elem, "", "", input, level, path)
+			node.Children = item.Children
+			if item.Description != nil {
+				notes = []string{*item.Description}
+			}
+		} else {
+			node.Children = []ParameterNode{c.node("item", elem, "", "", input, level, path)}
+			node.Children[0].Required = true
+		}
+	} else {
+		node.Type, node.Children, notes = c.namedType(ref.name, input, level, path)
+	}
+
+	if defaultValue != "" {
+		notes = append(notes, "default: "+defaultValue)
+	}
+	if len(notes) > 0 {
+		if description != "" {
+			description += " (" + strings.Join(notes, "; ") + ")"
+		} else {
+			description = strings.Join(notes, "; ")
+		}
+	}
+	if description != "" {
+		node.Description = &description
+	}
+	return node
+}
+
+// namedType converts a named type into a parameter type, its children and notes describing the
+// GraphQL type, which parameter types cannot express
+func (c *graphQLConverter) namedType(name string, input bool, level int, path []string) (string, []ParameterNode, []string) {
+	if paramType, ok := graphQLBuiltinScalars[name]; ok {
+		return paramType, nil, nil
+	}
+
+	t := c.schema.types[name]
+	if t == nil {
+		return "string", nil, []string{"undefined type " + name}
+	}
+
+	switch t.kind {
+	case "scalar":
+		return "string", nil, []string{"scalar " + name}
+	case "enum":
+		values := make([]string, 0, len(t.values))
+		for _, value := range t.values {
+			values = append(values, value.name)
+		}
+		return "string", nil, []string{fmt.Sprintf("enum %s: %s", name, strings.Join(values, ", "))}
+	}
+
+	notes := []string{name}
+	if t.kind == "union" {
+		notes = []string{fmt.Sprintf("%s, one of: %s", name, strings.Join(t.members, ", "))}
+	}
+	if containsString(path, name) {
+		return "object", nil, append(notes, "not expanded inside itself")
+	}
+	if level >= c.depth {
+		return "object", nil, append(notes, "not expanded beyond depth "+strconv.Itoa(c.depth))
+	}
+
+	path = append(append([]string{}, path...), name)
+	if t.kind != "union" {
+		return "object", c.fieldNodes(t.fields, input, level+1, path), notes
+	}
+
+	// Fields of a union are those of its members; only fields of every member are required
+	var members [][]ParameterNode
+	for _, member := range t.members {
+		if m := c.schema.types[member]; m != nil {
+			members = append(members, c.fieldNodes(m.fields, input, level+1, append(path, member)))
+		}
+	}
+	return "object", mergeGraphQLMembers(members), notes
+}
+
+// fieldNodes converts the fields of an object, interface or input type
+func (c *graphQLConverter) fieldNodes(fields []*gqlField, input bool, level int, path []string) []ParameterNode {
+	nodes := make([]ParameterNode, 0, len(fields))
+	for _, field := range fields {
+		nodes = append(nodes, c.node(field.name, field.typ, field.description, field.defaultValue, input, level, path))
+	}
+	return nodes
+}
+
+// mergeGraphQLMembers merges the fields of the members of a union by name, keeping the first
+// definition of each field; fields missing from some members are not required
+func mergeGraphQLMembers(members [][]ParameterNode) []ParameterNode {
+	merged := []ParameterNode{}
+	counts := make(map[string]int)
+	for _, fields := range members {
+		for _, field := range fields {
+			if counts[field.Name] == 0 {
+				merged = append(merged, field)
+			}
+			counts[field.Name]++
+		}
+	}
+	for i := range merged {
+		if counts[merged[i].Name] < len(members) {
+			merged[i].Required = false
+		}
+	}
+	return merged
+}
+
+// signature prints the SDL of a root field and of the types it uses within the import depth
+func (c *graphQLConverter) signature(root *gqlType, field *gqlField) string {
+	var out strings.Builder
+	out.WriteString("type " + root.name + " {\n")
+	writeGraphQLField(&out, field, "  ")
+	out.WriteString("}\n")
+
+	// Types used by the arguments, then by the return type, breadth first
+	var names []string
+	seen := make(map[string]bool)
+	add := func(ref gqlTypeRef, level int, queue *[]gqlUse) {
+		name := ref.namedType()
+		if _, builtin := graphQLBuiltinScalars[name]; builtin || seen[name] || c.schema.types[name] == nil {
+			return
+		}
+		seen[name] = true
+		names = append(names, name)
+		*queue = append(*queue, gqlUse{name: name, level: level})
+	}
+
+	var queue []gqlUse
+	for _, arg := range field.args {
+		add(arg.typ, 0, &queue)
+	}
+	add(field.typ, 0, &queue)
+	for len(queue) > 0 {
+		use := queue[0]
+		queue = queue[1:]
+		if use.level >= c.depth {
+			continue
+		}
+		t := c.schema.types[use.name]
+		for _, member := range t.members {
+			add(gqlTypeRef{name: member}, use.level, &queue)
+		}
+		for _, f := range t.fields {
+			if t.kind != "input" {
+				for _, arg := range f.args {
+					add(arg.typ, use.level+1, &queue)
+				}
+			}
+			add(f.typ, use.level+1, &queue)
+		}
+	}
+
+	for _, name := range names {
+		out.WriteString("\n")
+		writeGraphQLType(&out, c.schema.types[name])
+	}
+	return out.String()
+}
+
+// gqlUse is a type used by a signature, at the depth it is first used
+type gqlUse struct {
+	name  string
+	level int
+}
+
+// writeGraphQLType prints the definition of a type
+func writeGraphQLType(out *strings.Builder, t *gqlType) {
+	writeGraphQLDescription(out, t.description, "")
+	out.WriteString(t.kind + " " + t.name)
+
+	switch t.kind {
+	case "scalar":
+		out.WriteString("\n")
+		return
+	case "union":
+		out.WriteString(" = " + strings.Join(t.members, " | ") + "\n")
+		return
+	case "enum":
+		out.WriteString(" {\n")
+		for _, value := range t.values {
+			writeGraphQLDescription(out, value.description, "  ")
+			out.WriteString("  " + value.name + "\n")
+		}
+		out.WriteString("}\n")
+		return
+	}
+
+	if len(t.interfaces) > 0 {
+		out.WriteString(" implements " + strings.Join(t.interfaces, " & "))
+	}
+	out.WriteString(" {\n")
+	for _, field := range t.fields {
+		writeGraphQLField(out, field, "  ")
+	}
+	out.WriteString("}\n")
+}
+
+// writeGraphQLField prints a field with its arguments
+func writeGraphQLField(out *strings.Builder, field *gqlField, indent string) {
+	writeGraphQLDescription(out, field.description, indent)
+	out.WriteString(indent + field.name)
+	if len(field.args) > 0 {
+		args := make([]string, 0, len(field.args))
+		for _, arg := range field.args {
+			s := arg.name + ": " + arg.typ.String()
+			if arg.defaultValue != "" {
+				s += " = " + arg.defaultValue
+			}
+			args = append(args, s)
+		}
+		out.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+	out.WriteString(": " + field.typ.String())
+	if field.defaultValue != "" {
+		out.WriteString(" = " + field.defaultValue)
+	}
+	if field.deprecated {
+		out.WriteString(" @deprecated")
+	}
+	out.WriteString("\n")
+}
+
+// writeGraphQLDescription prints a description as a string, or as a block string if it spans lines
+func writeGraphQLDescription(out *strings.Builder, description, indent string) {
+	if description == "" {
+		return
+	}
+	if !strings.Contains(description, "\n") {
+		out.WriteString(indent + strconv.Quote(description) + "\n")
+		return
+	}
+	out.WriteString(indent + `"""` + "\n")
+	for _, line := range strings.Split(strings.ReplaceAll(description, `"""`, `\"""`), "\n") {
+		out.WriteString(indent + line + "\n")
+	}
+	out.WriteString(indent + `"""` + "\n")
+}
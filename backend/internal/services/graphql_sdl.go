@@ -0,0 +1,618 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidSDL is returned for GraphQL schema documents that cannot be parsed
+var ErrInvalidSDL = errors.New("invalid GraphQL schema")
+
+// graphQLBuiltinScalars maps the built-in GraphQL scalars to parameter types
+var graphQLBuiltinScalars = map[string]string{
+	"Int":     "integer",
+	"Float":   "number",
+	"String":  "string",
+	"ID":      "string",
+	"Boolean": "boolean",
+}
+
+// gqlSchema is a parsed GraphQL schema definition language (SDL) document
+type gqlSchema struct {
+	types map[string]*gqlType
+	roots map[string]string // Root type names by operation: query, mutation and subscription
+}
+
+// gqlType is a named type of a schema
+type gqlType struct {
+	kind        string // type, interface, input, enum, union or scalar
+	name        string
+	defined     bool // Whether the type has a definition, besides extensions
+	description string
+	interfaces  []string
+	fields      []*gqlField // Fields of types and interfaces, or input fields
+	values      []gqlEnumValue
+	members     []string // Member types of unions
+}
+
+// gqlField is a field, an argument or an input field
+type gqlField struct {
+	name         string
+	description  string
+	args         []*gqlField
+	typ          gqlTypeRef
+	defaultValue string // Source text of the default value of arguments and input fields
+	deprecated   bool
+}
+
+// gqlEnumValue is a value of an enum
+type gqlEnumValue struct {
+	name        string
+	description string
+}
+
+// gqlTypeRef is a reference to a type: a named type, or a list of elem
+type gqlTypeRef struct {
+	name    string
+	elem    *gqlTypeRef
+	nonNull bool
+}
+
+// String formats a type reference as in SDL, e.g. [User!]!
+func (t gqlTypeRef) String() string {
+	s := t.name
+	if t.elem != nil {
+		s = "[" + t.elem.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+// namedType returns the named type of a reference, unwrapping lists
+func (t gqlTypeRef) namedType() string {
+	if t.elem != nil {
+		return t.elem.namedType()
+	}
+	return t.name
+}
+
+// gqlToken is a lexical token of an SDL document
+type gqlToken struct {
+	kind  byte // 'n' name, 's' string, '0' number, 'p' punctuator, 0 end of document
+	value string
+	start int
+	end   int
+}
+
+// parseGraphQLSchema parses an SDL document. Directives and directive definitions are skipped;
+// extensions are merged into the types they extend, which need not be defined in the document.
+func parseGraphQLSchema(source string) (*gqlSchema, error) {
+	tokens, err := lexGraphQL(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &gqlParser{source: source, tokens: tokens}
+	schema := &gqlSchema{types: make(map[string]*gqlType), roots: make(map[string]string)}
+	if err := p.document(schema); err != nil {
+		return nil, err
+	}
+
+	for operation, name := range map[string]string{"query": "Query", "mutation": "Mutation", "subscription": "Subscription"} {
+		if _, ok := schema.roots[operation]; !ok {
+			if _, exists := schema.types[name]; exists {
+				schema.roots[operation] = name
+			}
+		}
+	}
+	return schema, nil
+}
+
+// lexGraphQL splits an SDL document into tokens, dropping comments, commas and whitespace
+func lexGraphQL(source string) ([]gqlToken, error) {
+	var tokens []gqlToken
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' || c == 0xEF || c == 0xBB || c == 0xBF:
+			i++
+		case c == '#':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+		case c == '"':
+			start := i
+			if strings.HasPrefix(source[i:], `"""`) {
+				end := strings.Index(source[i+3:], `"""`)
+				for end >= 0 && source[i+3+end-1] == '\\' {
+					next := strings.Index(source[i+3+end+1:], `"""`)
+					if next < 0 {
+						end = -1
+						break
+					}
+					end += next + 1
+				}
+				if end < 0 {
+					return nil, gqlSyntaxError(source, start, "unterminated block string")
+				}
+				raw := source[i+3 : i+3+end]
+				i += 3 + end + 3
+				tokens = append(tokens, gqlToken{kind: 's', value: blockStringValue(strings.ReplaceAll(raw, `\"""`, `"""`)), start: start, end: i})
+				continue
+			}
+			var value strings.Builder
+			i++
+			for {
+				if i >= len(source) || source[i] == '\n' {
+					return nil, gqlSyntaxError(source, start, "unterminated string")
+				}
+				if source[i] == '"' {
+					i++
+					break
+				}
+				if source[i] == '\\' && i+1 < len(source) {
+					switch source[i+1] {
+					case 'n':
+						value.WriteByte('\n')
+					case 't':
+						value.WriteByte('\t')
+					case 'u':
+						if i+6 <= len(source) {
+							var r rune
+							if _, err := fmt.Sscanf(source[i+2:i+6], "%04x", &r); err == nil {
+								value.WriteRune(r)
+								i += 6
+								continue
+							}
+						}
+						value.WriteByte('u')
+					default:
+						value.WriteByte(source[i+1])
+					}
+					i += 2
+					continue
+				}
+				value.WriteByte(source[i])
+				i++
+			}
+			tokens = append(tokens, gqlToken{kind: 's', value: value.String(), start: start, end: i})
+		case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			start := i
+			for i < len(source) && (source[i] == '_' || (source[i] >= 'a' && source[i] <= 'z') || (source[i] >= 'A' && source[i] <= 'Z') || (source[i] >= '0' && source[i] <= '9')) {
+				i++
+			}
+			tokens = append(tokens, gqlToken{kind: 'n', value: source[start:i], start: start, end: i})
+		case c == '-' || (c >= '0' && c <= '9'):
+			start := i
+			i++
+			for i < len(source) && strings.IndexByte("0123456789.eE+-", source[i]) >= 0 {
+				i++
+			}
+			tokens = append(tokens, gqlToken{kind: '0', value: source[start:i], start: start, end: i})
+		case strings.HasPrefix(source[i:], "..."):
+			tokens = append(tokens, gqlToken{kind: 'p', value: "...", start: i, end: i + 3})
+			i += 3
+		case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+			tokens = append(tokens, gqlToken{kind: 'p', value: string(c), start: i, end: i + 1})
+			i++
+		default:
+			return nil, gqlSyntaxError(source, i, fmt.Sprintf("unexpected character %q", c))
+		}
+	}
+	return append(tokens, gqlToken{start: len(source), end: len(source)}), nil
+}
+
+// blockStringValue removes the common indentation and the leading and trailing blank lines
+// of a block string
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// gqlSyntaxError reports a syntax error at an offset of the document with its line number
+func gqlSyntaxError(source string, offset int, message string) error {
+	return fmt.Errorf("%w: line %d: %s", ErrInvalidSDL, strings.Count(source[:offset], "\n")+1, message)
+}
+
+// gqlParser parses the tokens of an SDL document
+type gqlParser struct {
+	source string
+	tokens []gqlToken
+	pos    int
+}
+
+func (p *gqlParser) peek() gqlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *gqlParser) next() gqlToken {
+	token := p.tokens[p.pos]
+	if token.kind != 0 {
+		p.pos++
+	}
+	return token
+}
+
+// is reports whether the next token is the given punctuator or name
+func (p *gqlParser) is(value string) bool {
+	token := p.peek()
+	return (token.kind == 'p' || token.kind == 'n') && token.value == value
+}
+
+// accept consumes the next token if it is the given punctuator or name
+func (p *gqlParser) accept(value string) bool {
+	if p.is(value) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *gqlParser) expect(value string) error {
+	if !p.accept(value) {
+		return p.unexpected(fmt.Sprintf("%q", value))
+	}
+	return nil
+}
+
+func (p *gqlParser) name() (string, error) {
+	token := p.peek()
+	if token.kind != 'n' {
+		return "", p.unexpected("a name")
+	}
+	p.pos++
+	return token.value, nil
+}
+
+func (p *gqlParser) unexpected(expected string) error {
+	token := p.peek()
+	found := "end of document"
+	if token.kind != 0 {
+		found = fmt.Sprintf("%q", p.source[token.start:token.end])
+	}
+	return gqlSyntaxError(p.source, token.start, fmt.Sprintf("expected %s, found %s", expected, found))
+}
+
+// description consumes an optional description string
+func (p *gqlParser) description() string {
+	if p.peek().kind == 's' {
+		return strings.TrimSpace(p.next().value)
+	}
+	return ""
+}
+
+// document parses every definition of the document into schema
+func (p *gqlParser) document(schema *gqlSchema) error {
+	for p.peek().kind != 0 {
+		description := p.description()
+		extend := p.accept("extend")
+
+		keyword, err := p.name()
+		if err != nil {
+			return err
+		}
+
+		switch keyword {
+		case "schema":
+			if err := p.schemaDefinition(schema); err != nil {
+				return err
+			}
+		case "directive":
+			if err := p.skipDirectiveDefinition(); err != nil {
+				return err
+			}
+		case "type", "interface", "input", "enum", "union", "scalar":
+			name, err := p.name()
+			if err != nil {
+				return err
+			}
+			t := schema.types[name]
+			if t == nil {
+				t = &gqlType{name: name}
+				schema.types[name] = t
+			}
+			t.kind = keyword
+			if !extend {
+				if t.defined {
+					return gqlSyntaxError(p.source, p.tokens[p.pos-1].start, fmt.Sprintf("type %s is defined twice", name))
+				}
+				t.defined = true
+				t.description = description
+			}
+			if err := p.typeBody(t, keyword); err != nil {
+				return err
+			}
+		default:
+			return gqlSyntaxError(p.source, p.tokens[p.pos-1].start, fmt.Sprintf("unexpected %q: only type system definitions are supported", keyword))
+		}
+	}
+	return nil
+}
+
+// schemaDefinition parses the root operation types of a schema definition
+func (p *gqlParser) schemaDefinition(schema *gqlSchema) error {
+	if err := p.skipDirectives(); err != nil {
+		return err
+	}
+	if !p.accept("{") {
+		return nil
+	}
+	for !p.accept("}") {
+		operation, err := p.name()
+		if err != nil {
+			return err
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		schema.roots[operation] = name
+	}
+	return nil
+}
+
+// typeBody parses what follows the name of a type definition or extension
+func (p *gqlParser) typeBody(t *gqlType, keyword string) error {
+	if keyword == "type" || keyword == "interface" {
+		if p.accept("implements") {
+			p.accept("&")
+			for p.peek().kind == 'n' && !p.is("{") {
+				name, _ := p.name()
+				t.interfaces = append(t.interfaces, name)
+				if !p.accept("&") {
+					break
+				}
+			}
+		}
+	}
+	if err := p.skipDirectives(); err != nil {
+		return err
+	}
+
+	switch keyword {
+	case "scalar":
+		return nil
+	case "union":
+		if !p.accept("=") {
+			return nil
+		}
+		p.accept("|")
+		for {
+			name, err := p.name()
+			if err != nil {
+				return err
+			}
+			t.members = append(t.members, name)
+			if !p.accept("|") {
+				return nil
+			}
+		}
+	case "enum":
+		if !p.accept("{") {
+			return nil
+		}
+		for !p.accept("}") {
+			description := p.description()
+			name, err := p.name()
+			if err != nil {
+				return err
+			}
+			if err := p.skipDirectives(); err != nil {
+				return err
+			}
+			t.values = append(t.values, gqlEnumValue{name: name, description: description})
+		}
+		return nil
+	}
+
+	if !p.accept("{") {
+		return nil
+	}
+	for !p.accept("}") {
+		field, err := p.field(keyword != "input")
+		if err != nil {
+			return err
+		}
+		t.fields = append(t.fields, field)
+	}
+	return nil
+}
+
+// field parses a field with its arguments, or an input field or argument without them
+func (p *gqlParser) field(withArgs bool) (*gqlField, error) {
+	field := &gqlField{description: p.description()}
+
+	var err error
+	if field.name, err = p.name(); err != nil {
+		return nil, err
+	}
+
+	if withArgs && p.accept("(") {
+		for !p.accept(")") {
+			arg, err := p.field(false)
+			if err != nil {
+				return nil, err
+			}
+			field.args = append(field.args, arg)
+		}
+	}
+
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	if field.typ, err = p.typeRef(); err != nil {
+		return nil, err
+	}
+
+	if p.accept("=") {
+		start := p.peek().start
+		if err := p.skipValue(); err != nil {
+			return nil, err
+		}
+		field.defaultValue = strings.TrimSpace(p.source[start:p.tokens[p.pos-1].end])
+	}
+
+	for p.is("@") {
+		directive := p.tokens[p.pos+1]
+		if directive.kind == 'n' && directive.value == "deprecated" {
+			field.deprecated = true
+		}
+		if err := p.skipDirective(); err != nil {
+			return nil, err
+		}
+	}
+	return field, nil
+}
+
+// typeRef parses a type reference such as [String!]!
+func (p *gqlParser) typeRef() (gqlTypeRef, error) {
+	var ref gqlTypeRef
+	if p.accept("[") {
+		elem, err := p.typeRef()
+		if err != nil {
+			return ref, err
+		}
+		if err := p.expect("]"); err != nil {
+			return ref, err
+		}
+		ref.elem = &elem
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return ref, err
+		}
+		ref.name = name
+	}
+	ref.nonNull = p.accept("!")
+	return ref, nil
+}
+
+// skipValue skips a constant value: a scalar, an enum value, a list or an object
+func (p *gqlParser) skipValue() error {
+	token := p.next()
+	switch {
+	case token.kind == 0:
+		return p.unexpected("a value")
+	case token.kind == 'p' && token.value == "$":
+		_, err := p.name()
+		return err
+	case token.kind == 'p' && token.value == "[":
+		for !p.accept("]") {
+			if err := p.skipValue(); err != nil {
+				return err
+			}
+		}
+	case token.kind == 'p' && token.value == "{":
+		for !p.accept("}") {
+			if _, err := p.name(); err != nil {
+				return err
+			}
+			if err := p.expect(":"); err != nil {
+				return err
+			}
+			if err := p.skipValue(); err != nil {
+				return err
+			}
+		}
+	case token.kind == 'p':
+		p.pos--
+		return p.unexpected("a value")
+	}
+	return nil
+}
+
+// skipDirectives skips directives such as @key(fields: "id")
+func (p *gqlParser) skipDirectives() error {
+	for p.is("@") {
+		if err := p.skipDirective(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *gqlParser) skipDirective() error {
+	if err := p.expect("@"); err != nil {
+		return err
+	}
+	if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.accept("(") {
+		for !p.accept(")") {
+			if _, err := p.name(); err != nil {
+				return err
+			}
+			if err := p.expect(":"); err != nil {
+				return err
+			}
+			if err := p.skipValue(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// skipDirectiveDefinition skips a directive definition up to its last location
+func (p *gqlParser) skipDirectiveDefinition() error {
+	if err := p.expect("@"); err != nil {
+		return err
+	}
+	if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.accept("(") {
+		for !p.accept(")") {
+			if _, err := p.field(false); err != nil {
+				return err
+			}
+		}
+	}
+	p.accept("repeatable")
+	if err := p.expect("on"); err != nil {
+		return err
+	}
+	p.accept("|")
+	for {
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if !p.accept("|") {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidHAR is returned for files that are not HAR archives
+var ErrInvalidHAR = errors.New("invalid HAR file")
+
+// harFile is the part of a HAR 1.2 archive the importer reads
+type harFile struct {
+	Log *struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	Request struct {
+		Method      string         `json:"method"`
+		URL         string         `json:"url"`
+		QueryString []harNameValue `json:"queryString"`
+		PostData    *struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+		} `json:"postData"`
+	} `json:"request"`
+	Response struct {
+		Status  int `json:"status"`
+		Content struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+			Encoding string `json:"encoding"`
+		} `json:"content"`
+	} `json:"response"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARImportOptions selects the entries of a HAR file to import
+type HARImportOptions struct {
+	Host string // Only import calls to this host, e.g. api.example.com; all hosts if empty
+}
+
+// HARImportCandidate is an API found in a HAR file: the calls with the same method and
+// normalized path, with the parameters inferred from their bodies
+type HARImportCandidate struct {
+	Key                string          `json:"key"` // Method and endpoint, e.g. "GET /users/:id"
+	Name               string          `json:"name"`
+	Method             string          `json:"method"`
+	Endpoint           string          `json:"endpoint"`
+	Host               string          `json:"host"`
+	Calls              int             `json:"calls"`
+	StatusCodes        []int           `json:"statusCodes"`
+	ExistingAPIID      *uint           `json:"existingApiId,omitempty"` // Documented API of the group the calls match
+	RequestParameters  []ParameterNode `json:"requestParameters"`
+	ResponseParameters []ParameterNode `json:"responseParameters"`
+}
+
+// PreviewHARImport lists the APIs a HAR file would create in a group, in the order they were
+// first called. Only JSON calls are considered: calls with a JSON request or response body.
+// Calls are deduplicated by method and path, with identifier segments replaced as by the
+// recording proxy (/users/42 becomes /users/:id). Request parameters are inferred from the
+// JSON request bodies, or from the query strings of calls without a body; response
+// parameters from the JSON bodies of successful responses. Calls matching a documented API
+// of the group are marked with its ID.
+func PreviewHARImport(db *gorm.DB, group models.Group, data []byte, opts HARImportOptions) ([]HARImportCandidate, error) {
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHAR, err)
+	}
+	if har.Log == nil {
+		return nil, fmt.Errorf("%w: log.entries is missing", ErrInvalidHAR)
+	}
+
+	routes, err := LoadGroupMockRoutes(db, group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	type harEndpoint struct {
+		candidate *HARImportCandidate
+		request   *SchemaInferrer
+		response  *SchemaInferrer
+		statuses  map[int]bool
+	}
+	var order []string
+	endpoints := make(map[string]*harEndpoint)
+
+	for _, entry := range har.Log.Entries {
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		if opts.Host != "" && !strings.EqualFold(u.Host, opts.Host) && !strings.EqualFold(u.Hostname(), opts.Host) {
+			continue
+		}
+
+		var requestBody, responseBody []byte
+		if entry.Request.PostData != nil && isJSONMimeType(entry.Request.PostData.MimeType) {
+			requestBody = []byte(entry.Request.PostData.Text)
+		}
+		if isJSONMimeType(entry.Response.Content.MimeType) {
+			responseBody = harContent(entry.Response.Content.Text, entry.Response.Content.Encoding)
+		}
+		if requestBody == nil && responseBody == nil {
+			// Pages, scripts, styles and images are not API calls
+			continue
+		}
+
+		method := strings.ToUpper(entry.Request.Method)
+		path := u.EscapedPath()
+		endpoint := RecordedPathTemplate(path)
+		var existingID *uint
+		if route, _ := MatchMockRoute(routes, method, path); route != nil {
+			id := route.API.ID
+			existingID = &id
+			endpoint = route.API.Endpoint
+		}
+
+		key := method + " " + endpoint
+		recorded := endpoints[key]
+		if recorded == nil {
+			recorded = &harEndpoint{
+				candidate: &HARImportCandidate{
+					Key:           key,
+					Name:          key,
+					Method:        method,
+					Endpoint:      endpoint,
+					Host:          u.Host,
+					ExistingAPIID: existingID,
+				},
+				request:  NewSchemaInferrer(),
+				response: NewSchemaInferrer(),
+				statuses: make(map[int]bool),
+			}
+			endpoints[key] = recorded
+			order = append(order, key)
+		}
+		recorded.candidate.Calls++
+		recorded.statuses[entry.Response.Status] = true
+
+		if sample, ok := decodeRecordedObject(requestBody); ok {
+			recorded.request.Add(sample)
+		} else if len(requestBody) == 0 {
+			if sample, ok := harQueryObject(entry.Request.QueryString, u.Query()); ok {
+				recorded.request.Add(sample)
+			}
+		}
+		if entry.Response.Status >= 200 && entry.Response.Status < 300 {
+			if sample, ok := decodeRecordedObject(responseBody); ok {
+				recorded.response.Add(sample)
+			}
+		}
+	}
+
+	candidates := make([]HARImportCandidate, 0, len(order))
+	for _, key := range order {
+		recorded := endpoints[key]
+		candidate := *recorded.candidate
+		candidate.RequestParameters = recorded.request.Nodes()
+		candidate.ResponseParameters = recorded.response.Nodes()
+		candidate.StatusCodes = make([]int, 0, len(recorded.statuses))
+		for status := range recorded.statuses {
+			candidate.StatusCodes = append(candidate.StatusCodes, status)
+		}
+		sort.Ints(candidate.StatusCodes)
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+// ImportHARCandidates creates the APIs of the selected candidates in a group, with their
+// inferred parameters, in a single transaction. An empty selection imports every candidate.
+// Candidates matching a documented API are skipped. It returns the created APIs.
+func ImportHARCandidates(db *gorm.DB, group models.Group, candidates []HARImportCandidate, keys []string) ([]models.API, error) {
+	selected := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		selected[key] = true
+	}
+
+	created := []models.API{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var maxOrder int
+		if err := tx.Model(&models.API{}).Where("group_id = ?", group.ID).Select("COALESCE(MAX(`order`), 0)").Scan(&maxOrder).Error; err != nil {
+			return err
+		}
+
+		for _, candidate := range candidates {
+			if (len(keys) > 0 && !selected[candidate.Key]) || candidate.ExistingAPIID != nil {
+				continue
+			}
+
+			maxOrder++
+			api := models.API{
+				GroupID:  group.ID,
+				Name:     candidate.Name,
+				Endpoint: candidate.Endpoint,
+				Method:   candidate.Method,
+				Type:     "HTTP",
+				Order:    maxOrder,
+			}
+			if err := tx.Create(&api).Error; err != nil {
+				return err
+			}
+			if _, err := ReplaceParameterTree(tx, api.ID, "request", candidate.RequestParameters); err != nil {
+				return err
+			}
+			if _, err := ReplaceParameterTree(tx, api.ID, "response", candidate.ResponseParameters); err != nil {
+				return err
+			}
+			created = append(created, api)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// isJSONMimeType reports whether a MIME type denotes JSON, e.g. application/problem+json
+func isJSONMimeType(mimeType string) bool {
+	mimeType = strings.ToLower(strings.TrimSpace(strings.SplitN(mimeType, ";", 2)[0]))
+	return mimeType == "application/json" || strings.HasSuffix(mimeType, "+json")
+}
+
+// harContent returns the text of a HAR content object, decoding base64 content
+func harContent(text, encoding string) []byte {
+	if encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return nil
+		}
+		return decoded
+	}
+	return []byte(text)
+}
+
+// harQueryObject turns the query string of a HAR request into a sample object of string
+// fields in the order of the URL. Repeated names become arrays.
+func harQueryObject(queryString []harNameValue, fallback url.Values) (*OrderedObject, bool) {
+	if len(queryString) == 0 {
+		for _, key := range sortedKeys(fallback) {
+			for _, value := range fallback[key] {
+				queryString = append(queryString, harNameValue{Name: key, Value: value})
+			}
+		}
+	}
+	if len(queryString) == 0 {
+		return nil, false
+	}
+
+	obj := NewOrderedObject()
+	for _, param := range queryString {
+		switch existing := obj.Values[param.Name].(type) {
+		case nil:
+			obj.Set(param.Name, param.Value)
+		case []interface{}:
+			obj.Set(param.Name, append(existing, param.Value))
+		default:
+			obj.Set(param.Name, []interface{}{existing, param.Value})
+		}
+	}
+	return obj, true
+}
+
+// sortedKeys returns the keys of query values in order
+func sortedKeys(values url.Values) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
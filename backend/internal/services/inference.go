@@ -0,0 +1,222 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// arrayItemName names the single child that describes the items of a primitive array
+const arrayItemName = "item"
+
+// inferredTypeOrder is the order in which the members of a type union are listed
+var inferredTypeOrder = []string{"object", "array", "string", "number", "integer", "boolean"}
+
+// SchemaInferrer merges JSON samples into a parameter tree. Array items of every sample
+// are merged into one shape, a field is required only when it is present in every object
+// observed at its position, and fields that were null at least once are nullable.
+// Integers and decimals are told apart, values of different types become a union such as
+// string|number, and fields keep the order in which they first appeared.
+type SchemaInferrer struct {
+	root *inferredShape
+}
+
+// inferredShape accumulates what was observed at one position of the samples
+type inferredShape struct {
+	types   map[string]int            // observed type name to number of observations
+	objects int                       // number of objects observed, used for required fields
+	fields  map[string]*inferredShape // fields of observed objects
+	order   []string                  // field names in order of first appearance
+	present map[string]int            // number of objects in which each field was present
+	items   *inferredShape            // elements of observed arrays
+}
+
+// NewSchemaInferrer creates an empty inferrer
+func NewSchemaInferrer() *SchemaInferrer {
+	return &SchemaInferrer{root: newInferredShape()}
+}
+
+// InferParameterNodes infers a parameter tree from decoded JSON object samples.
+// Samples decoded with DecodeOrderedJSON keep their key order; plain maps are read in key order.
+func InferParameterNodes(samples ...interface{}) []ParameterNode {
+	inferrer := NewSchemaInferrer()
+	for _, sample := range samples {
+		inferrer.Add(sample)
+	}
+	return inferrer.Nodes()
+}
+
+// InferParameterNodesFromJSON infers a parameter tree from raw JSON object samples
+func InferParameterNodesFromJSON(samples ...[]byte) ([]ParameterNode, error) {
+	inferrer := NewSchemaInferrer()
+	for i, data := range samples {
+		sample, err := DecodeOrderedJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("sample %d is not valid JSON: %w", i, err)
+		}
+		if _, ok := sample.(*OrderedObject); !ok {
+			return nil, fmt.Errorf("sample %d is not a JSON object", i)
+		}
+		inferrer.Add(sample)
+	}
+	return inferrer.Nodes(), nil
+}
+
+// Add merges a decoded JSON sample into the inferred schema
+func (s *SchemaInferrer) Add(sample interface{}) {
+	s.root.observe(sample)
+}
+
+// Samples returns the number of samples added so far
+func (s *SchemaInferrer) Samples() int {
+	total := 0
+	for _, count := range s.root.types {
+		total += count
+	}
+	return total
+}
+
+// Nodes returns the fields of the inferred root object as parameter nodes
+func (s *SchemaInferrer) Nodes() []ParameterNode {
+	return s.root.fieldNodes()
+}
+
+func newInferredShape() *inferredShape {
+	return &inferredShape{
+		types:   make(map[string]int),
+		fields:  make(map[string]*inferredShape),
+		present: make(map[string]int),
+	}
+}
+
+// observe records a value at this position
+func (s *inferredShape) observe(value interface{}) {
+	switch v := value.(type) {
+	case *OrderedObject:
+		s.types["object"]++
+		s.objects++
+		for _, key := range v.Keys {
+			s.observeField(key, v.Values[key])
+		}
+	case map[string]interface{}:
+		s.types["object"]++
+		s.objects++
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s.observeField(key, v[key])
+		}
+	case []interface{}:
+		s.types["array"]++
+		if s.items == nil {
+			s.items = newInferredShape()
+		}
+		for _, item := range v {
+			s.items.observe(item)
+		}
+	default:
+		s.types[inferredTypeName(value)]++
+	}
+}
+
+// observeField records the value of an object field
+func (s *inferredShape) observeField(key string, value interface{}) {
+	field := s.fields[key]
+	if field == nil {
+		field = newInferredShape()
+		s.fields[key] = field
+		s.order = append(s.order, key)
+	}
+	s.present[key]++
+	field.observe(value)
+}
+
+// paramType returns the observed types as a parameter type. Integers merge into number
+// when decimals were seen as well; positions that were only ever null default to string.
+func (s *inferredShape) paramType() string {
+	members := make([]string, 0, 1)
+	for _, name := range inferredTypeOrder {
+		if s.types[name] == 0 {
+			continue
+		}
+		if name == "integer" && s.types["number"] > 0 {
+			continue
+		}
+		members = append(members, name)
+	}
+
+	if len(members) == 0 {
+		return "string"
+	}
+	return strings.Join(members, "|")
+}
+
+// fieldNodes converts the observed object fields into parameter nodes
+func (s *inferredShape) fieldNodes() []ParameterNode {
+	nodes := make([]ParameterNode, 0, len(s.order))
+	for _, key := range s.order {
+		node := s.fields[key].node(key)
+		node.Required = s.present[key] == s.objects
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// node converts this position into a parameter node
+func (s *inferredShape) node(name string) ParameterNode {
+	node := ParameterNode{
+		Name:     name,
+		Type:     s.paramType(),
+		Nullable: s.types["null"] > 0,
+	}
+
+	if s.types["object"] > 0 {
+		node.Children = s.fieldNodes()
+	} else if s.items != nil && len(s.items.types) > 0 {
+		if s.items.types["object"] > 0 {
+			node.Children = s.items.fieldNodes()
+		} else {
+			// A single primitive child describes the item type, as in GenerateExampleJSON
+			node.Children = []ParameterNode{{
+				Name:     arrayItemName,
+				Type:     s.items.paramType(),
+				Required: true,
+				Nullable: s.items.types["null"] > 0,
+			}}
+		}
+	}
+	return node
+}
+
+// inferredTypeName returns the type of a decoded primitive, telling integers from decimals
+func inferredTypeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if strings.ContainsAny(string(v), ".eE") {
+			return "number"
+		}
+		return "integer"
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return "integer"
+		}
+		return "number"
+	case float32:
+		return inferredTypeName(float64(v))
+	case int, int64, int32, uint, uint64, uint32:
+		return "integer"
+	default:
+		return jsonTypeName(value)
+	}
+}
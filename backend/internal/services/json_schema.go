@@ -0,0 +1,143 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+)
+
+// JSONSchemaDialect is the JSON Schema version of generated schemas
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchemaTypes are the JSON Schema types a parameter type may name
+var jsonSchemaTypes = map[string]bool{
+	"string": true, "integer": true, "number": true, "boolean": true, "array": true, "object": true, "null": true,
+}
+
+// JSONSchemaID returns the $id of the request or response schema of an API. It depends only
+// on the API ID, so references to it survive renames.
+func JSONSchemaID(apiID uint, paramType string) string {
+	return fmt.Sprintf("knot://apis/%d/%s.schema.json", apiID, paramType)
+}
+
+// GenerateJSONSchema generates the draft 2020-12 JSON Schema of the request or response body
+// of an API from its parameter tree
+func GenerateJSONSchema(api models.API, paramType string, tree []models.Parameter) *OrderedObject {
+	schema := NewOrderedObject()
+	schema.Set("$schema", JSONSchemaDialect)
+	schema.Set("$id", JSONSchemaID(api.ID, paramType))
+	schema.Set("title", strings.TrimSpace(api.Name+" "+paramType))
+
+	endpoint := strings.TrimSpace(api.Method + " " + api.Endpoint)
+	if api.Type == models.APITypeEvent && paramType == "request" {
+		schema.Set("description", "Message payload of "+endpoint)
+	} else if paramType == "response" {
+		schema.Set("description", "Response body of "+endpoint)
+	} else {
+		schema.Set("description", "Request body of "+endpoint)
+	}
+
+	writeJSONSchemaObject(schema, tree)
+	return schema
+}
+
+// jsonSchemaParameter returns the schema of a parameter
+func jsonSchemaParameter(param models.Parameter) *OrderedObject {
+	schema := NewOrderedObject()
+
+	var types []string
+	valid := true
+	for _, member := range strings.Split(param.Type, "|") {
+		member = strings.TrimSpace(member)
+		if !jsonSchemaTypes[member] {
+			valid = false
+			break
+		}
+		types = append(types, member)
+	}
+	if !valid {
+		// Unknown types accept any value
+		types = nil
+	} else if param.Nullable && !containsString(types, "null") {
+		types = append(types, "null")
+	}
+
+	switch len(types) {
+	case 0:
+	case 1:
+		schema.Set("type", types[0])
+	default:
+		schema.Set("type", types)
+	}
+
+	if param.Description != nil && strings.TrimSpace(*param.Description) != "" {
+		schema.Set("description", strings.TrimSpace(*param.Description))
+	}
+
+	switch {
+	case containsString(types, "array"):
+		switch {
+		case len(param.Children) == 0:
+		case len(param.Children) == 1 && param.Children[0].Name == "item":
+			// The single child of a primitive array describes its items
+			schema.Set("items", jsonSchemaParameter(param.Children[0]))
+		default:
+			items := NewOrderedObject()
+			items.Set("type", "object")
+			writeJSONSchemaObject(items, param.Children)
+			schema.Set("items", items)
+		}
+	case containsString(types, "object") && len(param.Children) > 0:
+		writeJSONSchemaObject(schema, param.Children)
+	}
+
+	return schema
+}
+
+// writeJSONSchemaObject sets the type, properties and required fields of an object schema
+func writeJSONSchemaObject(schema *OrderedObject, params []models.Parameter) {
+	if _, ok := schema.Get("type"); !ok {
+		schema.Set("type", "object")
+	}
+
+	properties := NewOrderedObject()
+	required := []string{}
+	for _, param := range params {
+		properties.Set(param.Name, jsonSchemaParameter(param))
+		if param.Required {
+			required = append(required, param.Name)
+		}
+	}
+	schema.Set("properties", properties)
+	if len(required) > 0 {
+		schema.Set("required", required)
+	}
+}
+
+// GenerateJSONSchemaFiles generates the request and response schemas of APIs as files named
+// <group slug>/<API slug>.request.schema.json and .response.schema.json
+func GenerateJSONSchemaFiles(apis []APIWithParams) ([]ExportFile, error) {
+	var files []ExportFile
+	for _, group := range groupExportAPIs(apis) {
+		for i, api := range group.apis {
+			for _, paramType := range []string{"request", "response"} {
+				params := api.RequestParameters
+				if paramType == "response" {
+					params = api.ResponseParameters
+				}
+
+				content, err := json.MarshalIndent(GenerateJSONSchema(api.API, paramType, BuildParameterTree(params)), "", "  ")
+				if err != nil {
+					return nil, err
+				}
+				files = append(files, ExportFile{
+					Name:    group.slug + "/" + group.apiSlugs[i] + "." + paramType + ".schema.json",
+					Content: string(content) + "\n",
+				})
+			}
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,424 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// schemaShape is a JSON Schema with $ref, allOf, oneOf and anyOf folded in
+type schemaShape struct {
+	types       []string // Parameter types in order, without null
+	nullable    bool
+	description string
+	properties  *OrderedObject  // Property schemas by name, not yet folded
+	required    map[string]bool // Required property names
+	items       interface{}     // Schema of array items, not yet folded
+	refs        []string        // Local references followed to build the shape
+}
+
+// jsonSchemaImporter converts the schemas of one document into parameter nodes
+type jsonSchemaImporter struct {
+	root   interface{}
+	active map[string]bool // References being expanded, to stop at recursive schemas
+}
+
+// ParameterNodesFromJSONSchema converts a JSON Schema document describing an object into a
+// parameter tree. Properties keep their order; required and descriptions (or titles) are
+// taken over. Local $ref such as #/$defs/Address are resolved, allOf members are merged, and
+// the alternatives of oneOf and anyOf are combined into one node whose type is the union of
+// their types and whose description lists them. A null type makes a node nullable, and
+// schemas without a type become strings. Recursive references stop at an empty object.
+func ParameterNodesFromJSONSchema(data []byte) ([]ParameterNode, error) {
+	document, err := DecodeOrderedJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if _, ok := document.(*OrderedObject); !ok {
+		return nil, errors.New("a JSON Schema document must be an object")
+	}
+	return parameterNodesFromSchema(document, document)
+}
+
+// errSchemaNotObject is returned for schemas of values other than objects
+var errSchemaNotObject = errors.New("the root schema must describe an object")
+
+// parameterNodesFromSchema converts an object schema embedded in a document, such as the
+// payload of an AsyncAPI message, resolving local references against the document
+func parameterNodesFromSchema(document, schema interface{}) ([]ParameterNode, error) {
+	imp := &jsonSchemaImporter{root: document, active: make(map[string]bool)}
+	shape, err := imp.shape(schema)
+	if err != nil {
+		return nil, err
+	}
+	if shape.properties == nil && !containsString(shape.types, "object") {
+		return nil, errSchemaNotObject
+	}
+
+	return imp.withRefs(shape.refs, func() ([]ParameterNode, error) {
+		return imp.fields(shape)
+	})
+}
+
+// withRefs runs build while the references of a shape are being expanded
+func (imp *jsonSchemaImporter) withRefs(refs []string, build func() ([]ParameterNode, error)) ([]ParameterNode, error) {
+	var added []string
+	for _, ref := range refs {
+		if !imp.active[ref] {
+			imp.active[ref] = true
+			added = append(added, ref)
+		}
+	}
+	defer func() {
+		for _, ref := range added {
+			delete(imp.active, ref)
+		}
+	}()
+	return build()
+}
+
+// fields converts the properties of an object shape into parameter nodes
+func (imp *jsonSchemaImporter) fields(shape schemaShape) ([]ParameterNode, error) {
+	if shape.properties == nil {
+		return []ParameterNode{}, nil
+	}
+
+	nodes := make([]ParameterNode, 0, shape.properties.Len())
+	for _, name := range shape.properties.Keys {
+		node, err := imp.node(name, shape.properties.Values[name])
+		if err != nil {
+			return nil, err
+		}
+		node.Required = shape.required[name]
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// node converts a property or item schema into a parameter node
+func (imp *jsonSchemaImporter) node(name string, schema interface{}) (ParameterNode, error) {
+	shape, err := imp.shape(schema)
+	if err != nil {
+		return ParameterNode{}, err
+	}
+
+	types := shape.types
+	if len(types) == 0 {
+		switch {
+		case shape.properties != nil:
+			types = []string{"object"}
+		case shape.items != nil:
+			types = []string{"array"}
+		default:
+			types = []string{"string"}
+		}
+	}
+
+	node := ParameterNode{
+		Name:     name,
+		Type:     strings.Join(types, "|"),
+		Nullable: shape.nullable,
+	}
+	if shape.description != "" {
+		description := shape.description
+		node.Description = &description
+	}
+
+	children, err := imp.withRefs(shape.refs, func() ([]ParameterNode, error) {
+		switch {
+		case containsString(types, "object") && shape.properties != nil:
+			return imp.fields(shape)
+		case containsString(types, "array") && shape.items != nil:
+			return imp.itemNodes(shape.items)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return ParameterNode{}, err
+	}
+	if len(children) > 0 {
+		node.Children = children
+	}
+	return node, nil
+}
+
+// itemNodes converts the item schema of an array: the fields of object items, or a single
+// "item" child describing other items as in GenerateExampleJSON
+func (imp *jsonSchemaImporter) itemNodes(items interface{}) ([]ParameterNode, error) {
+	shape, err := imp.shape(items)
+	if err != nil {
+		return nil, err
+	}
+	if shape.properties != nil && (len(shape.types) == 0 || containsString(shape.types, "object")) {
+		return imp.withRefs(shape.refs, func() ([]ParameterNode, error) {
+			return imp.fields(shape)
+		})
+	}
+	if len(shape.types) == 1 && shape.types[0] == "object" {
+		// Objects without fields, e.g. at a recursive reference, leave the items undescribed
+		return nil, nil
+	}
+
+	item, err := imp.node(arrayItemName, items)
+	if err != nil {
+		return nil, err
+	}
+	item.Required = true
+	return []ParameterNode{item}, nil
+}
+
+// shape folds $ref, allOf, oneOf and anyOf of a schema into one shape
+func (imp *jsonSchemaImporter) shape(value interface{}) (schemaShape, error) {
+	shape := schemaShape{required: make(map[string]bool)}
+
+	schema, ok := value.(*OrderedObject)
+	if !ok {
+		// true and false schemas accept anything or nothing
+		return shape, nil
+	}
+
+	if raw, ok := schema.Get("$ref"); ok {
+		ref, _ := raw.(string)
+		if imp.active[ref] {
+			// A recursive reference ends in an object without fields
+			shape.types = []string{"object"}
+			return shape, nil
+		}
+		target, err := imp.resolve(ref)
+		if err != nil {
+			return shape, err
+		}
+
+		imp.active[ref] = true
+		referenced, err := imp.shape(target)
+		delete(imp.active, ref)
+		if err != nil {
+			return shape, err
+		}
+		shape.merge(referenced)
+		shape.refs = append(shape.refs, ref)
+	}
+
+	if raw, ok := schema.Get("type"); ok {
+		var names []interface{}
+		if list, ok := raw.([]interface{}); ok {
+			names = list
+		} else {
+			names = []interface{}{raw}
+		}
+		var types []string
+		for _, item := range names {
+			name, _ := item.(string)
+			switch {
+			case name == "null":
+				shape.nullable = true
+			case IsParameterType(name):
+				types = append(types, name)
+			}
+		}
+		if len(types) > 0 {
+			shape.types = types
+		}
+	}
+
+	description, _ := schema.Values["description"].(string)
+	if strings.TrimSpace(description) == "" {
+		description, _ = schema.Values["title"].(string)
+	}
+	if description = strings.TrimSpace(description); description != "" {
+		shape.description = description
+	}
+
+	if properties, ok := schema.Values["properties"].(*OrderedObject); ok {
+		shape.mergeProperties(properties)
+	}
+	if required, ok := schema.Values["required"].([]interface{}); ok {
+		for _, item := range required {
+			if name, ok := item.(string); ok {
+				shape.required[name] = true
+			}
+		}
+	}
+
+	switch items := schema.Values["items"].(type) {
+	case nil:
+	case []interface{}:
+		// Tuples of older drafts are described by their first item
+		if len(items) > 0 {
+			shape.items = items[0]
+		}
+	default:
+		shape.items = items
+	}
+
+	if members, ok := schema.Values["allOf"].([]interface{}); ok {
+		for _, member := range members {
+			memberShape, err := imp.shape(member)
+			if err != nil {
+				return shape, err
+			}
+			shape.merge(memberShape)
+		}
+	}
+
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		alternatives, ok := schema.Values[keyword].([]interface{})
+		if !ok || len(alternatives) == 0 {
+			continue
+		}
+		if err := imp.mergeAlternatives(&shape, keyword, alternatives); err != nil {
+			return shape, err
+		}
+	}
+
+	return shape, nil
+}
+
+// mergeAlternatives combines the alternatives of oneOf or anyOf into a shape: the type becomes
+// the union of their types, properties of object alternatives are kept and only required if
+// every object alternative requires them, and the description lists the alternatives
+func (imp *jsonSchemaImporter) mergeAlternatives(shape *schemaShape, keyword string, alternatives []interface{}) error {
+	var labels []string
+	var requiredByAll map[string]bool
+
+	for _, alternative := range alternatives {
+		alt, err := imp.shape(alternative)
+		if err != nil {
+			return err
+		}
+
+		if len(alt.types) == 0 && !alt.nullable {
+			switch {
+			case alt.properties != nil:
+				alt.types = []string{"object"}
+			case alt.items != nil:
+				alt.types = []string{"array"}
+			}
+		}
+		for _, name := range alt.types {
+			if !containsString(shape.types, name) {
+				shape.types = append(shape.types, name)
+			}
+		}
+		shape.nullable = shape.nullable || alt.nullable
+
+		if alt.properties != nil {
+			shape.mergeProperties(alt.properties)
+			if requiredByAll == nil {
+				requiredByAll = alt.required
+			} else {
+				for name := range requiredByAll {
+					if !alt.required[name] {
+						delete(requiredByAll, name)
+					}
+				}
+			}
+		}
+		if shape.items == nil {
+			shape.items = alt.items
+		}
+		shape.refs = append(shape.refs, alt.refs...)
+
+		label := alt.description
+		if label == "" || len(label) > 40 {
+			label = strings.Join(alt.types, "|")
+			if alt.nullable {
+				label = strings.TrimPrefix(label+"|null", "|")
+			}
+		}
+		if label == "" {
+			label = "any"
+		}
+		labels = append(labels, label)
+	}
+
+	for name := range requiredByAll {
+		shape.required[name] = true
+	}
+
+	note := "One of: "
+	if keyword == "anyOf" {
+		note = "Any of: "
+	}
+	note += strings.Join(labels, ", ")
+	if shape.description != "" {
+		note = shape.description + " (" + note + ")"
+	}
+	shape.description = note
+	return nil
+}
+
+// merge adds the keywords of another shape, e.g. an allOf member or a referenced schema.
+// Keywords already set are kept.
+func (s *schemaShape) merge(other schemaShape) {
+	if len(s.types) == 0 {
+		s.types = other.types
+	}
+	s.nullable = s.nullable || other.nullable
+	if s.description == "" {
+		s.description = other.description
+	}
+	if other.properties != nil {
+		s.mergeProperties(other.properties)
+	}
+	for name := range other.required {
+		s.required[name] = true
+	}
+	if s.items == nil {
+		s.items = other.items
+	}
+	s.refs = append(s.refs, other.refs...)
+}
+
+// mergeProperties appends properties that are not defined yet
+func (s *schemaShape) mergeProperties(properties *OrderedObject) {
+	if s.properties == nil {
+		s.properties = NewOrderedObject()
+	}
+	for _, name := range properties.Keys {
+		if _, exists := s.properties.Get(name); !exists {
+			s.properties.Set(name, properties.Values[name])
+		}
+	}
+}
+
+// resolve returns the schema a local reference such as #/$defs/Address points to
+func (imp *jsonSchemaImporter) resolve(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("only local $ref are supported: %q", ref)
+	}
+	pointer, err := url.PathUnescape(strings.TrimPrefix(ref, "#"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref %q", ref)
+	}
+	if pointer == "" {
+		return imp.root, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("unsupported $ref %q: use a JSON pointer such as #/$defs/Name", ref)
+	}
+
+	current := imp.root
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := current.(type) {
+		case *OrderedObject:
+			value, ok := node.Get(token)
+			if !ok {
+				return nil, fmt.Errorf("$ref %q not found", ref)
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("$ref %q not found", ref)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("$ref %q not found", ref)
+		}
+	}
+	return current, nil
+}
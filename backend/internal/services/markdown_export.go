@@ -0,0 +1,159 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+)
+
+// GenerateMarkdown generates a single Markdown document from APIs, one section per group.
+// The output has no timestamps so that exports of unchanged data are identical.
+func GenerateMarkdown(apis []APIWithParams, locale string) string {
+	labels := newExportLabels(locale)
+
+	var md strings.Builder
+	md.WriteString("# " + labels.title + "\n")
+	for _, group := range groupExportAPIs(apis) {
+		md.WriteString("\n## " + group.name + "\n")
+		for _, api := range group.apis {
+			md.WriteString("\n")
+			writeMarkdownAPI(&md, api, "###", labels)
+		}
+	}
+	return md.String()
+}
+
+// GenerateMarkdownFiles generates one Markdown document per group, named after the group,
+// and a README.md index linking to them
+func GenerateMarkdownFiles(apis []APIWithParams, locale string) []ExportFile {
+	labels := newExportLabels(locale)
+
+	var index strings.Builder
+	index.WriteString("# " + labels.title + "\n\n")
+
+	files := []ExportFile{{Name: "README.md"}}
+	for _, group := range groupExportAPIs(apis) {
+		name := group.slug + ".md"
+		files = append(files, ExportFile{Name: name, Content: GenerateGroupMarkdown(group.name, group.apis, locale)})
+
+		index.WriteString(fmt.Sprintf("- [%s](%s) (%d)\n", escapeMarkdownLink(group.name), name, len(group.apis)))
+	}
+
+	files[0].Content = index.String()
+	return files
+}
+
+// GenerateGroupMarkdown generates the Markdown document of one group from its APIs
+func GenerateGroupMarkdown(name string, apis []APIWithParams, locale string) string {
+	labels := newExportLabels(locale)
+
+	var md strings.Builder
+	md.WriteString("# " + name + "\n")
+	for _, api := range apis {
+		md.WriteString("\n")
+		writeMarkdownAPI(&md, api, "##", labels)
+	}
+	return md.String()
+}
+
+// GenerateAPIMarkdown generates the Markdown document of a single API
+func GenerateAPIMarkdown(api APIWithParams, locale string) string {
+	var md strings.Builder
+	writeMarkdownAPI(&md, api, "#", newExportLabels(locale))
+	return md.String()
+}
+
+// writeMarkdownAPI writes the section of one API under a heading of the given level
+func writeMarkdownAPI(md *strings.Builder, api APIWithParams, heading string, labels exportLabels) {
+	endpoint := api.API.Endpoint
+	if api.URL != "" {
+		endpoint = api.URL
+	}
+
+	md.WriteString(heading + " " + api.API.Name + "\n\n")
+	if api.API.Method != "" {
+		md.WriteString(fmt.Sprintf("`%s` `%s` (%s)\n", api.API.Method, endpoint, api.API.Type))
+	} else {
+		md.WriteString(fmt.Sprintf("`%s` (%s)\n", endpoint, api.API.Type))
+	}
+
+	if api.API.Note != nil && strings.TrimSpace(*api.API.Note) != "" {
+		md.WriteString("\n" + heading + "# " + labels.note + "\n\n")
+		md.WriteString(strings.TrimSpace(*api.API.Note) + "\n")
+	}
+
+	if api.API.Signature != nil && *api.API.Signature != "" {
+		md.WriteString("\n" + heading + "# " + labels.signature + "\n\n")
+		md.WriteString("```graphql\n" + *api.API.Signature + "```\n")
+	}
+
+	sections := []struct {
+		params  []models.Parameter
+		title   string
+		example string
+	}{
+		{api.RequestParameters, labels.requestParams, labels.requestExample},
+		{api.ResponseParameters, labels.responseParams, labels.responseExample},
+	}
+	for _, section := range sections {
+		tree := BuildParameterTree(section.params)
+
+		md.WriteString("\n" + heading + "# " + section.title + "\n\n")
+		if len(tree) == 0 {
+			md.WriteString("_" + labels.noParameters + "_\n")
+			continue
+		}
+
+		md.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", labels.name, labels.paramType, labels.required, labels.description))
+		md.WriteString("| --- | --- | --- | --- |\n")
+		writeMarkdownParameterRows(md, tree, "", labels)
+
+		example, _ := json.MarshalIndent(GenerateExampleJSON(tree), "", "  ")
+		md.WriteString("\n" + heading + "# " + section.example + "\n\n")
+		md.WriteString("```json\n" + string(example) + "\n```\n")
+	}
+}
+
+// writeMarkdownParameterRows writes one table row per parameter, naming nested parameters by
+// their dotted path. Array items are written as name[] and name[].field.
+func writeMarkdownParameterRows(md *strings.Builder, params []models.Parameter, prefix string, labels exportLabels) {
+	for _, param := range params {
+		path := parameterPath(prefix, param, len(params))
+
+		paramType := param.Type
+		if param.Nullable {
+			paramType += "|null"
+		}
+
+		required := labels.no
+		if param.Required {
+			required = labels.yes
+		}
+
+		description := ""
+		if param.Description != nil {
+			description = *param.Description
+		}
+
+		md.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s |\n",
+			path, escapeMarkdownCell(paramType), required, escapeMarkdownCell(description)))
+
+		if len(param.Children) > 0 {
+			writeMarkdownParameterRows(md, param.Children, childPathPrefix(path, param.Type), labels)
+		}
+	}
+}
+
+// escapeMarkdownCell escapes text for use inside a table cell
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "<br>")
+}
+
+// escapeMarkdownLink escapes text for use as link text
+func escapeMarkdownLink(s string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`).Replace(s)
+}
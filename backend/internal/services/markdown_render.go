@@ -0,0 +1,142 @@
+package services
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	markdownHeading     = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	markdownBullet      = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	markdownNumbered    = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	markdownCodeSpan    = regexp.MustCompile("`([^`]+)`")
+	markdownLink        = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	markdownStrong      = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	markdownEmphasis    = regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+	markdownPlaceholder = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// RenderMarkdown renders the common subset of Markdown used in notes and descriptions
+// to HTML: headings, paragraphs, bullet and numbered lists, block quotes, fenced code
+// blocks, inline code, links, bold and italic text. Raw HTML is escaped.
+func RenderMarkdown(source string) string {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+
+	var out strings.Builder
+	var paragraph []string
+	list := ""
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + renderMarkdownInline(strings.Join(paragraph, "\n")) + "</p>\n")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if list != "" {
+			out.WriteString("</" + list + ">\n")
+			list = ""
+		}
+	}
+	openList := func(tag string) {
+		if list != tag {
+			closeList()
+			out.WriteString("<" + tag + ">\n")
+			list = tag
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flushParagraph()
+			closeList()
+			lang := strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			if lang != "" {
+				out.WriteString(`<pre><code class="language-` + html.EscapeString(lang) + `">`)
+			} else {
+				out.WriteString("<pre><code>")
+			}
+			out.WriteString(html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+
+		case trimmed == "":
+			flushParagraph()
+			closeList()
+
+		case markdownHeading.MatchString(trimmed):
+			flushParagraph()
+			closeList()
+			match := markdownHeading.FindStringSubmatch(trimmed)
+			level := string(rune('0' + len(match[1])))
+			out.WriteString("<h" + level + ">" + renderMarkdownInline(match[2]) + "</h" + level + ">\n")
+
+		case markdownBullet.MatchString(line):
+			flushParagraph()
+			openList("ul")
+			out.WriteString("<li>" + renderMarkdownInline(markdownBullet.FindStringSubmatch(line)[1]) + "</li>\n")
+
+		case markdownNumbered.MatchString(line):
+			flushParagraph()
+			openList("ol")
+			out.WriteString("<li>" + renderMarkdownInline(markdownNumbered.FindStringSubmatch(line)[1]) + "</li>\n")
+
+		case strings.HasPrefix(trimmed, ">"):
+			flushParagraph()
+			closeList()
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quote = append(quote, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">"), " "))
+			}
+			i--
+			out.WriteString("<blockquote>\n" + RenderMarkdown(strings.Join(quote, "\n")) + "</blockquote>\n")
+
+		default:
+			closeList()
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flushParagraph()
+	closeList()
+
+	return out.String()
+}
+
+// renderMarkdownInline renders inline code, links and emphasis of escaped text. Code spans
+// are set aside first so that their content is not formatted.
+func renderMarkdownInline(text string) string {
+	var spans []string
+	text = markdownCodeSpan.ReplaceAllStringFunc(text, func(match string) string {
+		spans = append(spans, "<code>"+html.EscapeString(match[1:len(match)-1])+"</code>")
+		return "\x00" + strconv.Itoa(len(spans)-1) + "\x00"
+	})
+
+	text = html.EscapeString(text)
+	text = markdownLink.ReplaceAllStringFunc(text, func(match string) string {
+		parts := markdownLink.FindStringSubmatch(match)
+		href := parts[2]
+		if strings.HasPrefix(strings.ToLower(html.UnescapeString(href)), "javascript:") {
+			return parts[1]
+		}
+		return `<a href="` + href + `">` + parts[1] + "</a>"
+	})
+	text = markdownStrong.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = markdownEmphasis.ReplaceAllString(text, "<em>$1$2</em>")
+	text = strings.ReplaceAll(text, "\n", "<br>\n")
+
+	return markdownPlaceholder.ReplaceAllStringFunc(text, func(match string) string {
+		index, err := strconv.Atoi(match[1 : len(match)-1])
+		if err == nil && index < len(spans) {
+			return spans[index]
+		}
+		return match
+	})
+}
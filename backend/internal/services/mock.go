@@ -0,0 +1,248 @@
+package services
+
+import (
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// MockOptions configures latency and error injection of the mock server
+type MockOptions struct {
+	Group       string        // Only serve APIs of the group with this name, empty for all groups
+	Latency     time.Duration // Delay added before every response
+	ErrorRate   float64       // Probability between 0 and 1 of replying with ErrorStatus
+	ErrorStatus int           // Status code of injected errors, 500 if zero
+}
+
+// MockMaxLatency caps the latency a single request may ask for with a header
+var MockMaxLatency = 30 * time.Second
+
+// MockRoute is a documented HTTP API that the mock server can answer
+type MockRoute struct {
+	API          models.API
+	RequestTree  []models.Parameter
+	ResponseTree []models.Parameter
+	pattern      *regexp.Regexp
+	paramNames   []string
+	literalLen   int
+}
+
+// mockSegmentPattern matches "{{var}}", ":name" and "{name}" placeholders in an endpoint
+var mockSegmentPattern = regexp.MustCompile(`\{\{[^}]*\}\}|:([A-Za-z_][A-Za-z0-9_]*)|\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// LoadMockRoutes loads the HTTP APIs served by the mock server, most specific endpoints first
+func LoadMockRoutes(db *gorm.DB, groupName string) ([]MockRoute, error) {
+	query := db.Where("type = ?", "HTTP")
+	if groupName != "" {
+		var group models.Group
+		if err := db.Where("name = ?", groupName).First(&group).Error; err != nil {
+			return nil, err
+		}
+		query = query.Where("group_id = ?", group.ID)
+	}
+	return loadMockRoutes(query)
+}
+
+// MockRouteCache keeps the loaded routes of the mock server per group. The routes are
+// reloaded after the change history records a change, including changes made by other
+// processes sharing the database.
+type MockRouteCache struct {
+	mu      sync.Mutex
+	version uint                   // ID of the latest change record when the routes were loaded
+	routes  map[string][]MockRoute // routes by group name, "" for all groups
+}
+
+// NewMockRouteCache creates an empty route cache
+func NewMockRouteCache() *MockRouteCache {
+	return &MockRouteCache{routes: make(map[string][]MockRoute)}
+}
+
+// Routes returns the routes of a group as LoadMockRoutes does, loading them only when
+// documentation changed since they were last loaded
+func (c *MockRouteCache) Routes(db *gorm.DB, groupName string) ([]MockRoute, error) {
+	var version uint
+	if err := db.Model(&models.ChangeRecord{}).Select("COALESCE(MAX(id), 0)").Scan(&version).Error; err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if version != c.version {
+		c.routes = make(map[string][]MockRoute)
+		c.version = version
+	}
+	if routes, ok := c.routes[groupName]; ok {
+		return routes, nil
+	}
+
+	routes, err := LoadMockRoutes(db, groupName)
+	if err != nil {
+		return nil, err
+	}
+	c.routes[groupName] = routes
+	return routes, nil
+}
+
+// LoadGroupMockRoutes loads the mock routes of the HTTP APIs of one group
+func LoadGroupMockRoutes(db *gorm.DB, groupID uint) ([]MockRoute, error) {
+	return loadMockRoutes(db.Where("type = ? AND group_id = ?", "HTTP", groupID))
+}
+
+// loadMockRoutes compiles the APIs selected by query into routes, most specific endpoints first
+func loadMockRoutes(query *gorm.DB) ([]MockRoute, error) {
+	var apis []models.API
+	if err := query.Preload("Parameters", func(db *gorm.DB) *gorm.DB {
+		return db.Order("`order` ASC")
+	}).Order("id ASC").Find(&apis).Error; err != nil {
+		return nil, err
+	}
+
+	routes := make([]MockRoute, 0, len(apis))
+	for _, api := range apis {
+		requestParams := make([]models.Parameter, 0)
+		responseParams := make([]models.Parameter, 0)
+		for _, p := range api.Parameters {
+			if p.ParamType == "request" {
+				requestParams = append(requestParams, p)
+			} else if p.ParamType == "response" {
+				responseParams = append(responseParams, p)
+			}
+		}
+
+		route := compileMockRoute(api.Endpoint)
+		route.API = api
+		route.RequestTree = BuildParameterTree(requestParams)
+		route.ResponseTree = BuildParameterTree(responseParams)
+		routes = append(routes, route)
+	}
+
+	// Static paths win over templated ones, e.g. /users/me over /users/:id
+	sort.SliceStable(routes, func(i, j int) bool {
+		if len(routes[i].paramNames) != len(routes[j].paramNames) {
+			return len(routes[i].paramNames) < len(routes[j].paramNames)
+		}
+		return routes[i].literalLen > routes[j].literalLen
+	})
+
+	return routes, nil
+}
+
+// MatchMockRoute finds the route for a request method and path and returns its path parameters
+func MatchMockRoute(routes []MockRoute, method, path string) (*MockRoute, map[string]string) {
+	path = "/" + strings.Trim(path, "/")
+	for i := range routes {
+		route := &routes[i]
+		if route.API.Method != "" && !strings.EqualFold(route.API.Method, method) {
+			continue
+		}
+
+		match := route.pattern.FindStringSubmatch(path)
+		if match == nil {
+			continue
+		}
+
+		params := make(map[string]string, len(route.paramNames))
+		for j, name := range route.paramNames {
+			if name != "" {
+				params[name] = match[j+1]
+			}
+		}
+		return route, params
+	}
+	return nil, nil
+}
+
+// InjectMockError decides whether a response should be replaced by an injected error
+// and returns its status code
+func InjectMockError(options MockOptions) (int, bool) {
+	if options.ErrorRate <= 0 || rand.Float64() >= options.ErrorRate {
+		return 0, false
+	}
+	if options.ErrorStatus == 0 {
+		return 500, true
+	}
+	return options.ErrorStatus, true
+}
+
+// compileMockRoute turns an endpoint into a path pattern.
+// Query strings and a leading base URL (absolute or a "{{baseUrl}}" style template) are ignored.
+func compileMockRoute(endpoint string) MockRoute {
+	if i := strings.Index(endpoint, "?"); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+	if strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://") {
+		rest := endpoint[strings.Index(endpoint, "://")+3:]
+		if i := strings.Index(rest, "/"); i >= 0 {
+			endpoint = rest[i:]
+		} else {
+			endpoint = "/"
+		}
+	}
+	if strings.HasPrefix(endpoint, "{{") {
+		if i := strings.Index(endpoint, "}}"); i >= 0 {
+			endpoint = endpoint[i+2:]
+		}
+	}
+	endpoint = "/" + strings.Trim(endpoint, "/")
+
+	var pattern strings.Builder
+	var names []string
+	literalLen := 0
+	last := 0
+
+	pattern.WriteString("^")
+	for _, loc := range mockSegmentPattern.FindAllStringSubmatchIndex(endpoint, -1) {
+		literal := endpoint[last:loc[0]]
+		pattern.WriteString(regexp.QuoteMeta(literal))
+		literalLen += len(literal)
+
+		name := ""
+		if loc[2] >= 0 {
+			name = endpoint[loc[2]:loc[3]]
+		} else if loc[4] >= 0 {
+			name = endpoint[loc[4]:loc[5]]
+		}
+		names = append(names, name)
+		pattern.WriteString("([^/]+)")
+		last = loc[1]
+	}
+	literal := endpoint[last:]
+	pattern.WriteString(regexp.QuoteMeta(literal))
+	literalLen += len(literal)
+	pattern.WriteString("/?$")
+
+	return MockRoute{
+		pattern:    regexp.MustCompile(pattern.String()),
+		paramNames: names,
+		literalLen: literalLen,
+	}
+}
+
+// MockResponseBody builds the example response of a route. Top-level fields named like
+// a path parameter take the value from the request path, e.g. id for /users/:id.
+func MockResponseBody(route *MockRoute, pathParams map[string]string) *OrderedObject {
+	body := GenerateExampleJSON(route.ResponseTree)
+	for name, value := range pathParams {
+		example, ok := body.Get(name)
+		if !ok {
+			continue
+		}
+		switch example.(type) {
+		case string:
+			body.Set(name, value)
+		case int, float64:
+			if number, err := strconv.ParseFloat(value, 64); err == nil {
+				body.Set(name, number)
+			}
+		}
+	}
+	return body
+}
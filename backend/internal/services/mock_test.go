@@ -0,0 +1,45 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+)
+
+func TestMockRouteCache(t *testing.T) {
+	db := newTestDB(t)
+	group := models.Group{Name: "shop"}
+	if err := db.Create(&group).Error; err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	first := models.API{GroupID: group.ID, Name: "List orders", Endpoint: "/orders", Method: "GET", Type: "HTTP"}
+	if err := db.Create(&first).Error; err != nil {
+		t.Fatalf("create API: %v", err)
+	}
+
+	cache := NewMockRouteCache()
+	routes, err := cache.Routes(db, "shop")
+	if err != nil || len(routes) != 1 {
+		t.Fatalf("Routes = %d routes, %v; want 1", len(routes), err)
+	}
+
+	// Writes that are not in the change history yet are not seen
+	second := models.API{GroupID: group.ID, Name: "Get order", Endpoint: "/orders/{id}", Method: "GET", Type: "HTTP"}
+	if err := db.Create(&second).Error; err != nil {
+		t.Fatalf("create API: %v", err)
+	}
+	if routes, _ := cache.Routes(db, "shop"); len(routes) != 1 {
+		t.Errorf("Routes = %d routes before the change was recorded, want the cached 1", len(routes))
+	}
+
+	if err := RecordChange(db, ChangeEvent{Type: EventAPICreated, GroupID: group.ID, APIID: second.ID}); err != nil {
+		t.Fatalf("RecordChange: %v", err)
+	}
+	if routes, _ := cache.Routes(db, "shop"); len(routes) != 2 {
+		t.Errorf("Routes = %d routes after the change was recorded, want 2", len(routes))
+	}
+
+	if _, err := cache.Routes(db, "missing"); err == nil {
+		t.Error("Routes of an unknown group should fail")
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// OrderedObject is a decoded JSON object that remembers the order of its keys
+type OrderedObject struct {
+	Keys   []string
+	Values map[string]interface{}
+}
+
+// NewOrderedObject creates an empty ordered object
+func NewOrderedObject() *OrderedObject {
+	return &OrderedObject{Values: make(map[string]interface{})}
+}
+
+// Get returns the value of a key
+func (o *OrderedObject) Get(key string) (interface{}, bool) {
+	value, ok := o.Values[key]
+	return value, ok
+}
+
+// Set sets the value of a key, appending the key if it is new
+func (o *OrderedObject) Set(key string, value interface{}) {
+	if _, exists := o.Values[key]; !exists {
+		o.Keys = append(o.Keys, key)
+	}
+	o.Values[key] = value
+}
+
+// Delete removes a key
+func (o *OrderedObject) Delete(key string) {
+	if _, exists := o.Values[key]; !exists {
+		return
+	}
+	delete(o.Values, key)
+	for i, k := range o.Keys {
+		if k == key {
+			o.Keys = append(o.Keys[:i], o.Keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Len returns the number of keys
+func (o *OrderedObject) Len() int {
+	return len(o.Keys)
+}
+
+// MarshalJSON encodes the object with its keys in order
+func (o *OrderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.Keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		encodedValue, err := json.Marshal(o.Values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		buf.Write(encodedValue)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// DecodeOrderedJSON decodes a JSON document like json.Unmarshal into an interface{},
+// except that objects become *OrderedObject and numbers json.Number, so that key
+// order and the difference between integer and decimal literals are kept
+func DecodeOrderedJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	value, err := decodeOrderedValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+// decodeOrderedValue decodes the next value from a token stream
+func decodeOrderedValue(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+
+	switch delim {
+	case '{':
+		obj := NewOrderedObject()
+		for dec.More() {
+			keyToken, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyToken.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected object key %v", keyToken)
+			}
+
+			value, err := decodeOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(key, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+
+	case '[':
+		items := []interface{}{}
+		for dec.More() {
+			item, err := decodeOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+
+	return nil, fmt.Errorf("unexpected delimiter %v", delim)
+}
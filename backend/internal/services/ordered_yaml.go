@@ -0,0 +1,148 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// DecodeOrderedYAML decodes a YAML document like DecodeOrderedJSON: mappings become
+// *OrderedObject and numbers json.Number. JSON documents are decoded as JSON.
+func DecodeOrderedYAML(data []byte) (interface{}, error) {
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		return DecodeOrderedJSON(data)
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 {
+		return nil, errors.New("empty document")
+	}
+	return decodeYAMLNode(document.Content[0], 0)
+}
+
+// decodeYAMLNode converts a YAML node; depth guards against alias cycles
+func decodeYAMLNode(node *yaml.Node, depth int) (interface{}, error) {
+	if depth > 100 {
+		return nil, errors.New("document is nested too deeply")
+	}
+
+	switch node.Kind {
+	case yaml.AliasNode:
+		return decodeYAMLNode(node.Alias, depth+1)
+	case yaml.MappingNode:
+		obj := NewOrderedObject()
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Tag == "!!merge" {
+				merged, err := decodeYAMLNode(value, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				if mergedObj, ok := merged.(*OrderedObject); ok {
+					for _, name := range mergedObj.Keys {
+						if _, exists := obj.Get(name); !exists {
+							obj.Set(name, mergedObj.Values[name])
+						}
+					}
+				}
+				continue
+			}
+			decoded, err := decodeYAMLNode(value, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			obj.Set(key.Value, decoded)
+		}
+		return obj, nil
+	case yaml.SequenceNode:
+		list := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			decoded, err := decodeYAMLNode(item, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, decoded)
+		}
+		return list, nil
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!null":
+			return nil, nil
+		case "!!bool":
+			var value bool
+			err := node.Decode(&value)
+			return value, err
+		case "!!int", "!!float":
+			var value interface{}
+			if err := node.Decode(&value); err != nil {
+				return nil, err
+			}
+			return json.Number(fmt.Sprint(value)), nil
+		}
+		return node.Value, nil
+	}
+	return nil, fmt.Errorf("unsupported YAML node at line %d", node.Line)
+}
+
+// EncodeOrderedYAML encodes a value as YAML, keeping the key order of *OrderedObject
+func EncodeOrderedYAML(value interface{}) ([]byte, error) {
+	node, err := encodeYAMLNode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var out strings.Builder
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return []byte(out.String()), nil
+}
+
+// encodeYAMLNode converts a value into a YAML node
+func encodeYAMLNode(value interface{}) (*yaml.Node, error) {
+	switch v := value.(type) {
+	case *OrderedObject:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range v.Keys {
+			child, err := encodeYAMLNode(v.Values[key])
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+		}
+		return node, nil
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			child, err := encodeYAMLNode(item)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(string(v), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: string(v)}, nil
+	}
+
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
@@ -0,0 +1,434 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Parameter operations, named after their JSON Patch counterparts
+const (
+	ParameterOpAdd     = "add"
+	ParameterOpReplace = "replace"
+	ParameterOpMove    = "move"
+	ParameterOpRemove  = "remove"
+)
+
+// Parameter operation issue codes
+const (
+	IssueInvalidOperation  = "invalid_operation"
+	IssueParameterNotFound = "parameter_not_found"
+)
+
+// ParameterFields holds the fields changed by a replace operation; nil fields are kept
+type ParameterFields struct {
+	Name        *string `json:"name"`
+	Type        *string `json:"type"`
+	Description *string `json:"description"`
+	Required    *bool   `json:"required"`
+	Nullable    *bool   `json:"nullable"`
+}
+
+// ParameterOperation is a JSON Patch style edit of one parameter. Existing parameters are
+// addressed by ID or by a path of names such as /user/tags; a parentId of 0 is the root.
+type ParameterOperation struct {
+	Op       string          `json:"op"`                 // add, replace, move or remove
+	ID       uint            `json:"id,omitempty"`       // Parameter to replace, move or remove
+	Path     string          `json:"path,omitempty"`     // Parameter to replace or remove, or where to add or move it to
+	From     string          `json:"from,omitempty"`     // Parameter to move, instead of id
+	ParentID *uint           `json:"parentId,omitempty"` // Parent to add or move to, instead of path
+	Position *int            `json:"position,omitempty"` // Index among the new siblings, last if omitted
+	Value    json.RawMessage `json:"value,omitempty"`    // add: the parameter and its children, replace: the changed fields
+}
+
+// ParameterEditError reports operations that could not be applied or that would leave an invalid tree
+type ParameterEditError struct {
+	Issues []ValidationIssue
+}
+
+func (e *ParameterEditError) Error() string {
+	return "invalid parameter operations"
+}
+
+// ApplyParameterOperations applies operations in order to a copy of a tree. Either all
+// operations apply and the resulting tree is valid, or a *ParameterEditError is returned.
+// It also returns, per operation, the path of the parameter it affected (empty for remove).
+func ApplyParameterOperations(nodes []ParameterNode, ops []ParameterOperation) ([]ParameterNode, []string, error) {
+	root := &ParameterNode{Type: "object", Children: cloneParameterNodes(nodes)}
+	paths := make([]string, len(ops))
+
+	for i, op := range ops {
+		path, issue := applyParameterOperation(root, op)
+		if issue != nil {
+			issue.Path = fmt.Sprintf("operations[%d]", i)
+			return nil, nil, &ParameterEditError{Issues: []ValidationIssue{*issue}}
+		}
+		paths[i] = path
+	}
+
+	if issues := ValidateParameterNodes(root.Children); len(issues) > 0 {
+		return nil, nil, &ParameterEditError{Issues: issues}
+	}
+	return root.Children, paths, nil
+}
+
+// FindParameterNode returns the node at a path of names such as /user/tags, or nil
+func FindParameterNode(nodes []ParameterNode, path string) *ParameterNode {
+	root := &ParameterNode{Children: nodes}
+	parent, index, ok := locateByPath(root, path)
+	if !ok {
+		return nil
+	}
+	return &parent.Children[index]
+}
+
+// SyncParameterTree stores a tree as the request or response parameters of an API.
+// Nodes carrying the ID of one of those parameters update it in place, so IDs referenced
+// by comments stay valid; other nodes are inserted with one batch per depth level and
+// parameters missing from the tree are deleted. Nodes receive the IDs of their rows.
+// Callers should run it inside a transaction. It returns the number of parameters in the tree.
+func SyncParameterTree(db *gorm.DB, apiID uint, paramType string, nodes []ParameterNode) (int, error) {
+	var existing []models.Parameter
+	if err := db.Select("id").Where("api_id = ? AND param_type = ?", apiID, paramType).Find(&existing).Error; err != nil {
+		return 0, err
+	}
+	known := make(map[uint]bool, len(existing))
+	for _, param := range existing {
+		known[param.ID] = true
+	}
+
+	// levels[d] holds the nodes at depth d with their parents; orders are assigned in pre-order
+	type syncEntry struct {
+		node   *ParameterNode
+		parent *ParameterNode
+		order  int
+	}
+	var levels [][]syncEntry
+	order := 0
+
+	var collect func(nodes []ParameterNode, depth int, parent *ParameterNode)
+	collect = func(nodes []ParameterNode, depth int, parent *ParameterNode) {
+		if len(nodes) == 0 {
+			return
+		}
+		if len(levels) == depth {
+			levels = append(levels, nil)
+		}
+		for i := range nodes {
+			levels[depth] = append(levels[depth], syncEntry{node: &nodes[i], parent: parent, order: order})
+			order++
+			collect(nodes[i].Children, depth+1, &nodes[i])
+		}
+	}
+	collect(nodes, 0, nil)
+
+	kept := make(map[uint]bool, len(existing))
+	for _, level := range levels {
+		var inserted []models.Parameter
+		var insertedNodes []*ParameterNode
+
+		for _, entry := range level {
+			node := entry.node
+			description := node.Description
+			if description != nil && *description == "" {
+				description = nil
+			}
+
+			param := models.Parameter{
+				APIID:       apiID,
+				Name:        node.Name,
+				Type:        node.Type,
+				Description: description,
+				Required:    node.Required,
+				Nullable:    node.Nullable,
+				ParamType:   paramType,
+				Order:       entry.order,
+			}
+			if entry.parent != nil {
+				parentID := entry.parent.ID
+				param.ParentID = &parentID
+			}
+
+			if known[node.ID] && !kept[node.ID] {
+				param.ID = node.ID
+				kept[node.ID] = true
+				if err := db.Model(&param).Select("ParentID", "Name", "Type", "Description", "Required", "Nullable", "Order").Updates(&param).Error; err != nil {
+					return 0, err
+				}
+				continue
+			}
+			inserted = append(inserted, param)
+			insertedNodes = append(insertedNodes, node)
+		}
+
+		if len(inserted) == 0 {
+			continue
+		}
+		if err := db.CreateInBatches(inserted, parameterBatchSize).Error; err != nil {
+			return 0, err
+		}
+		for i, param := range inserted {
+			insertedNodes[i].ID = param.ID
+			kept[param.ID] = true
+		}
+	}
+
+	removed := make([]uint, 0)
+	for _, param := range existing {
+		if !kept[param.ID] {
+			removed = append(removed, param.ID)
+		}
+	}
+	if len(removed) > 0 {
+		if err := db.Delete(&models.Parameter{}, removed).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	return order, nil
+}
+
+// applyParameterOperation applies one operation below root and returns the affected path
+func applyParameterOperation(root *ParameterNode, op ParameterOperation) (string, *ValidationIssue) {
+	switch op.Op {
+	case ParameterOpAdd:
+		var node ParameterNode
+		if err := json.Unmarshal(op.Value, &node); err != nil || len(op.Value) == 0 {
+			return "", invalidOperation("add needs the parameter as value")
+		}
+
+		parent, parentPath, name, issue := locateDestination(root, op)
+		if issue != nil {
+			return "", issue
+		}
+		if name != "" {
+			node.Name = name
+		}
+		node.ID = 0
+		clearParameterIDs(node.Children)
+
+		insertParameterNode(parent, node, op.Position)
+		return parentPath + "/" + escapePathSegment(node.Name), nil
+
+	case ParameterOpReplace:
+		var fields ParameterFields
+		if err := json.Unmarshal(op.Value, &fields); err != nil || len(op.Value) == 0 {
+			return "", invalidOperation("replace needs the changed fields as value")
+		}
+
+		parent, index, path, issue := locateTarget(root, op.ID, op.Path)
+		if issue != nil {
+			return "", issue
+		}
+
+		node := &parent.Children[index]
+		if fields.Name != nil {
+			node.Name = *fields.Name
+			path = path[:strings.LastIndex(path, "/")+1] + escapePathSegment(node.Name)
+		}
+		if fields.Type != nil {
+			node.Type = *fields.Type
+		}
+		if fields.Description != nil {
+			node.Description = fields.Description
+		}
+		if fields.Required != nil {
+			node.Required = *fields.Required
+		}
+		if fields.Nullable != nil {
+			node.Nullable = *fields.Nullable
+		}
+		return path, nil
+
+	case ParameterOpMove:
+		parent, index, _, issue := locateTarget(root, op.ID, op.From)
+		if issue != nil {
+			return "", issue
+		}
+		if op.ParentID == nil && op.Path == "" {
+			return "", invalidOperation("move needs a parentId or path as destination")
+		}
+
+		node := parent.Children[index]
+		parent.Children = append(parent.Children[:index:index], parent.Children[index+1:]...)
+
+		// The destination is resolved without the moved parameter, so it cannot be its own descendant
+		destination, parentPath, name, issue := locateDestination(root, op)
+		if issue != nil {
+			return "", issue
+		}
+		if name != "" {
+			node.Name = name
+		}
+
+		insertParameterNode(destination, node, op.Position)
+		return parentPath + "/" + escapePathSegment(node.Name), nil
+
+	case ParameterOpRemove:
+		parent, index, _, issue := locateTarget(root, op.ID, op.Path)
+		if issue != nil {
+			return "", issue
+		}
+		parent.Children = append(parent.Children[:index:index], parent.Children[index+1:]...)
+		return "", nil
+
+	default:
+		return "", invalidOperation(fmt.Sprintf("unknown op %q, expected add, replace, move or remove", op.Op))
+	}
+}
+
+// locateTarget finds an existing parameter by ID or path and returns its parent, index and path
+func locateTarget(root *ParameterNode, id uint, path string) (*ParameterNode, int, string, *ValidationIssue) {
+	if id != 0 {
+		parent, index, nodePath, ok := locateByID(root, id, "")
+		if !ok {
+			return nil, 0, "", notFound(fmt.Sprintf("parameter %d not found", id))
+		}
+		return parent, index, nodePath, nil
+	}
+	if path == "" {
+		return nil, 0, "", invalidOperation("an id or path is required")
+	}
+
+	parent, index, ok := locateByPath(root, path)
+	if !ok {
+		return nil, 0, "", notFound(fmt.Sprintf("parameter %s not found", path))
+	}
+	return parent, index, path, nil
+}
+
+// locateDestination finds the parent a parameter is added or moved to. A path names the
+// parameter itself, so its last segment is returned as the new name.
+func locateDestination(root *ParameterNode, op ParameterOperation) (*ParameterNode, string, string, *ValidationIssue) {
+	if op.ParentID != nil {
+		if *op.ParentID == 0 {
+			return root, "", "", nil
+		}
+		parent, index, path, ok := locateByID(root, *op.ParentID, "")
+		if !ok {
+			return nil, "", "", notFound(fmt.Sprintf("parent parameter %d not found", *op.ParentID))
+		}
+		return &parent.Children[index], path, "", nil
+	}
+	if op.Path == "" {
+		return root, "", "", nil
+	}
+
+	segments := splitParameterPath(op.Path)
+	if len(segments) == 0 {
+		return nil, "", "", invalidOperation("path must name the parameter, e.g. /user/email")
+	}
+	name := segments[len(segments)-1]
+	parentPath := op.Path[:strings.LastIndex(op.Path, "/")]
+	if parentPath == "" {
+		return root, "", name, nil
+	}
+
+	parent, index, ok := locateByPath(root, parentPath)
+	if !ok {
+		return nil, "", "", notFound(fmt.Sprintf("parent parameter %s not found", parentPath))
+	}
+	return &parent.Children[index], parentPath, name, nil
+}
+
+// locateByID searches a tree depth-first for a parameter ID
+func locateByID(parent *ParameterNode, id uint, prefix string) (*ParameterNode, int, string, bool) {
+	for i := range parent.Children {
+		path := prefix + "/" + escapePathSegment(parent.Children[i].Name)
+		if parent.Children[i].ID == id {
+			return parent, i, path, true
+		}
+		if found, index, foundPath, ok := locateByID(&parent.Children[i], id, path); ok {
+			return found, index, foundPath, true
+		}
+	}
+	return nil, 0, "", false
+}
+
+// locateByPath follows a path of names from the root
+func locateByPath(root *ParameterNode, path string) (*ParameterNode, int, bool) {
+	segments := splitParameterPath(path)
+	if len(segments) == 0 {
+		return nil, 0, false
+	}
+
+	parent := root
+	for depth, segment := range segments {
+		index := -1
+		for i := range parent.Children {
+			if parent.Children[i].Name == segment {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, 0, false
+		}
+		if depth == len(segments)-1 {
+			return parent, index, true
+		}
+		parent = &parent.Children[index]
+	}
+	return nil, 0, false
+}
+
+// splitParameterPath splits a JSON Pointer style path into parameter names
+func splitParameterPath(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return nil
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+	}
+	return segments
+}
+
+// escapePathSegment escapes a parameter name for use in a path
+func escapePathSegment(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+// insertParameterNode inserts a node among the children of parent, last if position is nil
+func insertParameterNode(parent *ParameterNode, node ParameterNode, position *int) {
+	index := len(parent.Children)
+	if position != nil && *position >= 0 && *position < index {
+		index = *position
+	}
+	parent.Children = append(parent.Children, ParameterNode{})
+	copy(parent.Children[index+1:], parent.Children[index:])
+	parent.Children[index] = node
+}
+
+// cloneParameterNodes deep-copies a tree so that edits do not affect the original
+func cloneParameterNodes(nodes []ParameterNode) []ParameterNode {
+	if nodes == nil {
+		return nil
+	}
+	clone := make([]ParameterNode, len(nodes))
+	for i, node := range nodes {
+		clone[i] = node
+		clone[i].Children = cloneParameterNodes(node.Children)
+	}
+	return clone
+}
+
+// clearParameterIDs removes IDs from added nodes so that they are inserted as new parameters
+func clearParameterIDs(nodes []ParameterNode) {
+	for i := range nodes {
+		nodes[i].ID = 0
+		clearParameterIDs(nodes[i].Children)
+	}
+}
+
+func invalidOperation(message string) *ValidationIssue {
+	return &ValidationIssue{Code: IssueInvalidOperation, Message: message}
+}
+
+func notFound(message string) *ValidationIssue {
+	return &ValidationIssue{Code: IssueParameterNotFound, Message: message}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestSyncParameterTree(t *testing.T) {
+	db := newTestDB(t)
+	api := models.API{GroupID: 1, Name: "Create order", Endpoint: "/orders", Method: "POST", Type: "HTTP"}
+	if err := db.Create(&api).Error; err != nil {
+		t.Fatalf("create API: %v", err)
+	}
+
+	inserts := 0
+	db.Callback().Create().After("gorm:create").Register("test:count_inserts", func(tx *gorm.DB) {
+		if tx.Statement.Table == "parameters" {
+			inserts++
+		}
+	})
+
+	nodes := []ParameterNode{
+		{Name: "customer", Type: "object", Children: []ParameterNode{
+			{Name: "id", Type: "integer", Required: true},
+			{Name: "name", Type: "string"},
+		}},
+		{Name: "items", Type: "array", Children: []ParameterNode{
+			{Name: "sku", Type: "string", Required: true},
+		}},
+		{Name: "note", Type: "string"},
+	}
+
+	count, err := SyncParameterTree(db, api.ID, "request", nodes)
+	if err != nil {
+		t.Fatalf("SyncParameterTree: %v", err)
+	}
+	if count != 6 {
+		t.Errorf("count = %d, want 6", count)
+	}
+	if inserts != 2 {
+		t.Errorf("insert statements = %d, want one per depth level (2)", inserts)
+	}
+
+	stored := loadTestTree(t, db, api.ID)
+	if got := treeShape(stored); got != "customer(id,name),items(sku),note" {
+		t.Fatalf("stored tree = %s", got)
+	}
+	for i, node := range nodes {
+		if node.ID != stored[i].ID {
+			t.Errorf("node %s ID = %d, want %d", node.Name, node.ID, stored[i].ID)
+		}
+	}
+	customerID, skuID := nodes[0].ID, nodes[1].Children[0].ID
+
+	// Nodes with IDs update their rows in place; new nodes are inserted, missing ones deleted
+	inserts = 0
+	nodes = []ParameterNode{
+		{ID: nodes[1].ID, Name: "lines", Type: "array", Children: []ParameterNode{
+			{ID: skuID, Name: "sku", Type: "string", Required: true},
+			{Name: "quantity", Type: "integer"},
+		}},
+		{ID: customerID, Name: "customer", Type: "object"},
+	}
+	if _, err := SyncParameterTree(db, api.ID, "request", nodes); err != nil {
+		t.Fatalf("SyncParameterTree: %v", err)
+	}
+	if inserts != 1 {
+		t.Errorf("insert statements = %d, want 1", inserts)
+	}
+
+	stored = loadTestTree(t, db, api.ID)
+	if got := treeShape(stored); got != "lines(sku,quantity),customer" {
+		t.Fatalf("stored tree = %s", got)
+	}
+	if stored[1].ID != customerID || stored[0].Children[0].ID != skuID {
+		t.Errorf("IDs changed: customer %d (want %d), sku %d (want %d)",
+			stored[1].ID, customerID, stored[0].Children[0].ID, skuID)
+	}
+
+	var total int64
+	db.Model(&models.Parameter{}).Where("api_id = ?", api.ID).Count(&total)
+	if total != 4 {
+		t.Errorf("stored %d parameters, want 4", total)
+	}
+}
+
+// loadTestTree loads the request parameter tree of an API
+func loadTestTree(t *testing.T, db *gorm.DB, apiID uint) []models.Parameter {
+	t.Helper()
+	var params []models.Parameter
+	if err := db.Where("api_id = ? AND param_type = ?", apiID, "request").Order("`order` ASC").Find(&params).Error; err != nil {
+		t.Fatalf("load parameters: %v", err)
+	}
+	return BuildParameterTree(params)
+}
+
+// treeShape renders the names of a tree, e.g. user(id,name),tags
+func treeShape(tree []models.Parameter) string {
+	shape := ""
+	for i, param := range tree {
+		if i > 0 {
+			shape += ","
+		}
+		shape += param.Name
+		if len(param.Children) > 0 {
+			shape += "(" + treeShape(param.Children) + ")"
+		}
+	}
+	return shape
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// parameterTypes lists the types a parameter may have; unions join them with "|"
+var parameterTypes = []string{"string", "integer", "number", "boolean", "array", "object"}
+
+// parameterBatchSize is the number of parameters inserted per statement
+const parameterBatchSize = 100
+
+// Parameter tree issue codes
+const (
+	IssueEmptyName          = "empty_name"
+	IssueDuplicateName      = "duplicate_name"
+	IssueUnknownType        = "unknown_type"
+	IssueUnexpectedChildren = "unexpected_children"
+)
+
+// ParameterNode is a parameter tree node that is not bound to stored rows.
+// It is used for drafts, whole-tree replacement and incremental edits.
+type ParameterNode struct {
+	ID          uint            `json:"id,omitempty"` // ID of the stored parameter, 0 for new nodes
+	Name        string          `json:"name"`
+	Type        string          `json:"type"`
+	Description *string         `json:"description,omitempty"`
+	Required    bool            `json:"required"`
+	Nullable    bool            `json:"nullable,omitempty"`
+	Children    []ParameterNode `json:"children,omitempty"`
+}
+
+// NodesFromParameters converts a built parameter tree into parameter nodes
+func NodesFromParameters(tree []models.Parameter) []ParameterNode {
+	nodes := make([]ParameterNode, 0, len(tree))
+	for _, param := range tree {
+		nodes = append(nodes, ParameterNode{
+			ID:          param.ID,
+			Name:        param.Name,
+			Type:        param.Type,
+			Description: param.Description,
+			Required:    param.Required,
+			Nullable:    param.Nullable,
+			Children:    NodesFromParameters(param.Children),
+		})
+	}
+	return nodes
+}
+
+// ParametersFromNodes converts parameter nodes into an unsaved parameter tree.
+// Orders are assigned in pre-order, matching how trees are stored.
+func ParametersFromNodes(nodes []ParameterNode, apiID uint, paramType string) []models.Parameter {
+	order := 0
+
+	var convert func(nodes []ParameterNode) []models.Parameter
+	convert = func(nodes []ParameterNode) []models.Parameter {
+		params := make([]models.Parameter, 0, len(nodes))
+		for _, node := range nodes {
+			param := models.Parameter{
+				ID:          node.ID,
+				APIID:       apiID,
+				Name:        node.Name,
+				Type:        node.Type,
+				Description: node.Description,
+				Required:    node.Required,
+				Nullable:    node.Nullable,
+				ParamType:   paramType,
+				Order:       order,
+			}
+			order++
+			param.Children = convert(node.Children)
+			params = append(params, param)
+		}
+		return params
+	}
+
+	return convert(nodes)
+}
+
+// ReplaceParameterTree replaces the request or response parameters of an API with the
+// given tree. Callers should run it inside a transaction and validate the tree with
+// ValidateParameterNodes first. Existing parameters at the same path as a node keep their
+// IDs; the rest is stored by SyncParameterTree. It returns the number of parameters in the tree.
+func ReplaceParameterTree(db *gorm.DB, apiID uint, paramType string, nodes []ParameterNode) (int, error) {
+	var existing []models.Parameter
+	if err := db.Where("api_id = ? AND param_type = ?", apiID, paramType).Order("`order` ASC").Find(&existing).Error; err != nil {
+		return 0, err
+	}
+	MatchParameterIDs(nodes, BuildParameterTree(existing))
+	return SyncParameterTree(db, apiID, paramType, nodes)
+}
+
+// ValidateParameterNodes checks a parameter tree before it is stored: every parameter needs
+// a name that is unique among its siblings and a known type, and only objects and arrays may
+// have children. Paths address nodes by position, e.g. parameters[1].children[0].
+func ValidateParameterNodes(nodes []ParameterNode) []ValidationIssue {
+	issues := []ValidationIssue{}
+
+	var walk func(nodes []ParameterNode, path string)
+	walk = func(nodes []ParameterNode, path string) {
+		seen := make(map[string]bool, len(nodes))
+		for i, node := range nodes {
+			nodePath := fmt.Sprintf("%s[%d]", path, i)
+			name := strings.TrimSpace(node.Name)
+
+			if name == "" {
+				issues = append(issues, ValidationIssue{
+					Path:    nodePath,
+					Code:    IssueEmptyName,
+					Message: "parameter name is required",
+				})
+			} else if seen[node.Name] {
+				issues = append(issues, ValidationIssue{
+					Path:    nodePath,
+					Code:    IssueDuplicateName,
+					Message: fmt.Sprintf("parameter %q is defined more than once at this level", node.Name),
+					Actual:  node.Name,
+				})
+			}
+			seen[node.Name] = true
+
+			if !IsParameterType(node.Type) {
+				issues = append(issues, ValidationIssue{
+					Path:     nodePath,
+					Code:     IssueUnknownType,
+					Message:  fmt.Sprintf("parameter %q has unknown type %q", node.Name, node.Type),
+					Expected: strings.Join(parameterTypes, ", "),
+					Actual:   node.Type,
+				})
+			} else if len(node.Children) > 0 && !hasParamType(node.Type, "object") && !hasParamType(node.Type, "array") {
+				issues = append(issues, ValidationIssue{
+					Path:     nodePath,
+					Code:     IssueUnexpectedChildren,
+					Message:  fmt.Sprintf("parameter %q of type %s cannot have children", node.Name, node.Type),
+					Expected: "object or array",
+					Actual:   node.Type,
+				})
+			}
+
+			walk(node.Children, nodePath+".children")
+		}
+	}
+	walk(nodes, "parameters")
+
+	return issues
+}
+
+// IsParameterType reports whether a type is a parameter type or a union of parameter types
+func IsParameterType(paramType string) bool {
+	if paramType == "" {
+		return false
+	}
+	for _, member := range strings.Split(paramType, "|") {
+		known := false
+		for _, name := range parameterTypes {
+			if member == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return false
+		}
+	}
+	return true
+}
+
+// CarryDocumentation copies IDs and descriptions from documented nodes to nodes at the
+// same path that have none, e.g. after inferring a tree from examples
+func CarryDocumentation(nodes, documented []ParameterNode) {
+	byName := make(map[string]ParameterNode, len(documented))
+	for _, node := range documented {
+		byName[node.Name] = node
+	}
+	for i := range nodes {
+		match, ok := byName[nodes[i].Name]
+		if !ok {
+			continue
+		}
+		if nodes[i].ID == 0 {
+			nodes[i].ID = match.ID
+		}
+		if nodes[i].Description == nil {
+			nodes[i].Description = match.Description
+		}
+		CarryDocumentation(nodes[i].Children, match.Children)
+	}
+}
+
+// CarryRequiredFlags copies the required flags of documented nodes to the nodes at the
+// same path, e.g. when a single example cannot tell optional fields from required ones
+func CarryRequiredFlags(nodes, documented []ParameterNode) {
+	byName := make(map[string]ParameterNode, len(documented))
+	for _, node := range documented {
+		byName[node.Name] = node
+	}
+	for i := range nodes {
+		if match, ok := byName[nodes[i].Name]; ok {
+			nodes[i].Required = match.Required
+			CarryRequiredFlags(nodes[i].Children, match.Children)
+		}
+	}
+}
+
+// MatchParameterIDs gives nodes without an ID the ID of the stored parameter at the same
+// path, so SyncParameterTree updates those parameters in place instead of renumbering them
+func MatchParameterIDs(nodes []ParameterNode, stored []models.Parameter) {
+	byName := make(map[string]*models.Parameter, len(stored))
+	for i := range stored {
+		byName[stored[i].Name] = &stored[i]
+	}
+	for i := range nodes {
+		match, ok := byName[nodes[i].Name]
+		if !ok {
+			continue
+		}
+		if nodes[i].ID == 0 {
+			nodes[i].ID = match.ID
+		}
+		MatchParameterIDs(nodes[i].Children, match.Children)
+	}
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// RecorderAuthor is the author of drafts proposed by the recording proxy
+const RecorderAuthor = "knot record"
+
+// recordedIDPattern matches path segments that identify a resource: numbers, UUIDs and long hex strings
+var recordedIDPattern = regexp.MustCompile(`^(\d+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{16,})$`)
+
+// Recorder groups observed HTTP calls by method and path template and proposes
+// the inferred documentation as drafts, so nothing is published without review.
+type Recorder struct {
+	db        *gorm.DB
+	group     models.Group
+	mu        sync.Mutex
+	endpoints map[string]*recordedEndpoint
+}
+
+// recordedEndpoint holds the samples merged for one method and path template
+type recordedEndpoint struct {
+	calls    int
+	request  *SchemaInferrer
+	response *SchemaInferrer
+}
+
+// RecordedCall summarizes how an observed call was recorded
+type RecordedCall struct {
+	Method   string
+	Endpoint string
+	APIID    *uint // Documented API the call matched, nil for a newly discovered API
+	DraftID  uint
+	Calls    int
+}
+
+// NewRecorder creates a recorder proposing drafts in the group with the given name,
+// creating the group if it does not exist
+func NewRecorder(db *gorm.DB, groupName string) (*Recorder, error) {
+	var group models.Group
+	err := db.Where("name = ?", groupName).First(&group).Error
+	if err == gorm.ErrRecordNotFound {
+		var maxOrder int
+		db.Model(&models.Group{}).Select("COALESCE(MAX(`order`), 0)").Scan(&maxOrder)
+		group = models.Group{Name: groupName, Order: maxOrder + 1}
+		err = db.Create(&group).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recorder{
+		db:        db,
+		group:     group,
+		endpoints: make(map[string]*recordedEndpoint),
+	}, nil
+}
+
+// Group returns the group receiving the proposed drafts
+func (r *Recorder) Group() models.Group {
+	return r.group
+}
+
+// Observe merges an observed call into the inferred documentation of its endpoint and
+// updates the proposed draft. Calls matching a documented API of the group propose an
+// update of that API, other calls propose a new API. Bodies that are not JSON objects
+// are ignored; for calls without a request body the query string is used instead.
+func (r *Recorder) Observe(method, rawPath string, requestBody, responseBody []byte) (RecordedCall, error) {
+	method = strings.ToUpper(method)
+	path, query := rawPath, ""
+	if i := strings.Index(rawPath, "?"); i >= 0 {
+		path, query = rawPath[:i], rawPath[i+1:]
+	}
+
+	routes, err := LoadGroupMockRoutes(r.db, r.group.ID)
+	if err != nil {
+		return RecordedCall{}, err
+	}
+
+	var api *models.API
+	endpoint := RecordedPathTemplate(path)
+	if route, _ := MatchMockRoute(routes, method, path); route != nil {
+		api = &route.API
+		endpoint = route.API.Endpoint
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := method + " " + endpoint
+	recorded := r.endpoints[key]
+	if recorded == nil {
+		recorded = &recordedEndpoint{request: NewSchemaInferrer(), response: NewSchemaInferrer()}
+		r.endpoints[key] = recorded
+	}
+	recorded.calls++
+
+	if sample, ok := decodeRecordedObject(requestBody); ok {
+		recorded.request.Add(sample)
+	} else if len(requestBody) == 0 && query != "" {
+		if sample, ok := queryRecordedObject(query); ok {
+			recorded.request.Add(sample)
+		}
+	}
+	if sample, ok := decodeRecordedObject(responseBody); ok {
+		recorded.response.Add(sample)
+	}
+
+	var draft models.APIDraft
+	if api != nil {
+		draft, err = r.proposeUpdate(*api, recorded)
+	} else {
+		draft, err = r.proposeNew(method, endpoint, recorded)
+	}
+	if err != nil {
+		return RecordedCall{}, err
+	}
+
+	return RecordedCall{
+		Method:   method,
+		Endpoint: endpoint,
+		APIID:    draft.APIID,
+		DraftID:  draft.ID,
+		Calls:    recorded.calls,
+	}, nil
+}
+
+// proposeUpdate stores the inferred trees in the recorder's own open draft of a documented
+// API, separate from the draft people edit. Trees without samples keep the documented
+// version, and descriptions from the draft or the documented fields are carried over.
+func (r *Recorder) proposeUpdate(api models.API, recorded *recordedEndpoint) (models.APIDraft, error) {
+	var draft models.APIDraft
+	err := r.db.Where("api_id = ? AND status = ? AND author = ?", api.ID, models.DraftStatusOpen, RecorderAuthor).
+		Order("id DESC").First(&draft).Error
+	if err == gorm.ErrRecordNotFound {
+		draft, err = newDraft(r.db, api, RecorderAuthor)
+	}
+	if err != nil {
+		return draft, err
+	}
+
+	published, err := LoadPublishedVersion(r.db, api)
+	if err != nil {
+		return draft, err
+	}
+
+	for _, paramType := range []string{"request", "response"} {
+		inferrer, documented := recorded.request, published.RequestParameters
+		if paramType == "response" {
+			inferrer, documented = recorded.response, published.ResponseParameters
+		}
+		if inferrer.Samples() == 0 {
+			continue
+		}
+
+		current, err := DraftParameterNodes(draft, paramType)
+		if err != nil {
+			return draft, err
+		}
+		nodes := inferrer.Nodes()
+		CarryDocumentation(nodes, current)
+		CarryDocumentation(nodes, NodesFromParameters(documented))
+		if err := SetDraftParameterNodes(&draft, paramType, nodes); err != nil {
+			return draft, err
+		}
+	}
+
+	draft.Summary = recordedSummary(recorded.calls)
+	return draft, r.db.Save(&draft).Error
+}
+
+// proposeNew stores the inferred trees in the open draft proposing a new API
+func (r *Recorder) proposeNew(method, endpoint string, recorded *recordedEndpoint) (models.APIDraft, error) {
+	var draft models.APIDraft
+	err := r.db.Where("api_id IS NULL AND group_id = ? AND status = ? AND method = ? AND endpoint = ?",
+		r.group.ID, models.DraftStatusOpen, method, endpoint).Order("id DESC").First(&draft).Error
+	if err == gorm.ErrRecordNotFound {
+		groupID := r.group.ID
+		draft = models.APIDraft{
+			GroupID:  &groupID,
+			Status:   models.DraftStatusOpen,
+			Author:   RecorderAuthor,
+			Name:     method + " " + endpoint,
+			Endpoint: endpoint,
+			Method:   method,
+			Type:     "HTTP",
+		}
+	} else if err != nil {
+		return draft, err
+	}
+
+	if err := SetDraftParameterNodes(&draft, "request", recorded.request.Nodes()); err != nil {
+		return draft, err
+	}
+	if err := SetDraftParameterNodes(&draft, "response", recorded.response.Nodes()); err != nil {
+		return draft, err
+	}
+
+	draft.Summary = recordedSummary(recorded.calls)
+	return draft, r.db.Save(&draft).Error
+}
+
+// RecordedPathTemplate replaces path segments that look like identifiers with
+// parameters, e.g. /users/42/orders/7 becomes /users/:id/orders/:id2
+func RecordedPathTemplate(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	params := 0
+	for i, segment := range segments {
+		if !recordedIDPattern.MatchString(segment) {
+			continue
+		}
+		params++
+		if params == 1 {
+			segments[i] = ":id"
+		} else {
+			segments[i] = fmt.Sprintf(":id%d", params)
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// decodeRecordedObject decodes a body that holds a JSON object, keeping its key order
+func decodeRecordedObject(body []byte) (*OrderedObject, bool) {
+	if len(body) == 0 {
+		return nil, false
+	}
+	value, err := DecodeOrderedJSON(body)
+	if err != nil {
+		return nil, false
+	}
+	obj, ok := value.(*OrderedObject)
+	return obj, ok
+}
+
+// queryRecordedObject turns a query string into a sample object of string fields
+func queryRecordedObject(query string) (map[string]interface{}, bool) {
+	values, err := url.ParseQuery(query)
+	if err != nil || len(values) == 0 {
+		return nil, false
+	}
+	obj := make(map[string]interface{}, len(values))
+	for key, list := range values {
+		if len(list) > 1 {
+			items := make([]interface{}, len(list))
+			for i, value := range list {
+				items[i] = value
+			}
+			obj[key] = items
+		} else {
+			obj[key] = list[0]
+		}
+	}
+	return obj, true
+}
+
+// recordedSummary describes how many calls a recorded draft is based on
+func recordedSummary(calls int) *string {
+	summary := fmt.Sprintf("Recorded from %d observed calls", calls)
+	if calls == 1 {
+		summary = "Recorded from 1 observed call"
+	}
+	return &summary
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ProjAnvil/knot/backend/internal/config"
+)
+
+// SecretKeyEnv names the environment variable that can provide the base64-encoded
+// 32-byte key used to encrypt environment secrets instead of the key file
+const SecretKeyEnv = "KNOT_SECRET_KEY"
+
+// encryptedPrefix marks values produced by EncryptSecret
+const encryptedPrefix = "enc:v1:"
+
+var (
+	secretKey     []byte
+	secretKeyErr  error
+	secretKeyOnce sync.Once
+)
+
+// loadSecretKey returns the encryption key, creating the key file on first use
+func loadSecretKey() ([]byte, error) {
+	secretKeyOnce.Do(func() {
+		if encoded := os.Getenv(SecretKeyEnv); encoded != "" {
+			secretKey, secretKeyErr = decodeSecretKey(encoded)
+			return
+		}
+
+		path := config.GetSecretKeyPath()
+		if data, err := os.ReadFile(path); err == nil {
+			secretKey, secretKeyErr = decodeSecretKey(string(data))
+			return
+		} else if !os.IsNotExist(err) {
+			secretKeyErr = fmt.Errorf("failed to read secret key: %w", err)
+			return
+		}
+
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			secretKeyErr = fmt.Errorf("failed to generate secret key: %w", err)
+			return
+		}
+		if err := config.EnsureUserDataDir(); err != nil {
+			secretKeyErr = err
+			return
+		}
+		if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)), 0600); err != nil {
+			secretKeyErr = fmt.Errorf("failed to write secret key: %w", err)
+			return
+		}
+		secretKey = key
+	})
+	return secretKey, secretKeyErr
+}
+
+// decodeSecretKey decodes a base64-encoded 32-byte key
+func decodeSecretKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("secret key must be 32 bytes encoded as base64")
+	}
+	return key, nil
+}
+
+// newSecretCipher creates the AES-GCM cipher for environment secrets
+func newSecretCipher() (cipher.AEAD, error) {
+	key, err := loadSecretKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptSecret encrypts a secret value for storage
+func EncryptSecret(plain string) (string, error) {
+	gcm, err := newSecretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plain), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret decrypts a value produced by EncryptSecret
+func DecryptSecret(stored string) (string, error) {
+	if !strings.HasPrefix(stored, encryptedPrefix) {
+		return "", errors.New("value is not encrypted")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, encryptedPrefix))
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newSecretCipher()
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("encrypted value is too short")
+	}
+
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,511 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+)
+
+// SiteOptions configures the static documentation site
+type SiteOptions struct {
+	Title  string // Site title, the localized "API Documentation" if empty
+	Locale string // en or zh
+	Theme  string // CSS replacing the default theme, empty for the default
+}
+
+// siteSearchEntry is one API in the client-side search index
+type siteSearchEntry struct {
+	Name     string `json:"name"`
+	Group    string `json:"group"`
+	Method   string `json:"method"`
+	Endpoint string `json:"endpoint"`
+	URL      string `json:"url"`
+	Text     string `json:"text"` // Lowercase text searched for the query terms
+}
+
+// GenerateSite generates a static documentation site: an overview page, one page per
+// group at <group>/index.html and one page per API at <group>/<api>.html, a search index
+// and a theme. Slugs come from the names and links are relative, so the site can be served
+// from any path of any static host. The output has no timestamps and is identical for the
+// same data.
+func GenerateSite(apis []APIWithParams, opts SiteOptions) []ExportFile {
+	labels := newExportLabels(opts.Locale)
+	if opts.Title == "" {
+		opts.Title = labels.title
+	}
+	theme := opts.Theme
+	if theme == "" {
+		theme = siteThemeCSS
+	}
+
+	site := &siteBuilder{opts: opts, labels: labels, groups: groupExportAPIs(apis)}
+
+	files := []ExportFile{{Name: "index.html", Content: site.overviewPage()}}
+	var index []siteSearchEntry
+	for _, group := range site.groups {
+		files = append(files, ExportFile{Name: group.slug + "/index.html", Content: site.groupPage(group)})
+		for i, api := range group.apis {
+			url := group.slug + "/" + group.apiSlugs[i] + ".html"
+			files = append(files, ExportFile{Name: url, Content: site.apiPage(group, i)})
+			index = append(index, siteSearchEntry{
+				Name:     api.API.Name,
+				Group:    group.name,
+				Method:   api.API.Method,
+				Endpoint: api.API.Endpoint,
+				URL:      url,
+				Text:     siteSearchText(group, api),
+			})
+		}
+	}
+
+	encodedIndex, _ := json.Marshal(index)
+	if index == nil {
+		encodedIndex = []byte("[]")
+	}
+
+	files = append(files,
+		ExportFile{Name: "assets/theme.css", Content: theme},
+		ExportFile{Name: "assets/search.js", Content: siteSearchJS},
+		ExportFile{Name: "assets/search-index.js", Content: "window.KNOT_SEARCH_INDEX = " + string(encodedIndex) + ";\n"},
+	)
+	return files
+}
+
+// siteSearchText collects the words an API is found by: its name, method, endpoint,
+// group, note and parameter paths
+func siteSearchText(group *exportGroup, api APIWithParams) string {
+	parts := []string{api.API.Name, api.API.Method, api.API.Endpoint, group.name}
+	if api.API.Note != nil {
+		parts = append(parts, *api.API.Note)
+	}
+	for _, params := range [][]models.Parameter{api.RequestParameters, api.ResponseParameters} {
+		for _, param := range params {
+			parts = append(parts, param.Name)
+		}
+	}
+	return strings.ToLower(strings.Join(strings.Fields(strings.Join(parts, " ")), " "))
+}
+
+// siteBuilder renders the pages of a static site
+type siteBuilder struct {
+	opts   SiteOptions
+	labels exportLabels
+	groups []*exportGroup
+}
+
+// overviewPage renders index.html, listing the groups
+func (s *siteBuilder) overviewPage() string {
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("<h1>%s</h1>\n", html.EscapeString(s.opts.Title)))
+	body.WriteString(fmt.Sprintf("<h2>%s</h2>\n<table class=\"overview\">\n<tbody>\n", s.labels.groups))
+	for _, group := range s.groups {
+		body.WriteString(fmt.Sprintf("<tr><td><a href=\"%s/index.html\">%s</a></td><td>%d %s</td></tr>\n",
+			group.slug, html.EscapeString(group.name), len(group.apis), s.labels.apis))
+	}
+	body.WriteString("</tbody>\n</table>\n")
+
+	return s.page("", s.opts.Title, nil, "", body.String())
+}
+
+// groupPage renders the overview page of a group, listing its APIs
+func (s *siteBuilder) groupPage(group *exportGroup) string {
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("<nav class=\"breadcrumb\"><a href=\"../index.html\">%s</a></nav>\n", html.EscapeString(s.opts.Title)))
+	body.WriteString(fmt.Sprintf("<h1>%s</h1>\n<table class=\"overview\">\n<tbody>\n", html.EscapeString(group.name)))
+	for i, api := range group.apis {
+		body.WriteString(fmt.Sprintf("<tr><td>%s</td><td><a href=\"%s.html\">%s</a></td><td><code>%s</code></td></tr>\n",
+			siteMethodBadge(api.API.Method), group.apiSlugs[i], html.EscapeString(api.API.Name), html.EscapeString(siteEndpoint(api))))
+	}
+	body.WriteString("</tbody>\n</table>\n")
+
+	return s.page("../", group.name, group, "", body.String())
+}
+
+// apiPage renders the page of the i-th API of a group
+func (s *siteBuilder) apiPage(group *exportGroup, i int) string {
+	api := group.apis[i]
+
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("<nav class=\"breadcrumb\"><a href=\"../index.html\">%s</a> › <a href=\"index.html\">%s</a></nav>\n",
+		html.EscapeString(s.opts.Title), html.EscapeString(group.name)))
+	body.WriteString(fmt.Sprintf("<h1>%s</h1>\n", html.EscapeString(api.API.Name)))
+	body.WriteString(fmt.Sprintf("<p class=\"api-meta\">%s <code class=\"endpoint\">%s</code> %s</p>\n",
+		siteMethodBadge(api.API.Method), html.EscapeString(siteEndpoint(api)), siteTypeBadge(api.API)))
+
+	if api.API.Note != nil && strings.TrimSpace(*api.API.Note) != "" {
+		body.WriteString(fmt.Sprintf("<h2>%s</h2>\n<div class=\"note\">%s</div>\n", s.labels.note, html.EscapeString(strings.TrimSpace(*api.API.Note))))
+	}
+	if api.API.Signature != nil && *api.API.Signature != "" {
+		body.WriteString(fmt.Sprintf("<h2>%s</h2>\n<pre><code>%s</code></pre>\n", s.labels.signature, html.EscapeString(*api.API.Signature)))
+	}
+
+	sections := []struct {
+		params  []models.Parameter
+		title   string
+		example string
+	}{
+		{api.RequestParameters, s.labels.requestParams, s.labels.requestExample},
+		{api.ResponseParameters, s.labels.responseParams, s.labels.responseExample},
+	}
+	if api.API.Type == models.APITypeEvent {
+		// Events carry a single message payload
+		sections = sections[:1]
+		sections[0].title, sections[0].example = s.labels.messagePayload, s.labels.payloadExample
+	}
+	for _, section := range sections {
+		tree := BuildParameterTree(section.params)
+
+		body.WriteString(fmt.Sprintf("<h2>%s</h2>\n", section.title))
+		if len(tree) == 0 {
+			body.WriteString(fmt.Sprintf("<p class=\"muted\">%s</p>\n", s.labels.noParameters))
+			continue
+		}
+
+		body.WriteString(fmt.Sprintf("<table class=\"params\">\n<thead><tr><th>%s</th><th>%s</th><th>%s</th><th>%s</th></tr></thead>\n<tbody>\n",
+			s.labels.name, s.labels.paramType, s.labels.required, s.labels.description))
+		s.parameterRows(&body, tree, 0)
+		body.WriteString("</tbody>\n</table>\n")
+
+		example, _ := json.MarshalIndent(GenerateExampleJSON(tree), "", "  ")
+		body.WriteString(fmt.Sprintf("<h3>%s</h3>\n<pre><code>%s</code></pre>\n", section.example, html.EscapeString(string(example))))
+	}
+
+	body.WriteString("<nav class=\"pager\">")
+	if i > 0 {
+		body.WriteString(fmt.Sprintf("<a class=\"prev\" href=\"%s.html\">← %s: %s</a>", group.apiSlugs[i-1], s.labels.previous, html.EscapeString(group.apis[i-1].API.Name)))
+	}
+	if i < len(group.apis)-1 {
+		body.WriteString(fmt.Sprintf("<a class=\"next\" href=\"%s.html\">%s: %s →</a>", group.apiSlugs[i+1], s.labels.next, html.EscapeString(group.apis[i+1].API.Name)))
+	}
+	body.WriteString("</nav>\n")
+
+	return s.page("../", api.API.Name, group, group.apiSlugs[i], body.String())
+}
+
+// parameterRows renders one table row per parameter, indenting children by depth
+func (s *siteBuilder) parameterRows(body *strings.Builder, params []models.Parameter, depth int) {
+	for _, param := range params {
+		paramType := param.Type
+		if param.Nullable {
+			paramType += "|null"
+		}
+
+		required := fmt.Sprintf("<span class=\"optional\">%s</span>", s.labels.no)
+		if param.Required {
+			required = fmt.Sprintf("<span class=\"required\">%s</span>", s.labels.yes)
+		}
+
+		description := ""
+		if param.Description != nil {
+			description = html.EscapeString(*param.Description)
+		}
+
+		body.WriteString(fmt.Sprintf("<tr><td class=\"param-name depth-%d\"><code>%s</code></td><td><span class=\"type\">%s</span></td><td>%s</td><td>%s</td></tr>\n",
+			depth, html.EscapeString(param.Name), html.EscapeString(paramType), required, description))
+
+		if len(param.Children) > 0 {
+			s.parameterRows(body, param.Children, depth+1)
+		}
+	}
+}
+
+// page wraps a page body in the site layout. root is the relative path to the site root;
+// current is the group whose APIs the sidebar expands, with the current API slug.
+func (s *siteBuilder) page(root, title string, current *exportGroup, currentAPI, content string) string {
+	var sidebar strings.Builder
+	for _, group := range s.groups {
+		class := ""
+		if group == current {
+			class = " class=\"active\""
+		}
+		sidebar.WriteString(fmt.Sprintf("<li%s><a href=\"%s%s/index.html\">%s</a>", class, root, group.slug, html.EscapeString(group.name)))
+
+		if group == current {
+			sidebar.WriteString("\n<ul>\n")
+			for i, api := range group.apis {
+				apiClass := ""
+				if group.apiSlugs[i] == currentAPI {
+					apiClass = " class=\"active\""
+				}
+				sidebar.WriteString(fmt.Sprintf("<li%s><a href=\"%s%s/%s.html\">%s</a></li>\n",
+					apiClass, root, group.slug, group.apiSlugs[i], html.EscapeString(api.API.Name)))
+			}
+			sidebar.WriteString("</ul>\n")
+		}
+		sidebar.WriteString("</li>\n")
+	}
+
+	lang := "en"
+	if s.opts.Locale == "zh" {
+		lang = "zh"
+	}
+
+	pageTitle := html.EscapeString(s.opts.Title)
+	if title != s.opts.Title {
+		pageTitle = html.EscapeString(title) + " · " + pageTitle
+	}
+
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="%s" data-root="%s">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>%s</title>
+<link rel="stylesheet" href="%sassets/theme.css">
+</head>
+<body>
+<header class="topbar">
+<a class="site-title" href="%sindex.html">%s</a>
+<div class="search">
+<input id="search" type="search" placeholder="%s" autocomplete="off">
+<ul id="search-results" hidden></ul>
+</div>
+</header>
+<div class="layout">
+<aside class="sidebar">
+<ul>
+%s</ul>
+</aside>
+<main class="content">
+%s</main>
+</div>
+<script src="%sassets/search-index.js"></script>
+<script src="%sassets/search.js"></script>
+</body>
+</html>
+`, lang, root, pageTitle, root, root, html.EscapeString(s.opts.Title), s.labels.search, sidebar.String(), content, root, root)
+}
+
+// siteEndpoint returns the resolved URL of an API, or its endpoint
+func siteEndpoint(api APIWithParams) string {
+	if api.URL != "" {
+		return api.URL
+	}
+	return api.API.Endpoint
+}
+
+// siteMethodBadge renders the HTTP method of an API, or nothing for APIs without one
+func siteMethodBadge(method string) string {
+	if method == "" {
+		return ""
+	}
+	return fmt.Sprintf("<span class=\"badge method-%s\">%s</span>", strings.ToLower(html.EscapeString(method)), html.EscapeString(method))
+}
+
+// siteTypeBadge renders the type of an API, with the broker protocol of EVENT APIs
+func siteTypeBadge(api models.API) string {
+	if api.Type != models.APITypeEvent {
+		return fmt.Sprintf("<span class=\"badge badge-type\">%s</span>", html.EscapeString(api.Type))
+	}
+	label := api.Type
+	if api.Protocol != nil && *api.Protocol != "" {
+		label += " · " + *api.Protocol
+	}
+	return fmt.Sprintf("<span class=\"badge badge-event\">%s</span>", html.EscapeString(label))
+}
+
+// siteSearchJS filters the search index as the user types. Results link relative to the
+// site root given by the data-root attribute of the page.
+const siteSearchJS = `(function () {
+  var input = document.getElementById('search');
+  var results = document.getElementById('search-results');
+  var root = document.documentElement.getAttribute('data-root') || '';
+  var index = window.KNOT_SEARCH_INDEX || [];
+
+  input.addEventListener('input', function () {
+    var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.innerHTML = '';
+    if (terms.length === 0) {
+      results.hidden = true;
+      return;
+    }
+
+    var matches = index.filter(function (entry) {
+      return terms.every(function (term) {
+        return entry.text.indexOf(term) !== -1;
+      });
+    }).slice(0, 20);
+
+    matches.forEach(function (entry) {
+      var item = document.createElement('li');
+      var link = document.createElement('a');
+      link.href = root + entry.url;
+
+      var name = document.createElement('strong');
+      name.textContent = entry.name;
+      var meta = document.createElement('span');
+      meta.textContent = entry.group + ' · ' + (entry.method ? entry.method + ' ' : '') + entry.endpoint;
+
+      link.appendChild(name);
+      link.appendChild(meta);
+      item.appendChild(link);
+      results.appendChild(item);
+    });
+    results.hidden = matches.length === 0;
+  });
+
+  input.addEventListener('keydown', function (event) {
+    if (event.key === 'Enter') {
+      var first = results.querySelector('a');
+      if (first) {
+        window.location.href = first.href;
+      }
+    } else if (event.key === 'Escape') {
+      input.value = '';
+      results.innerHTML = '';
+      results.hidden = true;
+    }
+  });
+})();
+`
+
+// siteThemeCSS is the default theme, following the light or dark preference of the browser
+const siteThemeCSS = `:root {
+  --bg: #ffffff;
+  --fg: #1f2933;
+  --muted: #6b7280;
+  --border: #e5e7eb;
+  --surface: #f8fafc;
+  --accent: #2563eb;
+  --code-bg: #f3f4f6;
+  --get: #059669;
+  --post: #2563eb;
+  --put: #d97706;
+  --patch: #7c3aed;
+  --delete: #dc2626;
+  --publish: #0891b2;
+  --subscribe: #db2777;
+  --query: #4f46e5;
+  --mutation: #c2410c;
+  --event: #ea580c;
+}
+
+@media (prefers-color-scheme: dark) {
+  :root {
+    --bg: #0f172a;
+    --fg: #e2e8f0;
+    --muted: #94a3b8;
+    --border: #1e293b;
+    --surface: #111827;
+    --accent: #60a5fa;
+    --code-bg: #1e293b;
+  }
+}
+
+* { box-sizing: border-box; }
+
+body {
+  margin: 0;
+  font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, "Helvetica Neue", Arial, sans-serif;
+  background: var(--bg);
+  color: var(--fg);
+  line-height: 1.6;
+}
+
+a { color: var(--accent); text-decoration: none; }
+a:hover { text-decoration: underline; }
+code, pre { font-family: "SFMono-Regular", Consolas, "Liberation Mono", Menlo, monospace; font-size: 0.9em; }
+code { background: var(--code-bg); padding: 0.1em 0.35em; border-radius: 4px; }
+pre { background: var(--code-bg); padding: 1em; border-radius: 6px; overflow-x: auto; }
+pre code { background: none; padding: 0; }
+
+.topbar {
+  position: sticky;
+  top: 0;
+  z-index: 10;
+  display: flex;
+  align-items: center;
+  justify-content: space-between;
+  gap: 1em;
+  padding: 0.75em 1.5em;
+  background: var(--surface);
+  border-bottom: 1px solid var(--border);
+}
+.site-title { font-weight: 600; font-size: 1.1em; color: var(--fg); }
+
+.search { position: relative; width: min(420px, 50vw); }
+.search input {
+  width: 100%;
+  padding: 0.45em 0.75em;
+  border: 1px solid var(--border);
+  border-radius: 6px;
+  background: var(--bg);
+  color: var(--fg);
+}
+#search-results {
+  position: absolute;
+  right: 0;
+  left: 0;
+  margin: 0.25em 0 0;
+  padding: 0;
+  list-style: none;
+  background: var(--bg);
+  border: 1px solid var(--border);
+  border-radius: 6px;
+  max-height: 60vh;
+  overflow-y: auto;
+}
+#search-results a { display: block; padding: 0.5em 0.75em; color: var(--fg); }
+#search-results a:hover { background: var(--surface); text-decoration: none; }
+#search-results span { display: block; font-size: 0.85em; color: var(--muted); }
+
+.layout { display: flex; }
+.sidebar {
+  flex: 0 0 260px;
+  position: sticky;
+  top: 3.5em;
+  height: calc(100vh - 3.5em);
+  overflow-y: auto;
+  padding: 1em;
+  border-right: 1px solid var(--border);
+}
+.sidebar ul { list-style: none; margin: 0; padding: 0; }
+.sidebar ul ul { margin: 0.25em 0 0.5em 0.75em; font-size: 0.92em; }
+.sidebar li { margin: 0.15em 0; }
+.sidebar li.active > a { font-weight: 600; }
+.sidebar ul ul a { color: var(--muted); }
+.sidebar ul ul li.active > a { color: var(--accent); }
+
+.content { flex: 1; min-width: 0; max-width: 960px; padding: 1.5em 2.5em 4em; }
+.breadcrumb { font-size: 0.9em; color: var(--muted); }
+.api-meta { display: flex; align-items: center; gap: 0.5em; flex-wrap: wrap; }
+.note { white-space: pre-wrap; background: var(--surface); border-left: 3px solid var(--accent); padding: 0.75em 1em; }
+.muted { color: var(--muted); }
+
+table { width: 100%; border-collapse: collapse; margin: 0.5em 0 1em; }
+th, td { text-align: left; padding: 0.45em 0.6em; border-bottom: 1px solid var(--border); vertical-align: top; }
+th { background: var(--surface); font-weight: 600; }
+.param-name.depth-1 { padding-left: 1.8em; }
+.param-name.depth-2 { padding-left: 3em; }
+.param-name.depth-3 { padding-left: 4.2em; }
+.param-name.depth-4, .param-name.depth-5, .param-name.depth-6 { padding-left: 5.4em; }
+.type { color: var(--muted); font-family: monospace; }
+.required { color: var(--delete); font-weight: 600; }
+.optional { color: var(--muted); }
+
+.badge { display: inline-block; padding: 0.1em 0.5em; border-radius: 4px; font-size: 0.8em; font-weight: 600; color: #fff; background: var(--muted); }
+.method-get { background: var(--get); }
+.method-post { background: var(--post); }
+.method-put { background: var(--put); }
+.method-patch { background: var(--patch); }
+.method-delete { background: var(--delete); }
+.method-publish { background: var(--publish); }
+.method-subscribe { background: var(--subscribe); }
+.method-query { background: var(--query); }
+.method-mutation { background: var(--mutation); }
+.method-subscription { background: var(--subscribe); }
+.badge-type { background: none; color: var(--muted); border: 1px solid var(--border); }
+.badge-event { background: none; color: var(--event); border: 1px solid var(--event); }
+
+.pager { display: flex; justify-content: space-between; margin-top: 3em; padding-top: 1em; border-top: 1px solid var(--border); }
+.pager .next { margin-left: auto; }
+
+@media (max-width: 800px) {
+  .layout { display: block; }
+  .sidebar { position: static; height: auto; border-right: none; border-bottom: 1px solid var(--border); }
+  .content { padding: 1em; }
+}
+`
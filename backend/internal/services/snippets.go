@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go/format"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+)
+
+// SnippetLanguages lists the clients snippets can be generated for
+var SnippetLanguages = []string{"curl", "httpie", "fetch", "python", "go"}
+
+// snippetLabels are the display names of SnippetLanguages
+var snippetLabels = map[string]string{
+	"curl":   "cURL",
+	"httpie": "HTTPie",
+	"fetch":  "JavaScript (fetch)",
+	"python": "Python (requests)",
+	"go":     "Go (net/http)",
+}
+
+// SnippetDefaultBaseURL is the server address of snippets when neither the input nor the
+// environment provides a base URL
+var SnippetDefaultBaseURL = "https://api.example.com"
+
+// Snippet is a ready-to-run call of an API in one client
+type Snippet struct {
+	Language string `json:"language"`
+	Label    string `json:"label"`
+	Code     string `json:"code"`
+}
+
+// snippetRequest is the request a snippet sends
+type snippetRequest struct {
+	method  string
+	url     string
+	headers [][2]string // Sorted by name
+	body    []byte      // Indented when json is set
+	json    bool
+}
+
+// GenerateSnippets builds the request of an API like the request executor and writes it as
+// code for the given languages of SnippetLanguages, or all of them if none are given.
+// Environment secrets are masked in the code.
+func GenerateSnippets(api models.API, requestTree []models.Parameter, input ExecuteInput, languages []string) ([]Snippet, error) {
+	if len(languages) == 0 {
+		languages = SnippetLanguages
+	}
+	for _, lang := range languages {
+		if _, ok := snippetLabels[lang]; !ok {
+			return nil, ErrUnsupportedLanguage
+		}
+	}
+
+	env := input.Env
+	if strings.TrimSpace(input.BaseURL) == "" && (env == nil || env.Variables[BaseURLVariable] == "") {
+		input.BaseURL = SnippetDefaultBaseURL
+	}
+
+	req, payload, err := BuildRequest(context.Background(), api, requestTree, input)
+	if err != nil {
+		return nil, err
+	}
+
+	request := snippetRequest{method: req.Method, url: req.URL.String()}
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		request.headers = append(request.headers, [2]string{name, req.Header.Get(name)})
+	}
+
+	request.body = payload
+	if payload != nil && strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, payload, "", "  "); err == nil {
+			request.body = indented.Bytes()
+			request.json = true
+		}
+	}
+
+	snippets := make([]Snippet, 0, len(languages))
+	for _, lang := range languages {
+		var code string
+		switch lang {
+		case "curl":
+			code = curlSnippet(request)
+		case "httpie":
+			code = httpieSnippet(request)
+		case "fetch":
+			code = fetchSnippet(request)
+		case "python":
+			code = pythonSnippet(request)
+		case "go":
+			code = goSnippet(request)
+		}
+		snippets = append(snippets, Snippet{Language: lang, Label: snippetLabels[lang], Code: env.Mask(code)})
+	}
+	return snippets, nil
+}
+
+// curlSnippet writes a request as a curl command
+func curlSnippet(r snippetRequest) string {
+	var out strings.Builder
+	out.WriteString("curl")
+	switch r.method {
+	case http.MethodGet:
+	case http.MethodHead:
+		out.WriteString(" --head")
+	default:
+		out.WriteString(" -X " + r.method)
+	}
+	out.WriteString(" " + shellQuote(r.url))
+	for _, header := range r.headers {
+		out.WriteString(" \\\n  -H " + shellQuote(header[0]+": "+header[1]))
+	}
+	if r.body != nil {
+		out.WriteString(" \\\n  --data-raw " + shellQuote(string(r.body)))
+	}
+	return out.String() + "\n"
+}
+
+// httpieSnippet writes a request as an HTTPie command
+func httpieSnippet(r snippetRequest) string {
+	var out strings.Builder
+	out.WriteString("http " + r.method + " " + shellQuote(r.url))
+	for _, header := range r.headers {
+		out.WriteString(" \\\n  " + shellQuote(header[0]+":"+header[1]))
+	}
+	if r.body != nil {
+		out.WriteString(" \\\n  --raw " + shellQuote(string(r.body)))
+	}
+	return out.String() + "\n"
+}
+
+// fetchSnippet writes a request as a JavaScript fetch call
+func fetchSnippet(r snippetRequest) string {
+	var out strings.Builder
+	out.WriteString("const response = await fetch(" + jsString(r.url))
+
+	if r.method != http.MethodGet || len(r.headers) > 0 || r.body != nil {
+		out.WriteString(", {\n")
+		if r.method != http.MethodGet {
+			out.WriteString("  method: " + jsString(r.method) + ",\n")
+		}
+		if len(r.headers) > 0 {
+			out.WriteString("  headers: {\n")
+			for _, header := range r.headers {
+				out.WriteString("    " + jsString(header[0]) + ": " + jsString(header[1]) + ",\n")
+			}
+			out.WriteString("  },\n")
+		}
+		if r.json {
+			out.WriteString("  body: JSON.stringify(" + strings.ReplaceAll(string(r.body), "\n", "\n  ") + "),\n")
+		} else if r.body != nil {
+			out.WriteString("  body: " + jsString(string(r.body)) + ",\n")
+		}
+		out.WriteString("}")
+	}
+
+	out.WriteString(");\n\nconsole.log(response.status, await response.text());\n")
+	return out.String()
+}
+
+// pythonSnippet writes a request as a call of the Python requests library
+func pythonSnippet(r snippetRequest) string {
+	var out strings.Builder
+	out.WriteString("import requests\n\n")
+
+	switch r.method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		out.WriteString("response = requests." + strings.ToLower(r.method) + "(\n")
+	default:
+		out.WriteString("response = requests.request(\n    " + jsString(r.method) + ",\n")
+	}
+	out.WriteString("    " + jsString(r.url) + ",\n")
+
+	// requests sets the content type of JSON bodies itself
+	var headers [][2]string
+	for _, header := range r.headers {
+		if !(r.json && header[0] == "Content-Type") {
+			headers = append(headers, header)
+		}
+	}
+	if len(headers) > 0 {
+		out.WriteString("    headers={\n")
+		for _, header := range headers {
+			out.WriteString("        " + jsString(header[0]) + ": " + jsString(header[1]) + ",\n")
+		}
+		out.WriteString("    },\n")
+	}
+
+	if r.json {
+		value, err := DecodeOrderedJSON(r.body)
+		if err == nil {
+			out.WriteString("    json=")
+			writePythonLiteral(&out, value, "    ")
+			out.WriteString(",\n")
+		}
+	} else if r.body != nil {
+		out.WriteString("    data=" + jsString(string(r.body)) + ",\n")
+	}
+
+	out.WriteString(")\nprint(response.status_code, response.text)\n")
+	return out.String()
+}
+
+// writePythonLiteral writes a value decoded by DecodeOrderedJSON as a Python literal
+func writePythonLiteral(out *strings.Builder, value interface{}, indent string) {
+	switch v := value.(type) {
+	case nil:
+		out.WriteString("None")
+	case bool:
+		if v {
+			out.WriteString("True")
+		} else {
+			out.WriteString("False")
+		}
+	case json.Number:
+		out.WriteString(v.String())
+	case string:
+		out.WriteString(jsString(v))
+	case *OrderedObject:
+		if v.Len() == 0 {
+			out.WriteString("{}")
+			return
+		}
+		out.WriteString("{\n")
+		for _, key := range v.Keys {
+			out.WriteString(indent + "    " + jsString(key) + ": ")
+			writePythonLiteral(out, v.Values[key], indent+"    ")
+			out.WriteString(",\n")
+		}
+		out.WriteString(indent + "}")
+	case []interface{}:
+		if len(v) == 0 {
+			out.WriteString("[]")
+			return
+		}
+		out.WriteString("[\n")
+		for _, item := range v {
+			out.WriteString(indent + "    ")
+			writePythonLiteral(out, item, indent+"    ")
+			out.WriteString(",\n")
+		}
+		out.WriteString(indent + "]")
+	}
+}
+
+// goSnippet writes a request as a Go program using net/http
+func goSnippet(r snippetRequest) string {
+	var out strings.Builder
+	out.WriteString("package main\n\nimport (\n\t\"fmt\"\n\t\"io\"\n\t\"net/http\"\n")
+	if r.body != nil {
+		out.WriteString("\t\"strings\"\n")
+	}
+	out.WriteString(")\n\nfunc main() {\n")
+
+	body := "nil"
+	if r.body != nil {
+		out.WriteString("\tbody := strings.NewReader(" + goString(string(r.body)) + ")\n")
+		body = "body"
+	}
+
+	method := jsString(r.method)
+	switch r.method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		method = "http.Method" + r.method[:1] + strings.ToLower(r.method[1:])
+	}
+	out.WriteString("\treq, err := http.NewRequest(" + method + ", " + jsString(r.url) + ", " + body + ")\n")
+	out.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	for _, header := range r.headers {
+		out.WriteString("\treq.Header.Set(" + jsString(header[0]) + ", " + jsString(header[1]) + ")\n")
+	}
+
+	out.WriteString(`
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(resp.Status, string(data))
+}
+`)
+
+	formatted, err := format.Source([]byte(out.String()))
+	if err != nil {
+		return out.String()
+	}
+	return string(formatted)
+}
+
+// shellQuote quotes a string for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// jsString quotes a string as a JSON string literal, which is also valid in JavaScript, Python and Go
+func jsString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
+
+// goString quotes a string as a Go raw string literal where possible
+func goString(s string) string {
+	if strings.Contains(s, "`") || strings.Contains(s, "\r") {
+		return jsString(s)
+	}
+	return "`" + s + "`"
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ProjAnvil/knot/backend/internal/config"
+	"github.com/ProjAnvil/knot/backend/internal/models"
+)
+
+// DefaultTemplateName is the name of the built-in HTML export template
+const DefaultTemplateName = "default.html"
+
+//go:embed templates/default.html.tmpl
+var defaultTemplateSource string
+
+var defaultExportTemplate = template.Must(ParseExportTemplate(DefaultTemplateName, defaultTemplateSource))
+
+// templateNamePattern restricts template names to file names with an output extension, e.g. wiki.md
+var templateNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*(\.[A-Za-z0-9]+)+$`)
+
+var (
+	ErrTemplateNotFound    = errors.New("template not found")
+	ErrInvalidTemplateName = errors.New("template names are file names with an extension, e.g. wiki.md")
+	ErrTemplateReadOnly    = errors.New("the built-in template cannot be changed")
+)
+
+// TemplateData is the data export templates are executed with
+type TemplateData struct {
+	Title       string            // Localized document title
+	Locale      string            // en or zh
+	Labels      map[string]string // Localized headings, e.g. requestParameters or noParameters
+	Generator   string            // Always "Knot"
+	GeneratedAt time.Time         // Time of the export
+	Groups      []TemplateGroup   // Groups in export order, each with its APIs
+	APIs        []TemplateAPI     // Every API in the order of the groups
+}
+
+// TemplateGroup is a group of TemplateData
+type TemplateGroup struct {
+	ID   uint
+	Name string
+	Slug string // Unique among the exported groups
+	APIs []TemplateAPI
+}
+
+// TemplateAPI is an API of TemplateData with its parameter trees and examples
+type TemplateAPI struct {
+	ID                 uint
+	Name               string
+	Slug               string // Unique within the group
+	Anchor             string // Unique within the export: <group slug>/<API slug>
+	GroupName          string
+	GroupSlug          string
+	Method             string // HTTP method, PUBLISH or SUBSCRIBE for EVENT APIs, or the operation type for GRAPHQL APIs
+	Type               string // HTTP, RPC, EVENT or GRAPHQL
+	Protocol           string // Broker protocol of EVENT APIs, e.g. kafka
+	Signature          string // SDL of the root field of GRAPHQL APIs and the types it uses
+	Endpoint           string // Path, channel name for EVENT APIs, or root field name for GRAPHQL APIs
+	URL                string // Endpoint resolved for the environment of the export, or the endpoint
+	Note               string
+	RequestParameters  []TemplateParameter // Message payload for EVENT APIs
+	ResponseParameters []TemplateParameter
+	RequestExample     *OrderedObject // Example generated from the request parameters
+	ResponseExample    *OrderedObject // Example generated from the response parameters
+	Snippets           []Snippet      // Calls of the API in curl, HTTPie, fetch, Python and Go
+}
+
+// TemplateParameter is a parameter of TemplateAPI with its children
+type TemplateParameter struct {
+	ID          uint
+	Name        string
+	Path        string // Dotted path such as customer.addresses[].city
+	Type        string
+	Description string
+	Required    bool
+	Nullable    bool
+	Depth       int // 0 for top-level parameters
+	Children    []TemplateParameter
+}
+
+// ExportTemplateInfo describes an available export template
+type ExportTemplateInfo struct {
+	Name      string     `json:"name"`
+	BuiltIn   bool       `json:"builtIn"`
+	Size      int64      `json:"size"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// TemplateDir returns the directory holding user export templates
+func TemplateDir() string {
+	return filepath.Join(config.GetUserDataDir(), "templates")
+}
+
+// BuildTemplateData converts loaded APIs into the data model of export templates
+func BuildTemplateData(apis []APIWithParams, locale string) TemplateData {
+	labels := newExportLabels(locale)
+	if locale != "zh" {
+		locale = "en"
+	}
+
+	data := TemplateData{
+		Title:       labels.title,
+		Locale:      locale,
+		Labels:      labels.templateLabels(),
+		Generator:   "Knot",
+		GeneratedAt: time.Now().UTC().Truncate(time.Second),
+		Groups:      []TemplateGroup{},
+		APIs:        []TemplateAPI{},
+	}
+
+	for _, group := range groupExportAPIs(apis) {
+		templateGroup := TemplateGroup{ID: group.id, Name: group.name, Slug: group.slug, APIs: []TemplateAPI{}}
+		for i, api := range group.apis {
+			requestTree := BuildParameterTree(api.RequestParameters)
+			responseTree := BuildParameterTree(api.ResponseParameters)
+
+			url := api.API.Endpoint
+			if api.URL != "" {
+				url = api.URL
+			}
+			note := ""
+			if api.API.Note != nil {
+				note = strings.TrimSpace(*api.API.Note)
+			}
+			protocol := ""
+			if api.API.Protocol != nil {
+				protocol = *api.API.Protocol
+			}
+			signature := ""
+			if api.API.Signature != nil {
+				signature = *api.API.Signature
+			}
+			snippets, err := GenerateSnippets(api.API, requestTree, ExecuteInput{Env: api.Env}, nil)
+			if err != nil {
+				// EVENT and GRAPHQL APIs cannot be called as single HTTP requests
+				snippets = []Snippet{}
+			}
+
+			templateAPI := TemplateAPI{
+				ID:                 api.API.ID,
+				Name:               api.API.Name,
+				Slug:               group.apiSlugs[i],
+				Anchor:             group.slug + "/" + group.apiSlugs[i],
+				GroupName:          group.name,
+				GroupSlug:          group.slug,
+				Method:             api.API.Method,
+				Type:               api.API.Type,
+				Protocol:           protocol,
+				Signature:          signature,
+				Endpoint:           api.API.Endpoint,
+				URL:                url,
+				Note:               note,
+				RequestParameters:  templateParameters(requestTree, "", 0),
+				ResponseParameters: templateParameters(responseTree, "", 0),
+				RequestExample:     GenerateExampleJSON(requestTree),
+				ResponseExample:    GenerateExampleJSON(responseTree),
+				Snippets:           snippets,
+			}
+			templateGroup.APIs = append(templateGroup.APIs, templateAPI)
+			data.APIs = append(data.APIs, templateAPI)
+		}
+		data.Groups = append(data.Groups, templateGroup)
+	}
+	return data
+}
+
+// templateParameters converts a parameter tree, naming parameters by their dotted path
+func templateParameters(params []models.Parameter, prefix string, depth int) []TemplateParameter {
+	result := make([]TemplateParameter, 0, len(params))
+	for _, param := range params {
+		path := parameterPath(prefix, param, len(params))
+
+		description := ""
+		if param.Description != nil {
+			description = *param.Description
+		}
+
+		result = append(result, TemplateParameter{
+			ID:          param.ID,
+			Name:        param.Name,
+			Path:        path,
+			Type:        param.Type,
+			Description: description,
+			Required:    param.Required,
+			Nullable:    param.Nullable,
+			Depth:       depth,
+			Children:    templateParameters(param.Children, childPathPrefix(path, param.Type), depth+1),
+		})
+	}
+	return result
+}
+
+// templateFuncs are the helper functions available to export templates, in addition to
+// the built-in functions of text/template such as html, js, len and printf
+var templateFuncs = template.FuncMap{
+	"slugify":  Slugify,
+	"markdown": RenderMarkdown,
+	"json": func(v interface{}) (string, error) {
+		out, err := json.MarshalIndent(v, "", "  ")
+		return string(out), err
+	},
+	"jsonCompact": func(v interface{}) (string, error) {
+		out, err := json.Marshal(v)
+		return string(out), err
+	},
+	"flatten": flattenTemplateParameters,
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"trim":    strings.TrimSpace,
+	"join":    strings.Join,
+	"replace": strings.ReplaceAll,
+	"repeat":  strings.Repeat,
+	"add":     func(a, b int) int { return a + b },
+	"mul":     func(a, b int) int { return a * b },
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// flattenTemplateParameters lists a parameter tree depth-first, for templates that render
+// parameters as flat rows
+func flattenTemplateParameters(params []TemplateParameter) []TemplateParameter {
+	var result []TemplateParameter
+	for _, param := range params {
+		result = append(result, param)
+		result = append(result, flattenTemplateParameters(param.Children)...)
+	}
+	return result
+}
+
+// ParseExportTemplate parses the source of an export template with the helper functions
+func ParseExportTemplate(name, source string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(source)
+}
+
+// RenderExportTemplate executes an export template for APIs
+func RenderExportTemplate(tmpl *template.Template, apis []APIWithParams, locale string) (string, error) {
+	var out strings.Builder
+	if err := tmpl.Execute(&out, BuildTemplateData(apis, locale)); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// TemplateExtension returns the extension of the files a template produces, e.g. .md for wiki.md
+func TemplateExtension(name string) string {
+	if ext := filepath.Ext(name); ext != "" {
+		return ext
+	}
+	return ".txt"
+}
+
+// ListExportTemplates lists the built-in template and the templates in TemplateDir, by name
+func ListExportTemplates() ([]ExportTemplateInfo, error) {
+	templates := []ExportTemplateInfo{{Name: DefaultTemplateName, BuiltIn: true, Size: int64(len(defaultTemplateSource))}}
+
+	entries, err := os.ReadDir(TemplateDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".tmpl")
+		if entry.IsDir() || name == entry.Name() || name == DefaultTemplateName || !templateNamePattern.MatchString(name) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		updatedAt := info.ModTime().UTC()
+		templates = append(templates, ExportTemplateInfo{Name: name, Size: info.Size(), UpdatedAt: &updatedAt})
+	}
+
+	sort.SliceStable(templates[1:], func(i, j int) bool {
+		return templates[i+1].Name < templates[j+1].Name
+	})
+	return templates, nil
+}
+
+// LoadExportTemplate returns the source of a template by name
+func LoadExportTemplate(name string) (string, error) {
+	if name == DefaultTemplateName {
+		return defaultTemplateSource, nil
+	}
+	if !templateNamePattern.MatchString(name) {
+		return "", ErrInvalidTemplateName
+	}
+
+	source, err := os.ReadFile(templatePath(name))
+	if os.IsNotExist(err) {
+		return "", ErrTemplateNotFound
+	}
+	return string(source), err
+}
+
+// SaveExportTemplate checks that a template parses and stores it in TemplateDir
+func SaveExportTemplate(name, source string) error {
+	if name == DefaultTemplateName {
+		return ErrTemplateReadOnly
+	}
+	if !templateNamePattern.MatchString(name) {
+		return ErrInvalidTemplateName
+	}
+	if _, err := ParseExportTemplate(name, source); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(TemplateDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create template directory: %w", err)
+	}
+	return os.WriteFile(templatePath(name), []byte(source), 0644)
+}
+
+// DeleteExportTemplate removes a template from TemplateDir
+func DeleteExportTemplate(name string) error {
+	if name == DefaultTemplateName {
+		return ErrTemplateReadOnly
+	}
+	if !templateNamePattern.MatchString(name) {
+		return ErrInvalidTemplateName
+	}
+
+	err := os.Remove(templatePath(name))
+	if os.IsNotExist(err) {
+		return ErrTemplateNotFound
+	}
+	return err
+}
+
+// templatePath returns the file of a user template
+func templatePath(name string) string {
+	return filepath.Join(TemplateDir(), name+".tmpl")
+}
+
+// templateLabels returns the labels under the keys documented for templates
+func (l exportLabels) templateLabels() map[string]string {
+	return map[string]string{
+		"title":              l.title,
+		"generatedAt":        l.generatedAt,
+		"tableOfContents":    l.tableOfContents,
+		"requestParameters":  l.requestParams,
+		"responseParameters": l.responseParams,
+		"requestExample":     l.requestExample,
+		"responseExample":    l.responseExample,
+		"snippets":           l.snippets,
+		"messagePayload":     l.messagePayload,
+		"payloadExample":     l.payloadExample,
+		"signature":          l.signature,
+		"note":               l.note,
+		"noParameters":       l.noParameters,
+		"name":               l.name,
+		"type":               l.paramType,
+		"required":           l.required,
+		"description":        l.description,
+		"yes":                l.yes,
+		"no":                 l.no,
+		"groups":             l.groups,
+		"apis":               l.apis,
+		"previous":           l.previous,
+		"next":               l.next,
+	}
+}
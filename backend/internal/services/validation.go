@@ -0,0 +1,221 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+)
+
+// Validation issue codes
+const (
+	IssueMissingRequired = "missing_required"
+	IssueTypeMismatch    = "type_mismatch"
+	IssueUnexpectedField = "unexpected_field"
+	IssueInvalidJSON     = "invalid_json"
+)
+
+// ValidationIssue describes a place where a payload does not match a parameter tree
+type ValidationIssue struct {
+	Path     string `json:"path"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// ValidatePayload checks a decoded JSON payload against a built parameter tree and reports
+// missing required fields, type mismatches and fields that are not documented.
+// Objects documented without children accept any fields.
+// Paths use the form $.user.tags[0].id. An empty result means the payload conforms.
+func ValidatePayload(tree []models.Parameter, payload interface{}) []ValidationIssue {
+	issues := []ValidationIssue{}
+	if len(tree) == 0 {
+		return issues
+	}
+
+	obj, ok := payload.(map[string]interface{})
+	if !ok {
+		return append(issues, ValidationIssue{
+			Path:     "$",
+			Code:     IssueTypeMismatch,
+			Message:  "expected a JSON object",
+			Expected: "object",
+			Actual:   jsonTypeName(payload),
+		})
+	}
+
+	validateObject("$", tree, obj, &issues)
+	return issues
+}
+
+// ValidateJSON decodes a raw JSON document and checks it against a built parameter tree
+func ValidateJSON(tree []models.Parameter, data []byte) []ValidationIssue {
+	var payload interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return []ValidationIssue{{
+			Path:    "$",
+			Code:    IssueInvalidJSON,
+			Message: "payload is not valid JSON: " + err.Error(),
+		}}
+	}
+	return ValidatePayload(tree, payload)
+}
+
+// validateObject checks the fields of an object against the parameters describing it
+func validateObject(path string, params []models.Parameter, obj map[string]interface{}, issues *[]ValidationIssue) {
+	documented := make(map[string]bool, len(params))
+	for _, param := range params {
+		documented[param.Name] = true
+		fieldPath := path + "." + param.Name
+		value, present := obj[param.Name]
+
+		if !present {
+			if param.Required {
+				*issues = append(*issues, ValidationIssue{
+					Path:     fieldPath,
+					Code:     IssueMissingRequired,
+					Message:  fmt.Sprintf("required field %q is missing", param.Name),
+					Expected: param.Type,
+				})
+			}
+			continue
+		}
+
+		validateValue(fieldPath, param, value, issues)
+	}
+
+	unexpected := make([]string, 0)
+	for key := range obj {
+		if !documented[key] {
+			unexpected = append(unexpected, key)
+		}
+	}
+	sort.Strings(unexpected)
+
+	for _, key := range unexpected {
+		*issues = append(*issues, ValidationIssue{
+			Path:    path + "." + key,
+			Code:    IssueUnexpectedField,
+			Message: fmt.Sprintf("field %q is not documented", key),
+			Actual:  jsonTypeName(obj[key]),
+		})
+	}
+}
+
+// validateValue checks a single present value against its parameter.
+// Null is a type mismatch unless the parameter is nullable.
+func validateValue(path string, param models.Parameter, value interface{}, issues *[]ValidationIssue) {
+	if value == nil && param.Nullable {
+		return
+	}
+
+	actual := jsonTypeName(value)
+	if !typeMatches(param.Type, value) {
+		*issues = append(*issues, ValidationIssue{
+			Path:     path,
+			Code:     IssueTypeMismatch,
+			Message:  fmt.Sprintf("expected %s but got %s", param.Type, actual),
+			Expected: param.Type,
+			Actual:   actual,
+		})
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(param.Children) > 0 {
+			validateObject(path, param.Children, v, issues)
+		}
+	case []interface{}:
+		if len(param.Children) == 0 {
+			return
+		}
+		for i, item := range v {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			itemObj, ok := item.(map[string]interface{})
+			if !ok && isPrimitiveArray(param) {
+				// The item node of a primitive array describes the type of every item
+				validateValue(itemPath, param.Children[0], item, issues)
+				continue
+			}
+			if !ok {
+				*issues = append(*issues, ValidationIssue{
+					Path:     itemPath,
+					Code:     IssueTypeMismatch,
+					Message:  "expected object but got " + jsonTypeName(item),
+					Expected: "object",
+					Actual:   jsonTypeName(item),
+				})
+				continue
+			}
+			validateObject(itemPath, param.Children, itemObj, issues)
+		}
+	}
+}
+
+// isPrimitiveArray reports whether an array parameter describes a list of primitive values,
+// i.e. its only child is the item node of a primitive array. A single named field still
+// describes the objects of an array of objects.
+func isPrimitiveArray(param models.Parameter) bool {
+	if len(param.Children) != 1 {
+		return false
+	}
+	child := param.Children[0]
+	return child.Name == arrayItemName && !hasParamType(child.Type, "object") && !hasParamType(child.Type, "array")
+}
+
+// typeMatches reports whether a decoded value satisfies a parameter type. Unions such as
+// string|number accept any of their members, integer accepts whole numbers only and
+// unknown parameter types accept any value.
+func typeMatches(paramType string, value interface{}) bool {
+	actual := jsonTypeName(value)
+	for _, member := range strings.Split(paramType, "|") {
+		switch member {
+		case "string", "number", "boolean", "object", "array":
+			if member == actual {
+				return true
+			}
+		case "integer":
+			if number, ok := value.(float64); ok && number == math.Trunc(number) {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// hasParamType reports whether a parameter type is, or is a union containing, the given type
+func hasParamType(paramType, name string) bool {
+	for _, member := range strings.Split(paramType, "|") {
+		if member == name {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeName returns the JSON type of a decoded value
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64, float32, int, int64:
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
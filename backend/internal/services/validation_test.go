@@ -0,0 +1,145 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+)
+
+func TestValidateJSON(t *testing.T) {
+	tree := []models.Parameter{
+		{Name: "id", Type: "integer", Required: true},
+		{Name: "name", Type: "string", Required: true},
+		{Name: "nickname", Type: "string"},
+		{Name: "email", Type: "string", Nullable: true},
+		{Name: "tags", Type: "array", Children: []models.Parameter{
+			{Name: "id", Type: "number", Required: true},
+			{Name: "label", Type: "string"},
+		}},
+		{Name: "children", Type: "array", Children: []models.Parameter{
+			{Name: "id", Type: "number"},
+		}},
+		{Name: "scores", Type: "array", Children: []models.Parameter{
+			{Name: arrayItemName, Type: "number"},
+		}},
+	}
+
+	tests := []struct {
+		name    string
+		payload string
+		want    []ValidationIssue
+	}{
+		{
+			name:    "conforming payload",
+			payload: `{"id": 1, "name": "Alice", "email": null, "tags": [{"id": 1, "label": "a"}]}`,
+			want:    []ValidationIssue{},
+		},
+		{
+			name:    "missing required",
+			payload: `{"id": 1}`,
+			want: []ValidationIssue{
+				{Path: "$.name", Code: IssueMissingRequired, Message: `required field "name" is missing`, Expected: "string"},
+			},
+		},
+		{
+			name:    "unexpected field",
+			payload: `{"id": 1, "name": "Alice", "role": "admin"}`,
+			want: []ValidationIssue{
+				{Path: "$.role", Code: IssueUnexpectedField, Message: `field "role" is not documented`, Actual: "string"},
+			},
+		},
+		{
+			name:    "type mismatch",
+			payload: `{"id": 1.5, "name": 7}`,
+			want: []ValidationIssue{
+				{Path: "$.id", Code: IssueTypeMismatch, Message: "expected integer but got number", Expected: "integer", Actual: "number"},
+				{Path: "$.name", Code: IssueTypeMismatch, Message: "expected string but got number", Expected: "string", Actual: "number"},
+			},
+		},
+		{
+			name:    "null on required non-nullable field",
+			payload: `{"id": 1, "name": null}`,
+			want: []ValidationIssue{
+				{Path: "$.name", Code: IssueTypeMismatch, Message: "expected string but got null", Expected: "string", Actual: "null"},
+			},
+		},
+		{
+			name:    "null on optional non-nullable field",
+			payload: `{"id": 1, "name": "Alice", "nickname": null}`,
+			want: []ValidationIssue{
+				{Path: "$.nickname", Code: IssueTypeMismatch, Message: "expected string but got null", Expected: "string", Actual: "null"},
+			},
+		},
+		{
+			name:    "null on nullable field",
+			payload: `{"id": 1, "name": "Alice", "email": null}`,
+			want:    []ValidationIssue{},
+		},
+		{
+			name:    "nested array path",
+			payload: `{"id": 1, "name": "Alice", "tags": [{"id": 1}, {"label": 2}]}`,
+			want: []ValidationIssue{
+				{Path: "$.tags[1].id", Code: IssueMissingRequired, Message: `required field "id" is missing`, Expected: "number"},
+				{Path: "$.tags[1].label", Code: IssueTypeMismatch, Message: "expected string but got number", Expected: "string", Actual: "number"},
+			},
+		},
+		{
+			name:    "single-field object array",
+			payload: `{"id": 1, "name": "Alice", "children": [{"id": 1}, 2]}`,
+			want: []ValidationIssue{
+				{Path: "$.children[1]", Code: IssueTypeMismatch, Message: "expected object but got number", Expected: "object", Actual: "number"},
+			},
+		},
+		{
+			name:    "primitive array",
+			payload: `{"id": 1, "name": "Alice", "scores": [1, "two"]}`,
+			want: []ValidationIssue{
+				{Path: "$.scores[1]", Code: IssueTypeMismatch, Message: "expected number but got string", Expected: "number", Actual: "string"},
+			},
+		},
+		{
+			name:    "invalid JSON",
+			payload: `{"id": `,
+			want: []ValidationIssue{
+				{Path: "$", Code: IssueInvalidJSON, Message: "payload is not valid JSON: unexpected end of JSON input"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateJSON(tree, []byte(tt.payload))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateJSON(%s)\n got: %+v\nwant: %+v", tt.payload, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestValidationIssueJSON pins the JSON form of issues, which scripts parse from
+// knot validate --json and POST /api/apis/:id/validate
+func TestValidationIssueJSON(t *testing.T) {
+	tree := []models.Parameter{
+		{Name: "id", Type: "integer", Required: true},
+		{Name: "tags", Type: "array", Children: []models.Parameter{
+			{Name: "label", Type: "string"},
+		}},
+	}
+
+	issues := ValidateJSON(tree, []byte(`{"tags": [{"label": true}], "extra": 1}`))
+	data, err := json.Marshal(issues)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+
+	want := `[` +
+		`{"path":"$.id","code":"missing_required","message":"required field \"id\" is missing","expected":"integer"},` +
+		`{"path":"$.tags[0].label","code":"type_mismatch","message":"expected string but got boolean","expected":"string","actual":"boolean"},` +
+		`{"path":"$.extra","code":"unexpected_field","message":"field \"extra\" is not documented","actual":"number"}` +
+		`]`
+	if string(data) != want {
+		t.Errorf("issues JSON\n got: %s\nwant: %s", data, want)
+	}
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+	"github.com/ProjAnvil/knot/backend/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Webhook delivery settings
+var (
+	// WebhookMaxAttempts is the number of delivery attempts before a delivery is abandoned
+	WebhookMaxAttempts = 5
+	// WebhookInitialBackoff is the wait before the first retry, doubled after every failed attempt
+	WebhookInitialBackoff = 2 * time.Second
+	// WebhookClient is the HTTP client used to deliver webhooks
+	WebhookClient = &http.Client{Timeout: 10 * time.Second}
+)
+
+// Webhook request headers
+const (
+	WebhookEventHeader     = "X-Knot-Event"
+	WebhookDeliveryHeader  = "X-Knot-Delivery"
+	WebhookSignatureHeader = "X-Knot-Signature"
+)
+
+// SignWebhookPayload returns the HMAC-SHA256 signature of a payload in the form "sha256=<hex>"
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DispatchWebhooks delivers an event to every matching webhook subscription
+func DispatchWebhooks(db *gorm.DB, event ChangeEvent) {
+	var hooks []models.Webhook
+	if err := db.Where("active = ?", true).Find(&hooks).Error; err != nil {
+		logger.Log.Error("Failed to load webhooks", zap.Error(err))
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Log.Error("Failed to encode webhook payload", zap.String("event", event.Type), zap.Error(err))
+		return
+	}
+
+	for _, hook := range hooks {
+		if hook.Matches(event.Type, event.GroupID) {
+			go DeliverWebhook(db, hook, event.Type, payload)
+		}
+	}
+}
+
+// DeliverWebhook sends a payload to a webhook, retrying with exponential backoff.
+// Every attempt is recorded as a WebhookDelivery. It returns true once an attempt succeeds.
+func DeliverWebhook(db *gorm.DB, hook models.Webhook, eventType string, payload []byte) bool {
+	deliveryID := NewWebhookDeliveryID()
+	backoff := WebhookInitialBackoff
+
+	for attempt := 1; attempt <= WebhookMaxAttempts; attempt++ {
+		delivery, retryable := SendWebhook(hook, deliveryID, eventType, payload, attempt)
+		if err := db.Create(&delivery).Error; err != nil {
+			logger.Log.Error("Failed to record webhook delivery", zap.Uint("webhookId", hook.ID), zap.Error(err))
+		}
+
+		if delivery.Success {
+			return true
+		}
+		if !retryable || attempt == WebhookMaxAttempts {
+			break
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	logger.Log.Warn("Webhook delivery failed",
+		zap.Uint("webhookId", hook.ID),
+		zap.String("deliveryId", deliveryID),
+		zap.String("event", eventType))
+	return false
+}
+
+// SendWebhook performs a single delivery attempt without recording it.
+// The second return value reports whether a failed attempt is worth retrying.
+func SendWebhook(hook models.Webhook, deliveryID, eventType string, payload []byte, attempt int) (models.WebhookDelivery, bool) {
+	delivery := models.WebhookDelivery{
+		WebhookID:  hook.ID,
+		DeliveryID: deliveryID,
+		EventType:  eventType,
+		Payload:    string(payload),
+		Attempt:    attempt,
+	}
+
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		message := fmt.Sprintf("invalid request: %v", err)
+		delivery.Error = &message
+		return delivery, false
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Knot-Webhook/1.0")
+	req.Header.Set(WebhookEventHeader, eventType)
+	req.Header.Set(WebhookDeliveryHeader, deliveryID)
+	if hook.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(hook.Secret, payload))
+	}
+
+	start := time.Now()
+	resp, err := WebhookClient.Do(req)
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		message := err.Error()
+		delivery.Error = &message
+		return delivery, true
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	delivery.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		delivery.Success = true
+		return delivery, false
+	}
+
+	message := fmt.Sprintf("unexpected status: %s", resp.Status)
+	delivery.Error = &message
+
+	// Client errors will not succeed on retry, except timeouts and rate limiting
+	retryable := resp.StatusCode >= 500 ||
+		resp.StatusCode == http.StatusRequestTimeout ||
+		resp.StatusCode == http.StatusTooManyRequests
+	return delivery, retryable
+}
+
+// NewWebhookDeliveryID generates a random identifier shared by all attempts of one delivery
+func NewWebhookDeliveryID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ProjAnvil/knot/backend/internal/models"
+)
+
+// webhookReceiver records the requests of a test webhook endpoint and answers
+// them with the given status codes in turn, then with 200
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedWebhook
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+	at     time.Time
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, receivedWebhook{header: req.Header.Clone(), body: body, at: time.Now()})
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func TestDeliverWebhook(t *testing.T) {
+	defer func(attempts int, backoff time.Duration) {
+		WebhookMaxAttempts, WebhookInitialBackoff = attempts, backoff
+	}(WebhookMaxAttempts, WebhookInitialBackoff)
+	WebhookMaxAttempts = 3
+	WebhookInitialBackoff = 20 * time.Millisecond
+
+	receiver := &webhookReceiver{statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable}}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	db := newTestDB(t)
+	hook := models.Webhook{Name: "test", URL: server.URL, Secret: "s3cret", Active: true}
+	if err := db.Create(&hook).Error; err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+
+	payload := []byte(`{"event":"api.updated","apiId":1}`)
+	if !DeliverWebhook(db, hook, EventAPIUpdated, payload) {
+		t.Fatal("DeliverWebhook = false, want true after retries")
+	}
+
+	if len(receiver.requests) != 3 {
+		t.Fatalf("receiver got %d requests, want 3", len(receiver.requests))
+	}
+	for i, req := range receiver.requests {
+		if got, want := req.header.Get(WebhookSignatureHeader), SignWebhookPayload(hook.Secret, req.body); got != want {
+			t.Errorf("attempt %d: signature = %q, want %q", i+1, got, want)
+		}
+		if got := req.header.Get(WebhookEventHeader); got != EventAPIUpdated {
+			t.Errorf("attempt %d: event header = %q, want %q", i+1, got, EventAPIUpdated)
+		}
+		if string(req.body) != string(payload) {
+			t.Errorf("attempt %d: body = %s, want %s", i+1, req.body, payload)
+		}
+	}
+	if receiver.requests[0].header.Get(WebhookDeliveryHeader) != receiver.requests[2].header.Get(WebhookDeliveryHeader) {
+		t.Error("attempts of one delivery should share the delivery ID")
+	}
+
+	// The wait doubles after every failed attempt
+	first := receiver.requests[1].at.Sub(receiver.requests[0].at)
+	second := receiver.requests[2].at.Sub(receiver.requests[1].at)
+	if first < WebhookInitialBackoff || second < 2*WebhookInitialBackoff {
+		t.Errorf("backoff = %s, %s, want at least %s, %s", first, second, WebhookInitialBackoff, 2*WebhookInitialBackoff)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := db.Where("webhook_id = ?", hook.ID).Order("attempt ASC").Find(&deliveries).Error; err != nil {
+		t.Fatalf("load deliveries: %v", err)
+	}
+	if len(deliveries) != 3 {
+		t.Fatalf("recorded %d deliveries, want 3", len(deliveries))
+	}
+	wantStatus := []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK}
+	for i, delivery := range deliveries {
+		if delivery.Attempt != i+1 || delivery.StatusCode != wantStatus[i] || delivery.Success != (i == 2) {
+			t.Errorf("delivery %d = attempt %d, status %d, success %v; want attempt %d, status %d, success %v",
+				i, delivery.Attempt, delivery.StatusCode, delivery.Success, i+1, wantStatus[i], i == 2)
+		}
+		if delivery.EventType != EventAPIUpdated || delivery.Payload != string(payload) {
+			t.Errorf("delivery %d = event %q, payload %s", i, delivery.EventType, delivery.Payload)
+		}
+	}
+}
+
+func TestDeliverWebhookStopsOnClientError(t *testing.T) {
+	defer func(backoff time.Duration) { WebhookInitialBackoff = backoff }(WebhookInitialBackoff)
+	WebhookInitialBackoff = time.Millisecond
+
+	receiver := &webhookReceiver{statuses: []int{http.StatusNotFound}}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	db := newTestDB(t)
+	hook := models.Webhook{Name: "test", URL: server.URL, Active: true}
+	if err := db.Create(&hook).Error; err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+
+	if DeliverWebhook(db, hook, EventAPIDeleted, []byte(`{}`)) {
+		t.Fatal("DeliverWebhook = true, want false")
+	}
+	if len(receiver.requests) != 1 {
+		t.Errorf("receiver got %d requests, want 1", len(receiver.requests))
+	}
+	if got := receiver.requests[0].header.Get(WebhookSignatureHeader); got != "" {
+		t.Errorf("signature = %q, want none without a secret", got)
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	// Expected value from: printf '{"a":1}' | openssl dgst -sha256 -hmac key
+	want := "sha256=88a67f24bbcdaed0e6c997404bb79a743baf44c6bab2f4c27328e3009d22e342"
+	if got := SignWebhookPayload("key", []byte(`{"a":1}`)); got != want {
+		t.Errorf("SignWebhookPayload = %q, want %q", got, want)
+	}
+}
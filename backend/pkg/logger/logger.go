@@ -10,7 +10,8 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var Log *zap.Logger
+// Log is the global logger. It is a no-op logger until InitLogger is called.
+var Log = zap.NewNop()
 
 // InitLogger initializes the logger based on configuration
 func InitLogger(cfg *config.Config) error {
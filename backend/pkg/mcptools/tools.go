@@ -0,0 +1,401 @@
+// Package mcptools defines the MCP tools of Knot once, for the MCP server built into the
+// knot CLI and the standalone knot-mcp server, and formats their results.
+package mcptools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ReadTools are the tools that query the documentation
+var ReadTools = []mcp.Tool{
+	{
+		Name:        "list_groups",
+		Description: "List all API groups in the Knot database. Returns an array of all available API groups with their IDs and names. Use this as the starting point to explore the API catalog.",
+		InputSchema: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: map[string]interface{}{},
+			Required:   []string{},
+		},
+	},
+	{
+		Name:        "get_group",
+		Description: "Get detailed information about a specific API group. Supports fuzzy matching - you can provide a partial group name (e.g., 'user' will match 'USER-SERVICE'). Returns group details including the total count of APIs in that group. Use this to verify the exact group name before listing its APIs.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"groupName": map[string]interface{}{
+					"type":        "string",
+					"description": "Full or partial name of the API group. Case-insensitive fuzzy matching is applied. Examples: 'user', 'auth', 'payment'",
+				},
+			},
+			Required: []string{"groupName"},
+		},
+	},
+	{
+		Name:        "list_apis_by_group",
+		Description: "List all APIs within a specific group. Supports fuzzy matching on group name - you can provide a partial name. Returns the group information and an array of all APIs in that group, including API ID, name, endpoint, method (GET/POST/etc), type (HTTP/RPC/EVENT) and the broker protocol of EVENT APIs. EVENT APIs describe messages on a channel: their endpoint is the channel name and their method the direction (PUBLISH or SUBSCRIBE) seen from the service. This is the primary tool to discover APIs within a known or partially-known group.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"groupName": map[string]interface{}{
+					"type":        "string",
+					"description": "Full or partial name of the API group. Examples: 'user-service', 'auth', 'payment-gateway'. Case-insensitive fuzzy matching is applied.",
+				},
+			},
+			Required: []string{"groupName"},
+		},
+	},
+	{
+		Name:        "get_api",
+		Description: "Get comprehensive details about a specific API. Returns full API documentation including: endpoint, HTTP method, type (HTTP/RPC/EVENT), group name, and hierarchical request/response parameters with types, descriptions, and required flags. For EVENT APIs, the endpoint is the channel, the method the direction (PUBLISH or SUBSCRIBE), protocol the broker protocol (e.g. kafka) and requestParameters the message payload. For GRAPHQL APIs, the endpoint is the root field, the method the operation type (QUERY, MUTATION or SUBSCRIPTION), requestParameters the arguments and signature the SDL of the field. Use this after identifying the API ID from list_apis_by_group or search_apis.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"apiId": map[string]interface{}{
+					"type":        "number",
+					"description": "The unique ID of the API (obtained from list_apis_by_group or search_apis results)",
+				},
+				"environment": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional environment name (e.g. 'dev', 'staging', 'prod'). When set, the result includes the full URL resolved from the environment's baseUrl and {{var}} templates, with secrets masked.",
+				},
+			},
+			Required: []string{"apiId"},
+		},
+	},
+	{
+		Name:        "search_apis",
+		Description: "Search for APIs across all groups by name or endpoint path. Performs fuzzy matching on both API name and endpoint URL. Returns up to 50 matching APIs with their group names. Use this when you know part of an API name or endpoint but don't know which group it belongs to.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "Search term to match against API names or endpoint paths. Examples: 'login', '/api/user', 'transaction', '流程'. Case-insensitive partial matching is applied.",
+				},
+			},
+			Required: []string{"query"},
+		},
+	},
+	{
+		Name:        "get_api_json_example",
+		Description: "Generate example JSON for a specific API's request and response payloads. Returns the API name, endpoint, HTTP method, and auto-generated example JSON structures based on the parameter definitions. Use this to understand the expected data format for API calls.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"apiId": map[string]interface{}{
+					"type":        "number",
+					"description": "The unique ID of the API (obtained from list_apis_by_group or search_apis results)",
+				},
+			},
+			Required: []string{"apiId"},
+		},
+	},
+	{
+		Name:        "get_api_comments",
+		Description: "Get the review discussion for a specific API. Returns comment threads attached to the API or to individual request/response parameters (identified by their path, e.g. 'response.items[].id'), with authors, mentions, replies and resolution status. Only open threads are returned unless includeResolved is true.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"apiId": map[string]interface{}{
+					"type":        "number",
+					"description": "The unique ID of the API (obtained from list_apis_by_group or search_apis results)",
+				},
+				"includeResolved": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Also return resolved threads. Defaults to false.",
+				},
+			},
+			Required: []string{"apiId"},
+		},
+	},
+	{
+		Name:        "get_api_types",
+		Description: "Generate the exact request and response types of a specific API from its documented parameters, instead of guessing them from examples. Nested objects become named types, optional fields and descriptions are kept. Returns source code in the requested language.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"apiId": map[string]interface{}{
+					"type":        "number",
+					"description": "The unique ID of the API (obtained from list_apis_by_group or search_apis results)",
+				},
+				"language": map[string]interface{}{
+					"type":        "string",
+					"description": "Language of the generated types. Defaults to typescript.",
+					"enum":        []string{"typescript", "go", "java", "kotlin", "python", "pydantic"},
+				},
+			},
+			Required: []string{"apiId"},
+		},
+	},
+	{
+		Name:        "get_api_snippets",
+		Description: "Generate ready-to-run calls of a specific API in curl, HTTPie, JavaScript fetch, Python requests or Go net/http. Combines the method, the endpoint with the environment's base URL, the environment's headers and the example request body. Environment secrets are masked.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"apiId": map[string]interface{}{
+					"type":        "number",
+					"description": "The unique ID of the API (obtained from list_apis_by_group or search_apis results)",
+				},
+				"language": map[string]interface{}{
+					"type":        "string",
+					"description": "Client of the snippet. Returns all clients if omitted.",
+					"enum":        []string{"curl", "httpie", "fetch", "python", "go"},
+				},
+				"environment": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional environment name (e.g. dev, staging, prod) providing the base URL, headers and {{var}} values",
+				},
+				"baseUrl": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional server address, overriding the environment's baseUrl",
+				},
+			},
+			Required: []string{"apiId"},
+		},
+	},
+	{
+		Name:        "get_api_sdl",
+		Description: "Get the GraphQL SDL signature of a GRAPHQL API: the definition of its root field with its arguments and return type, followed by the types it uses. Use this to write a GraphQL query, mutation or subscription against the operation.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"apiId": map[string]interface{}{
+					"type":        "number",
+					"description": "The unique ID of the GRAPHQL API (obtained from list_apis_by_group or search_apis results)",
+				},
+			},
+			Required: []string{"apiId"},
+		},
+	},
+}
+
+// WriteTools are the tools that create and update documentation. Their results hold the
+// diff of what changed, or the draft awaiting approval when approval is required.
+var WriteTools = []mcp.Tool{
+	{
+		Name:        "create_group",
+		Description: "Create an API group. Fails if a group with the same name already exists, and when the server requires approval. Returns the new group and the diff of what changed.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Name of the new group (e.g., 'USER-SERVICE')",
+				},
+			},
+			Required: []string{"name"},
+		},
+	},
+	{
+		Name:        "create_api",
+		Description: "Document a new API at the end of a group. The group name must match exactly; use list_groups to find it or create_group to add it. Set its parameters afterwards with set_api_parameters_from_json. Returns the new API and the diff of what changed, or the draft proposing the API when the server requires approval.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"groupName": map[string]interface{}{
+					"type":        "string",
+					"description": "Exact name of the group the API belongs to",
+				},
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "Human readable name of the API (e.g., 'Create order')",
+				},
+				"endpoint": map[string]interface{}{
+					"type":        "string",
+					"description": "Path of an HTTP API (e.g., '/api/orders/{id}'), the method of an RPC API, the channel of an EVENT API or the root field of a GRAPHQL API",
+				},
+				"method": map[string]interface{}{
+					"type":        "string",
+					"description": "HTTP method (GET, POST, ...) of an HTTP API, PUBLISH or SUBSCRIBE for an EVENT API, QUERY, MUTATION or SUBSCRIPTION for a GRAPHQL API",
+				},
+				"type": map[string]interface{}{
+					"type":        "string",
+					"description": "API type. Defaults to HTTP",
+					"enum":        []string{"HTTP", "RPC", "EVENT", "GRAPHQL"},
+				},
+				"protocol": map[string]interface{}{
+					"type":        "string",
+					"description": "Broker protocol of an EVENT API (e.g., 'kafka')",
+				},
+				"note": map[string]interface{}{
+					"type":        "string",
+					"description": "Markdown note shown with the API",
+				},
+			},
+			Required: []string{"groupName", "name", "endpoint"},
+		},
+	},
+	{
+		Name:        "update_api",
+		Description: "Update the basic info of an API. Only the given fields change. Returns the API and the diff of what changed, or the draft awaiting approval when the server requires approval.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"apiId": map[string]interface{}{
+					"type":        "number",
+					"description": "The unique ID of the API (obtained from list_apis_by_group or search_apis results)",
+				},
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "New name of the API",
+				},
+				"endpoint": map[string]interface{}{
+					"type":        "string",
+					"description": "New endpoint of the API",
+				},
+				"method": map[string]interface{}{
+					"type":        "string",
+					"description": "New method of the API",
+				},
+				"type": map[string]interface{}{
+					"type":        "string",
+					"description": "New type of the API",
+					"enum":        []string{"HTTP", "RPC", "EVENT", "GRAPHQL"},
+				},
+				"protocol": map[string]interface{}{
+					"type":        "string",
+					"description": "New broker protocol of an EVENT API",
+				},
+				"note": map[string]interface{}{
+					"type":        "string",
+					"description": "New Markdown note of the API",
+				},
+			},
+			Required: []string{"apiId"},
+		},
+	},
+	{
+		Name:        "update_api_note",
+		Description: "Replace the Markdown note of an API, e.g. with usage hints, error codes or a changelog. An empty note removes it. Returns the API and the diff of what changed, or the draft awaiting approval when the server requires approval.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"apiId": map[string]interface{}{
+					"type":        "number",
+					"description": "The unique ID of the API",
+				},
+				"note": map[string]interface{}{
+					"type":        "string",
+					"description": "The new note in Markdown",
+				},
+			},
+			Required: []string{"apiId", "note"},
+		},
+	},
+	{
+		Name:        "set_api_parameters_from_json",
+		Description: "Replace the request or response parameters of an API with the tree derived from an example JSON object. Fields keep the order of the example; required flags and descriptions of existing parameters with the same name are kept. With samples instead of json, the tree is inferred from all samples: fields missing from some samples are optional and null values make a field nullable. Returns the parameter count and the diff of what changed, or the draft awaiting approval when the server requires approval.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"apiId": map[string]interface{}{
+					"type":        "number",
+					"description": "The unique ID of the API",
+				},
+				"paramType": map[string]interface{}{
+					"type":        "string",
+					"description": "Which parameters to replace",
+					"enum":        []string{"request", "response"},
+				},
+				"json": map[string]interface{}{
+					"type":        "string",
+					"description": "Example payload as JSON text, e.g. '{\"id\": 1, \"name\": \"Alice\"}'",
+				},
+				"samples": map[string]interface{}{
+					"type":        "array",
+					"description": "Several example payloads as JSON text, used instead of json",
+					"items": map[string]interface{}{
+						"type": "string",
+					},
+				},
+			},
+			Required: []string{"apiId", "paramType"},
+		},
+	},
+}
+
+// Caller runs a tool and returns its result as JSON
+type Caller func(tool string, args map[string]interface{}) (json.RawMessage, error)
+
+// Handler answers calls of a tool through call, with the result formatted by FormatResult
+func Handler(call Caller, tool string) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, _ := request.Params.Arguments.(map[string]interface{})
+		data, err := call(tool, args)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		text, err := FormatResult(tool, data)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to format result: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	}
+}
+
+// FormatResult renders the result of a tool as text: the generated code of get_api_types,
+// get_api_snippets and get_api_sdl, and indented JSON for the other tools
+func FormatResult(tool string, data json.RawMessage) (string, error) {
+	switch tool {
+	case "get_api_types":
+		var result struct {
+			APIName  string `json:"apiName"`
+			Endpoint string `json:"endpoint"`
+			Method   string `json:"method"`
+			Language string `json:"language"`
+			Code     string `json:"code"`
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s types for %s (%s %s):\n\n%s",
+			result.Language, result.APIName, result.Method, result.Endpoint, result.Code), nil
+
+	case "get_api_snippets":
+		var result struct {
+			APIName  string `json:"apiName"`
+			Endpoint string `json:"endpoint"`
+			Method   string `json:"method"`
+			Snippets []struct {
+				Language string `json:"language"`
+				Label    string `json:"label"`
+				Code     string `json:"code"`
+			} `json:"snippets"`
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return "", err
+		}
+		text := fmt.Sprintf("Calls of %s (%s %s):", result.APIName, result.Method, result.Endpoint)
+		for _, snippet := range result.Snippets {
+			text += fmt.Sprintf("\n\n%s:\n```%s\n%s```", snippet.Label, snippet.Language, snippet.Code)
+		}
+		return text, nil
+
+	case "get_api_sdl":
+		var result struct {
+			APIName   string `json:"apiName"`
+			Operation string `json:"operation"`
+			Field     string `json:"field"`
+			Signature string `json:"signature"`
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("SDL of %s (%s %s):\n\n```graphql\n%s```",
+			result.APIName, result.Operation, result.Field, result.Signature), nil
+	}
+
+	jsonData, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(jsonData), nil
+}
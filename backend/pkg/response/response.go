@@ -9,6 +9,7 @@ type Response struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Details interface{} `json:"details,omitempty"`
 }
 
 // Success sends a successful response
@@ -32,6 +33,15 @@ func BadRequest(c *fiber.Ctx, message string) error {
 	return Error(c, fiber.StatusBadRequest, message)
 }
 
+// ValidationFailed sends a 400 Bad Request response with details about each problem
+func ValidationFailed(c *fiber.Ctx, message string, details interface{}) error {
+	return c.Status(fiber.StatusBadRequest).JSON(Response{
+		Success: false,
+		Error:   message,
+		Details: details,
+	})
+}
+
 // NotFound sends a 404 Not Found response
 func NotFound(c *fiber.Ctx, message string) error {
 	return Error(c, fiber.StatusNotFound, message)
@@ -2,7 +2,10 @@ module github.com/ProjAnvil/knot/mcp-server
 
 go 1.25.5
 
-require github.com/mark3labs/mcp-go v0.43.1
+require (
+	github.com/ProjAnvil/knot/backend v0.0.0
+	github.com/mark3labs/mcp-go v0.43.1
+)
 
 require (
 	github.com/bahlo/generic-list-go v0.2.0 // indirect
@@ -10,8 +13,11 @@ require (
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/invopop/jsonschema v0.13.0 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
-	github.com/spf13/cast v1.7.1 // indirect
+	github.com/spf13/cast v1.10.0 // indirect
 	github.com/wk8/go-ordered-map/v2 v2.1.8 // indirect
 	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+// The tool definitions are shared with the backend
+replace github.com/ProjAnvil/knot/backend => ../backend
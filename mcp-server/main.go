@@ -10,16 +10,18 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/ProjAnvil/knot/backend/pkg/mcptools"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 /**
- * Knot MCP Server - Read-only API documentation query interface
+ * Knot MCP Server - API documentation query interface
  * Provides tools to explore API groups and their endpoints
  *
  * Design Principles:
- * - Read-only operations only (no create/update/delete)
+ * - Read-only operations by default; create/update tools only with KNOT_MCP_WRITE=true
+ *   and mcpWrite enabled in the backend config (never delete)
  * - Fuzzy search support for group names
  * - Hierarchical data structure (groups → APIs → parameters)
  */
@@ -28,6 +30,7 @@ import (
 var (
 	KNOT_BASE_URL = getEnv("KNOT_BASE_URL", "http://localhost:3000")
 	API_ENDPOINT  = KNOT_BASE_URL + "/api/mcp-tools"
+	MCP_WRITE     = getEnv("KNOT_MCP_WRITE", "false") == "true"
 )
 
 // APIRequest represents the request to backend API
@@ -36,10 +39,11 @@ type APIRequest struct {
 	Args map[string]interface{} `json:"args"`
 }
 
-// APIResponse represents the response from backend API
+// APIResponse represents the response from backend API.
+// Data is kept raw so that keys stay in the order the backend wrote them.
 type APIResponse struct {
-	Data  interface{} `json:"data,omitempty"`
-	Error string      `json:"error,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"`
 }
 
 // getEnv gets environment variable with a default value
@@ -50,8 +54,13 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// toolHandler answers calls of a tool through the backend API
+func toolHandler(name string) server.ToolHandlerFunc {
+	return mcptools.Handler(callAPI, name)
+}
+
 // callAPI makes a call to the backend API
-func callAPI(tool string, args map[string]interface{}) (interface{}, error) {
+func callAPI(tool string, args map[string]interface{}) (json.RawMessage, error) {
 	reqBody := APIRequest{
 		Tool: tool,
 		Args: args,
@@ -103,174 +112,15 @@ func main() {
 		server.WithPromptCapabilities(true),
 	)
 
-	// Register list_groups tool
-	mcpServer.AddTool(mcp.Tool{
-		Name:        "list_groups",
-		Description: "List all API groups in the Knot database. Returns an array of all available API groups with their IDs and names. Use this as the starting point to explore the API catalog.",
-		InputSchema: mcp.ToolInputSchema{
-			Type:       "object",
-			Properties: map[string]interface{}{},
-			Required:   []string{},
-		},
-	}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, _ := request.Params.Arguments.(map[string]interface{})
-		data, err := callAPI("list_groups", args)
-		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
-		}
-
-		jsonData, err := json.MarshalIndent(data, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to format result: %v", err)), nil
-		}
-
-		return mcp.NewToolResultText(string(jsonData)), nil
-	})
-
-	// Register get_group tool
-	mcpServer.AddTool(mcp.Tool{
-		Name:        "get_group",
-		Description: "Get detailed information about a specific API group. Supports fuzzy matching - you can provide a partial group name (e.g., 'user' will match 'USER-SERVICE'). Returns group details including the total count of APIs in that group. Use this to verify the exact group name before listing its APIs.",
-		InputSchema: mcp.ToolInputSchema{
-			Type: "object",
-			Properties: map[string]interface{}{
-				"groupName": map[string]interface{}{
-					"type":        "string",
-					"description": "Full or partial name of the API group. Case-insensitive fuzzy matching is applied. Examples: 'user', 'auth', 'payment'",
-				},
-			},
-			Required: []string{"groupName"},
-		},
-	}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, _ := request.Params.Arguments.(map[string]interface{})
-		data, err := callAPI("get_group", args)
-		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
-		}
-
-		jsonData, err := json.MarshalIndent(data, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to format result: %v", err)), nil
-		}
-
-		return mcp.NewToolResultText(string(jsonData)), nil
-	})
-
-	// Register list_apis_by_group tool
-	mcpServer.AddTool(mcp.Tool{
-		Name:        "list_apis_by_group",
-		Description: "List all APIs within a specific group. Supports fuzzy matching on group name - you can provide a partial name. Returns the group information and an array of all APIs in that group, including API ID, name, endpoint, method (GET/POST/etc), and type (HTTP/RPC). This is the primary tool to discover APIs within a known or partially-known group.",
-		InputSchema: mcp.ToolInputSchema{
-			Type: "object",
-			Properties: map[string]interface{}{
-				"groupName": map[string]interface{}{
-					"type":        "string",
-					"description": "Full or partial name of the API group. Examples: 'user-service', 'auth', 'payment-gateway'. Case-insensitive fuzzy matching is applied.",
-				},
-			},
-			Required: []string{"groupName"},
-		},
-	}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, _ := request.Params.Arguments.(map[string]interface{})
-		data, err := callAPI("list_apis_by_group", args)
-		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
-		}
-
-		jsonData, err := json.MarshalIndent(data, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to format result: %v", err)), nil
-		}
-
-		return mcp.NewToolResultText(string(jsonData)), nil
-	})
-
-	// Register get_api tool
-	mcpServer.AddTool(mcp.Tool{
-		Name:        "get_api",
-		Description: "Get comprehensive details about a specific API. Returns full API documentation including: endpoint, HTTP method, type (HTTP/RPC), group name, and hierarchical request/response parameters with types, descriptions, and required flags. Use this after identifying the API ID from list_apis_by_group or search_apis.",
-		InputSchema: mcp.ToolInputSchema{
-			Type: "object",
-			Properties: map[string]interface{}{
-				"apiId": map[string]interface{}{
-					"type":        "number",
-					"description": "The unique ID of the API (obtained from list_apis_by_group or search_apis results)",
-				},
-			},
-			Required: []string{"apiId"},
-		},
-	}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, _ := request.Params.Arguments.(map[string]interface{})
-		data, err := callAPI("get_api", args)
-		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
-		}
-
-		jsonData, err := json.MarshalIndent(data, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to format result: %v", err)), nil
-		}
-
-		return mcp.NewToolResultText(string(jsonData)), nil
-	})
-
-	// Register search_apis tool
-	mcpServer.AddTool(mcp.Tool{
-		Name:        "search_apis",
-		Description: "Search for APIs across all groups by name or endpoint path. Performs fuzzy matching on both API name and endpoint URL. Returns up to 50 matching APIs with their group names. Use this when you know part of an API name or endpoint but don't know which group it belongs to.",
-		InputSchema: mcp.ToolInputSchema{
-			Type: "object",
-			Properties: map[string]interface{}{
-				"query": map[string]interface{}{
-					"type":        "string",
-					"description": "Search term to match against API names or endpoint paths. Examples: 'login', '/api/user', 'transaction', '流程'. Case-insensitive partial matching is applied.",
-				},
-			},
-			Required: []string{"query"},
-		},
-	}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, _ := request.Params.Arguments.(map[string]interface{})
-		data, err := callAPI("search_apis", args)
-		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
-		}
-
-		jsonData, err := json.MarshalIndent(data, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to format result: %v", err)), nil
-		}
-
-		return mcp.NewToolResultText(string(jsonData)), nil
-	})
-
-	// Register get_api_json_example tool
-	mcpServer.AddTool(mcp.Tool{
-		Name:        "get_api_json_example",
-		Description: "Generate example JSON for a specific API's request and response payloads. Returns the API name, endpoint, HTTP method, and auto-generated example JSON structures based on the parameter definitions. Use this to understand the expected data format for API calls.",
-		InputSchema: mcp.ToolInputSchema{
-			Type: "object",
-			Properties: map[string]interface{}{
-				"apiId": map[string]interface{}{
-					"type":        "number",
-					"description": "The unique ID of the API (obtained from list_apis_by_group or search_apis results)",
-				},
-			},
-			Required: []string{"apiId"},
-		},
-	}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		args, _ := request.Params.Arguments.(map[string]interface{})
-		data, err := callAPI("get_api_json_example", args)
-		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
-		}
-
-		jsonData, err := json.MarshalIndent(data, "", "  ")
-		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to format result: %v", err)), nil
+	// Register tools. The backend refuses the write tools unless mcpWrite is set in its config.
+	for _, tool := range mcptools.ReadTools {
+		mcpServer.AddTool(tool, toolHandler(tool.Name))
+	}
+	if MCP_WRITE {
+		for _, tool := range mcptools.WriteTools {
+			mcpServer.AddTool(tool, toolHandler(tool.Name))
 		}
-
-		return mcp.NewToolResultText(string(jsonData)), nil
-	})
+	}
 
 	// Register resource
 	mcpServer.AddResource(